@@ -107,10 +107,14 @@ export async function uninstallApp(
 
             childProcess.on('exit', (code, signal) => {
                 clearTimeout(timeout)
-                if (code === 0) {
+                // 退出码 6 表示卸载成功但需要重新启动
+                if (code === 0 || code === 6) {
                     resolve({
                         success: true,
-                        message: `应用 ${app.DisplayName} 已成功卸载`
+                        message:
+                            code === 6
+                                ? `应用 ${app.DisplayName} 已成功卸载，需要重新启动计算机才能完成`
+                                : `应用 ${app.DisplayName} 已成功卸载`
                     })
                 } else {
                     resolve({
@@ -0,0 +1,113 @@
+// Package applog 配置 appman 的结构化日志（log/slog）。
+//
+//	closer, err := applog.Setup(applog.Options{File: "appman.log", Level: "debug", Redact: true})
+//	defer closer.Close()
+//	slog.Debug("扫描注册表", "path", path)
+//
+// 其他包直接使用 slog 的默认 Logger。没有调用 Setup 或没有指定文件和级别时日志被丢弃，
+// 以免混入命令的标准输出和标准错误输出。
+package applog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// 日志格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// 日志文件轮转的默认值
+const (
+	DefaultMaxSize    = 5 << 20
+	DefaultMaxBackups = 3
+)
+
+// Options 是日志的配置，零值表示不记录日志
+type Options struct {
+	File   string // 日志文件，为空且指定了 Level 时写到标准错误输出
+	Level  string // debug、info、warn 或 error，默认 info
+	Format string // text 或 json，默认 text
+	Redact bool   // 把用户目录等敏感值替换为占位符
+
+	MaxSize    int64 // 单个日志文件的最大字节数，默认 DefaultMaxSize
+	MaxBackups int   // 保留的旧日志文件数，默认 DefaultMaxBackups，负数表示不保留
+}
+
+// ParseLevel 解析日志级别，不区分大小写
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("不支持的日志级别 '%s'，可选 debug、info、warn、error", s)
+}
+
+// ValidFormat 判断是否为支持的日志格式，空字符串表示默认格式
+func ValidFormat(format string) bool {
+	return format == "" || format == FormatText || format == FormatJSON
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Setup 按配置创建 Logger 并设为 slog 的默认 Logger，返回值用于关闭日志文件
+func Setup(o Options) (io.Closer, error) {
+	if o.File == "" && o.Level == "" {
+		slog.SetDefault(slog.New(slog.DiscardHandler))
+		return nopCloser{}, nil
+	}
+
+	level, err := ParseLevel(o.Level)
+	if err != nil {
+		return nil, err
+	}
+	if !ValidFormat(o.Format) {
+		return nil, fmt.Errorf("不支持的日志格式 '%s'，可选 text、json", o.Format)
+	}
+
+	var w io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if o.File != "" {
+		if o.MaxSize <= 0 {
+			o.MaxSize = DefaultMaxSize
+		}
+		if o.MaxBackups < 0 {
+			o.MaxBackups = 0
+		} else if o.MaxBackups == 0 {
+			o.MaxBackups = DefaultMaxBackups
+		}
+		f, err := OpenRotatingFile(o.File, o.MaxSize, o.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		w, closer = f, f
+	}
+
+	slog.SetDefault(slog.New(NewHandler(w, level, o.Format, o.Redact)))
+	return closer, nil
+}
+
+// NewHandler 创建指定格式的 Handler，redact 为 true 时替换日志中的用户目录
+func NewHandler(w io.Writer, level slog.Level, format string, redact bool) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if redact {
+		opts.ReplaceAttr = defaultRedactor().ReplaceAttr
+	}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
@@ -0,0 +1,132 @@
+package applog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"DEBUG": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+	for s, want := range tests {
+		if got, err := ParseLevel(s); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) should fail")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	env := map[string]string{
+		"USERPROFILE":  `C:\Users\Bob`,
+		"LOCALAPPDATA": `C:\Users\Bob\AppData\Local`,
+		"HOME":         "/",
+	}
+	r := NewRedactor(func(name string) string { return env[name] })
+
+	tests := map[string]string{
+		`c:\users\bob\AppData\Local\Temp\setup.exe`: `%LOCALAPPDATA%\Temp\setup.exe`,
+		`"C:\Users\Bob\Desktop\app.exe" /S`:         `"%USERPROFILE%\Desktop\app.exe" /S`,
+		`C:\Users\alice\AppData\Roaming\x`:          `C:\Users\<user>\AppData\Roaming\x`,
+		`C:\Program Files\7-Zip\Uninstall.exe`:      `C:\Program Files\7-Zip\Uninstall.exe`,
+	}
+	for s, want := range tests {
+		if got := r.Redact(s); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", s, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: r.ReplaceAttr}))
+	logger.Info(`打开 C:\Users\Bob\a.txt`, "args", []string{`C:\Users\Bob\b`}, "err", errors.New(`C:\Users\Bob\c`), "pid", 42)
+	if strings.Contains(strings.ToLower(buf.String()), "bob") || !strings.Contains(buf.String(), `"pid":42`) {
+		t.Errorf("log = %s", buf.String())
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appman.log")
+	f, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	want := map[string]string{
+		path:        "dddddd\n",
+		path + ".1": "cccccc\n",
+		path + ".2": "bbbbbb\n",
+	}
+	for name, content := range want {
+		if data, err := os.ReadFile(name); err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v, want %q", filepath.Base(name), data, err, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 should not exist", filepath.Base(path))
+	}
+
+	// 重新打开时接着已有的大小计算
+	f, err = OpenRotatingFile(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("eeee\n"))
+	f.Close()
+	if data, _ := os.ReadFile(path); string(data) != "eeee\n" {
+		t.Errorf("after rotation without backups = %q", data)
+	}
+}
+
+func TestRotatingFileRenameFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appman.log")
+	// path.1 是非空目录，无法删除，也无法用日志文件替换
+	if err := os.MkdirAll(filepath.Join(path+".1", "busy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal("Write after failed rotation:", err)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != "aaaaaa\nbbbbbb\n" {
+		t.Errorf("after failed rotation = %q", data)
+	}
+
+	// 障碍消除后，下次写入时完成轮转
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("cccccc\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "cccccc\n" {
+		t.Errorf("after rotation = %q", data)
+	}
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "aaaaaa\nbbbbbb\n" {
+		t.Errorf("backup = %q", data)
+	}
+}
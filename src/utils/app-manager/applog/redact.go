@@ -0,0 +1,85 @@
+package applog
+
+import (
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// 替换为占位符的用户目录，较长的路径优先，以免 %USERPROFILE% 遮盖 %LOCALAPPDATA%
+var redactedDirs = []string{"LOCALAPPDATA", "APPDATA", "TEMP", "TMP", "USERPROFILE", "HOME", "ONEDRIVE"}
+
+// 其他用户目录中的用户名，如 C:\Users\alice\...
+var usersDirPattern = regexp.MustCompile(`(?i)([\\/](?:Users|home)[\\/])[^\\/"'\s]+`)
+
+type replacement struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// Redactor 把日志中的用户目录和用户名替换为占位符，便于用户把日志发给支持人员
+type Redactor struct {
+	replacements []replacement
+}
+
+// NewRedactor 根据 getenv 返回的用户目录创建 Redactor
+func NewRedactor(getenv func(string) string) *Redactor {
+	type dir struct{ name, path string }
+	var dirs []dir
+	for _, name := range redactedDirs {
+		// 太短的值（如 "/" 或 "C:"）替换后反而难以阅读
+		if path := getenv(name); len(path) > 3 {
+			dirs = append(dirs, dir{name, path})
+		}
+	}
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return len(dirs[i].path) > len(dirs[j].path)
+	})
+
+	r := &Redactor{}
+	for _, d := range dirs {
+		r.replacements = append(r.replacements, replacement{
+			pattern:     regexp.MustCompile(`(?i)` + regexp.QuoteMeta(d.path)),
+			placeholder: "%" + d.name + "%",
+		})
+	}
+	return r
+}
+
+var defaultRedactor = sync.OnceValue(func() *Redactor {
+	return NewRedactor(os.Getenv)
+})
+
+// Redact 使用当前用户的环境变量替换 s 中的用户目录
+func Redact(s string) string {
+	return defaultRedactor().Redact(s)
+}
+
+func (r *Redactor) Redact(s string) string {
+	for _, rep := range r.replacements {
+		s = rep.pattern.ReplaceAllLiteralString(s, rep.placeholder)
+	}
+	return usersDirPattern.ReplaceAllString(s, "${1}<user>")
+}
+
+// ReplaceAttr 用于 slog.HandlerOptions，替换字符串、错误和字符串切片中的用户目录
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.Redact(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			return slog.String(a.Key, r.Redact(v.Error()))
+		case []string:
+			redacted := make([]string, len(v))
+			for i, s := range v {
+				redacted[i] = r.Redact(s)
+			}
+			return slog.Any(a.Key, redacted)
+		}
+	}
+	return a
+}
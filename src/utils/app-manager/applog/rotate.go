@@ -0,0 +1,105 @@
+package applog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile 是大小有限的日志文件。写入后超过 maxSize 时，
+// 当前文件改名为 path.1，原来的 path.1 改名为 path.2，依此类推，最多保留 maxBackups 个
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	closed bool
+}
+
+// OpenRotatingFile 以追加方式打开日志文件，文件不存在时创建
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("无法打开日志文件: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("无法打开日志文件: %w", err)
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+func (r *RotatingFile) rotate() error {
+	r.f.Close()
+	r.f = nil
+	// 改名失败时（如文件被其他程序占用）继续追加到原文件，下次写入时再尝试轮转
+	r.shift()
+	return r.open()
+}
+
+// 依次改名备份文件，没有备份时直接删除当前文件
+func (r *RotatingFile) shift() {
+	if r.maxBackups == 0 {
+		os.Remove(r.path)
+		return
+	}
+	os.Remove(backupName(r.path, r.maxBackups))
+	for n := r.maxBackups - 1; n >= 1; n-- {
+		os.Rename(backupName(r.path, n), backupName(r.path, n+1))
+	}
+	os.Rename(r.path, backupName(r.path, 1))
+}
+
+// Write 写入一条日志，超过大小上限时先轮转。一条日志不会被拆分到两个文件
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	// 上次轮转后没能重新打开文件时重试
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -2,161 +2,129 @@ package main
 
 import (
 "encoding/json"
+"flag"
 "fmt"
+"io"
+"log/slog"
 "os"
-"os/exec"
-"sort"
+"runtime"
 "strconv"
 "strings"
-"time"
 
-"github.com/shirou/gopsutil/v3/process"
 "golang.org/x/sys/windows"
-"golang.org/x/sys/windows/registry"
+
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/applog"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/catalog"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/history"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/policy"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/report"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/uninstall"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/versions"
+"github.com/jasoft/YourUninstaler/src/utils/app-manager/vulns"
 )
 
-// STILL_ACTIVE is the exit code that indicates a process is still running
-const STILL_ACTIVE uint32 = 259
-
-type App struct {
-	DisplayName     string `json:"DisplayName"`
-	DisplayVersion  string `json:"DisplayVersion"`
-	Publisher       string `json:"Publisher"`
-	InstallDate     string `json:"InstallDate"`
-	UninstallString string `json:"UninstallString"`
-	InstallLocation string `json:"InstallLocation"`
-	DisplayIcon     string `json:"DisplayIcon"`
-	RegistryKey     string `json:"RegistryKey"`
-	EstimatedSize   uint32 `json:"EstimatedSize"`
-}
+// App 是 inventory 包中的已安装应用
+type App = inventory.App
 
 type Result struct {
-	Success bool   `json:"success"`
-	Apps    []App  `json:"apps"`
-	Error   string `json:"error,omitempty"`
-}
-
-type UninstallResult struct {
-Success bool   `json:"success"`
-Message string `json:"message,omitempty"`
-Error   string `json:"error,omitempty"`
-Matches []App  `json:"matches,omitempty"` // 添加匹配的应用列表
+	Success bool           `json:"success"`
+	Apps    []App          `json:"apps"`
+	Error   string         `json:"error,omitempty"`
+	Code    errcode.Code   `json:"code,omitempty"`
+	Params  errcode.Params `json:"params,omitempty"`
 }
 
-// 查找所有名称包含指定字符串的应用
-func findMatchingApps(apps []App, name string) []App {
-    var matches []App
-    lowerName := strings.ToLower(name)
-    
-    for _, app := range apps {
-        appLowerName := strings.ToLower(app.DisplayName)
-        if strings.Contains(appLowerName, lowerName) {
-            matches = append(matches, app)
-        }
-    }
-    
-    return matches
+// 匹配到多个应用时 --json 输出的数据
+type AmbiguousResult struct {
+	Matches []App `json:"matches"`
 }
 
-// 获取注册表根键的字符串表示
-func getKeyName(key registry.Key) string {
-	switch key {
-	case registry.CLASSES_ROOT:
-		return "HKCR"
-	case registry.CURRENT_USER:
-		return "HKCU"
-	case registry.LOCAL_MACHINE:
-		return "HKLM"
-	case registry.USERS:
-		return "HKU"
-	case registry.CURRENT_CONFIG:
-		return "HKCC"
-	default:
-		return "UNKNOWN_KEY_" + strconv.Itoa(int(key))
+// uninstall、repair 和 modify 共用的命令处理：查找应用，有多个匹配时列出供选择，
+// 只有一个匹配时执行操作并输出JSON结果
+func runAppAction(out *cli, args []string, run func(*App, ...uninstall.Option) *uninstall.Result) {
+	fs := flag.NewFlagSet(out.command, flag.ContinueOnError)
+	usage := fmt.Sprintf("appman %s <name>", out.command)
+	var closeRunning *string
+	var noBackup *bool
+	if out.command == uninstall.ActionUninstall {
+		closeRunning = fs.String("close-running", "", "卸载前关闭安装目录中正在运行的进程: ask, graceful, force")
+		noBackup = fs.Bool("no-backup", false, "卸载前不备份ARP注册表项，备份失败时仍要卸载可使用")
+		usage += " [--close-running ask|graceful|force] [--no-backup]"
+	}
+	positional, err := parseFlags(fs, args)
+	if err != nil || len(positional) < 1 {
+		out.usage(usage)
 	}
-}
-
-// 解析卸载命令，将其分离为路径和参数
-func parseUninstallCommand(cmdStr string) (string, []string, error) {
-	var cmd string
-	var args []string
-
-	cmdStr = strings.TrimSpace(cmdStr)
 
-	// 处理已用引号包裹的路径
-	if strings.HasPrefix(cmdStr, "\"") {
-		endIdx := strings.Index(cmdStr[1:], "\"")
-		if endIdx == -1 {
-			return "", nil, fmt.Errorf("invalid command format: %s", cmdStr)
+	var opts []uninstall.Option
+	if closeRunning != nil {
+		if !uninstall.ValidCloseMode(*closeRunning) {
+			out.fail(errcode.Wrap(errcode.InvalidArgument, nil, fmt.Errorf("--close-running 只能是 ask、graceful 或 force")))
 		}
-		endIdx += 1 // 调整索引到原始字符串
+		opts = append(opts, uninstall.WithCloseRunning(*closeRunning), uninstall.WithAsk(askCloseRunning(out)))
+	}
+	if noBackup != nil && *noBackup {
+		opts = append(opts, uninstall.WithBackup(""))
+	}
 
-		cmd = cmdStr[1:endIdx]
-		if !isFileExists(cmd) {
-			return "", nil, fmt.Errorf("executable not found: %s", cmd)
-		}
+	appName := positional[0]
+	result, err := getAllApps()
+	if err != nil {
+		out.fail(err)
+	}
 
-		// 将剩余部分分割为参数
-		remainingStr := strings.TrimSpace(cmdStr[endIdx+1:])
-		if remainingStr != "" {
-			args = parseArgs(remainingStr)
-		}
-	} else {
-		// 没有引号的情况，寻找可能的.exe
-		parts := strings.Split(cmdStr, " ")
-		cmdPart := ""
+	// 卸载无法撤销，名称有多个匹配时总是列出供选择
+	find := inventory.Find
+	if out.command == uninstall.ActionUninstall {
+		find = inventory.FindStrict
+	}
+	matches := find(result.Apps, appName)
+	if len(matches) == 0 {
+		err := errcode.New(errcode.AppNotFound, errcode.Params{"name": appName})
+		actionResult := uninstall.Failed(err)
+		out.finish(actionResult, exitNotFound, err, writeJSON(actionResult))
+	}
 
-		// 查找包含.exe的部分
-		for i, part := range parts {
-			cmdPart += part
-			if strings.HasSuffix(strings.ToLower(cmdPart), ".exe") {
-				cmd = cmdPart
-				args = parts[i+1:]
-				break
+	if len(matches) > 1 {
+		err := errcode.New(errcode.AmbiguousMatch, errcode.Params{"name": appName, "count": strconv.Itoa(len(matches))})
+		actionResult := uninstall.Failed(err)
+		out.finish(&AmbiguousResult{Matches: matches}, exitAmbiguous, err, func(w io.Writer) error {
+			// 使用标准error输出，确保正确显示中文
+			width := consoleWidth(os.Stderr)
+			if width <= 0 || width > 80 {
+				width = 80
+			}
+			lines := make([]string, len(matches))
+			for i, app := range matches {
+				lines[i] = fmt.Sprintf("%2d) %s", i+1, app.DisplayName)
 			}
-			cmdPart += " "
-		}
-
-		if cmd == "" {
-			return "", nil, fmt.Errorf("no executable found in command: %s", cmdStr)
-		}
 
-		if !isFileExists(cmd) {
-			return "", nil, fmt.Errorf("executable not found: %s", cmd)
-		}
+			fmt.Fprintln(os.Stderr)
+			table.Box(os.Stderr, fmt.Sprintf("搜索到 %d 个匹配程序", len(matches)), lines, width)
+			fmt.Fprintf(os.Stderr, "\n提示：找到多个匹配项，请选择并复制完整名称后重新运行命令\n\n")
+			return writeJSON(actionResult)(w)
+		})
 	}
 
-	return cmd, args, nil
-}
-
-// 辅助函数：解析命令行参数
-func parseArgs(argsStr string) []string {
-	var args []string
-	var currentArg string
-	inQuotes := false
-
-	for _, char := range argsStr {
-		switch char {
-		case '"':
-			inQuotes = !inQuotes
-			currentArg += string(char)
-		case ' ':
-			if inQuotes {
-				currentArg += string(char)
-			} else if currentArg != "" {
-				args = append(args, currentArg)
-				currentArg = ""
-			}
-		default:
-			currentArg += string(char)
-		}
+	// 只有一个匹配项时执行操作
+	actionResult := recordAction(&matches[0], out.command, run, opts...)
+	var actionErr error
+	if !actionResult.Success {
+		actionErr = &errcode.Error{Code: actionResult.Code, Params: actionResult.Params}
 	}
+	out.finish(actionResult, actionExitCode(actionResult), actionErr, writeJSON(actionResult))
+}
 
-	if currentArg != "" {
-		args = append(args, currentArg)
+// 获取所有已安装的应用列表
+func getAllApps() (*Result, error) {
+	apps, err := inventory.Scan()
+	if err != nil {
+		return nil, errcode.Wrap(errcode.ScanFailed, nil, err)
 	}
-
-	return args
+	return &Result{Success: true, Apps: apps}, nil
 }
 
 // 检查文件是否存在
@@ -165,396 +133,445 @@ func isFileExists(path string) bool {
 	return err == nil
 }
 
-// 启动进程并返回其PID
-func startProcess(cmd string, args []string) (int, error) {
-	// 处理命令路径，使用单引号包裹
-	cmdPath := fmt.Sprintf("'%s'", strings.Trim(cmd, `"`))
-
-	// 构建PowerShell命令
-	var psCmd string
-	if len(args) > 0 {
-		// 如果有参数，添加-ArgumentList
-		argsStr := fmt.Sprintf("'%s'", strings.Join(args, " "))
-		psCmd = fmt.Sprintf(`Start-Process -FilePath %s -ArgumentList %s -Verb RunAs -Wait`, cmdPath, argsStr)
-	} else {
-		// 如果没有参数，不添加-ArgumentList
-		psCmd = fmt.Sprintf(`Start-Process -FilePath %s -Verb RunAs -Wait`, cmdPath)
-	}
-
-	// 执行PowerShell命令
-	command := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", psCmd)
-	command.Stdout = os.Stdout
-	command.Stderr = os.Stderr
-
-	err := command.Run()
-	if err != nil {
-		return 0, fmt.Errorf("执行卸载命令失败: %v", err)
-	}
-
-	return command.Process.Pid, nil
+// 比较两个版本号，规则见versions包
+func compareVersions(v1, v2 string) int {
+	return versions.Compare(v1, v2)
 }
 
-// 获取进程的所有子进程PID
-func getChildProcesses(pid int) ([]int, error) {
-	var childPids []int
-	processes, err := process.Processes()
-	if err != nil {
-		return nil, err
-	}
-
-	for _, proc := range processes {
-		ppid, err := proc.Ppid()
-		if err == nil && int(ppid) == pid {
-			childPids = append(childPids, int(proc.Pid))
-		}
-	}
+func init() {
+    // 切换到UTF-8之前，按控制台原来的代码页决定模糊宽度字符占几列
+    if cp, err := windows.GetConsoleOutputCP(); err == nil {
+        table.SetAmbiguousWide(isEastAsianCodePage(cp))
+    }
 
-	return childPids, nil
+    // 设置控制台输入输出编码为UTF8
+    kernel32 := windows.NewLazySystemDLL("kernel32.dll")
+    setConsoleOutputCP := kernel32.NewProc("SetConsoleOutputCP")
+    setConsoleInputCP := kernel32.NewProc("SetConsoleCP")
+    
+    // 同时设置输入和输出编码
+    setConsoleOutputCP.Call(uintptr(65001)) // UTF-8
+    setConsoleInputCP.Call(uintptr(65001))  // UTF-8
+    
+    // 强制刷新控制台设置
+    fmt.Print("")
 }
 
-// 递归获取所有后代进程
-func getAllDescendants(pid int, visited map[int]bool) ([]int, error) {
-	if visited[pid] {
-		return nil, nil
-	}
-	visited[pid] = true
-
-	var descendants []int
-	children, err := getChildProcesses(pid)
-	if err != nil {
-		return nil, err
+func main() {
+	globals, args, err := parseGlobalFlags(os.Args[1:])
+	if globals == nil {
+		globals = &globalOptions{}
 	}
-
-	for _, childPid := range children {
-		descendants = append(descendants, childPid)
-		childDescendants, err := getAllDescendants(childPid, visited)
-		if err != nil {
-			return nil, err
-		}
-		descendants = append(descendants, childDescendants...)
+	if globals.lang == "" {
+		globals.lang = errcode.SystemLanguage()
 	}
+	errcode.SetLanguage(globals.lang)
 
-	return descendants, nil
-}
-
-// 检查进程是否仍在运行
-func isProcessRunning(pid int) bool {
-	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_INFORMATION, false, uint32(pid))
-	defer windows.CloseHandle(handle)
-
-	var exitCode uint32
-	err = windows.GetExitCodeProcess(handle, &exitCode)
-	return err == nil && exitCode == STILL_ACTIVE
-}
-
-// 卸载应用并等待所有子进程结束
-func (app *App) Uninstall() *UninstallResult {
-	result := &UninstallResult{
-		Success: false,
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
 	}
-
-	// 解析卸载命令
-	cmd, args, err := parseUninstallCommand(app.UninstallString)
+	out := newCLI(command, globals.json)
 	if err != nil {
-		result.Error = err.Error()
-		return result
+		out.fail(err)
 	}
-
-	// 启动卸载进程
-	pid, err := startProcess(cmd, args)
+	logFile, err := applog.Setup(globals.log)
 	if err != nil {
-		result.Error = fmt.Sprintf("启动卸载进程失败: %s", err.Error())
-		return result
+		out.fail(errcode.Wrap(errcode.InvalidArgument, nil, err))
 	}
+	out.log = logFile
+	defer out.closeLog()
+	slog.Info("开始执行命令", "args", args, "lang", globals.lang)
+	os.Args = append(os.Args[:1], args...)
 
-	// 监控进程树
-	timeout := time.After(10 * time.Minute) // 10分钟超时
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			// 获取所有相关进程
-			visited := make(map[int]bool)
-			descendants, err := getAllDescendants(pid, visited)
-			if err != nil {
-				continue
-			}
+    if len(os.Args) < 2 {
+		if globals.json {
+			out.usage("appman <command> [arguments]")
+		}
+		fmt.Println("用法: appman <command> [arguments]")
+		fmt.Println("可用命令:")
+		fmt.Println("  list [查询参数]   - 列出已安装的应用名称")
+		fmt.Println("  export [查询参数] - 导出应用的详细信息(JSON格式)")
+		fmt.Println("  list/export --format table|csv|tsv|yaml|ndjson|html - 以表格、CSV、YAML或HTML报告等格式输出")
+		fmt.Println("  export --format cyclonedx|spdx - 导出软件物料清单(SBOM)")
+		fmt.Println("  uninstall <name> [--close-running ask|graceful|force] [--no-backup] - 卸载指定的应用，可先关闭安装目录中正在运行的进程")
+		fmt.Println("  repair <name>     - 修复指定的应用，MSI产品使用 msiexec /f")
+		fmt.Println("  modify <name>     - 打开安装程序的维护界面修改已安装的功能")
+		fmt.Println("  search <关键词> [--limit 20] [--format json|text] - 按名称或发布者搜索，支持拼音、首字母和拼写纠错")
+		fmt.Println("  usage [--top 10] [--format text|json|treemap] - 按应用、发布者、安装程序类型和磁盘汇总占用空间")
+		fmt.Println("  snapshot save <file>           - 保存当前应用列表快照")
+		fmt.Println("  diff <a> <b> [--format text|json|markdown] - 比较两个快照")
+		fmt.Println("  compare-versions <a> <b>       - 比较两个版本号，输出 -1、0 或 1")
+		fmt.Println("  outdated --catalog <dir> [--format text|json] - 根据本地软件包目录检查可更新的应用")
+		fmt.Println("  migrate export --catalog <dir> [--out <dir>] - 生成winget导入文件和重新安装脚本")
+		fmt.Println("  vulns --feed <dir> [--format text|json] - 根据本地NVD数据检查已知漏洞")
+		fmt.Println("  audit --policy <file> [--strict] [--format text|json] - 按策略文件检查应用，违反策略时返回非零退出码")
+		fmt.Println("  enforce --policy <file> [--dry-run] - 卸载策略要求移除的应用")
+		fmt.Println("  reregister <备份文件|历史记录ID> [--force] - 从卸载前的备份恢复应用的注册表项，以便重新卸载")
+		fmt.Println("  history [--since 7d] [--action uninstall] [--name <模式>] [--status failed] [--format table|json] - 查看卸载、修复和修改的历史记录")
+		fmt.Println("\n查询参数(list/export):")
+		fmt.Println("  --name <模式> --publisher <模式>   - 子串、通配符(*?)或 /正则/，不区分大小写")
+		fmt.Println("  --installed-after/--installed-before <YYYY-MM-DD>")
+		fmt.Println("  --min-size/--max-size <大小>       - 如 512K、100MB、1.5G")
+		fmt.Println("  --scope machine|user --arch x86|x64|arm64")
+		fmt.Println("  --sort name|size|date|publisher [--reverse] --limit <n>")
+		fmt.Println("  --fields DisplayName,DisplayVersion - 只输出指定字段")
+		fmt.Println("  --all                              - 同时列出更新、补丁和子组件")
+		fmt.Println("  --measure-size [--workers <n>]     - 统计安装目录的实际占用空间，结果按目录修改时间缓存")
+		fmt.Println("\n全局参数:")
+		fmt.Println("  --lang zh-CN|en-US                 - 错误消息的语言，默认使用系统界面语言")
+		fmt.Println("  --json                             - 所有命令都输出一个JSON信封 {version, command, success, exitCode, data, error}，忽略 --format")
+		fmt.Println("  --log-file <文件>                  - 写入日志，超过5MB时轮转，保留3个旧文件")
+		fmt.Println("  --log-level debug|info|warn|error  - 日志级别，默认 info；只指定级别时写到标准错误输出")
+		fmt.Println("  --log-format text|json             - 日志格式，默认 text")
+		fmt.Println("  --log-redact=false                 - 不替换日志中的用户目录和用户名")
+		fmt.Println("\n退出码:")
+		for _, e := range exitCodeHelp {
+			fmt.Printf("  %-3d %s\n", e.code, e.text)
+		}
+		out.quit(exitUsage)
+	}
 
-			// 检查主进程是否仍在运行
-			mainProcessRunning := isProcessRunning(pid)
+	switch command {
+	case "list":
+		fs := flag.NewFlagSet("list", flag.ContinueOnError)
+		format := fs.String("format", "text", "输出格式: text, json, table, csv, tsv, yaml, ndjson, html")
+		qf := addQueryFlags(fs)
+		if _, err := parseFlags(fs, os.Args[2:]); err != nil {
+			out.usage("appman list [--format text|json|table|csv|tsv|yaml|ndjson|html] [查询参数]")
+		}
+		q, fields, err := qf.build()
+		if err != nil {
+			out.fail(errcode.Wrap(errcode.InvalidArgument, nil, err))
+		}
 
-			// 检查是否还有子进程在运行
-			anyChildRunning := false
-			for _, descendantPid := range descendants {
-				if isProcessRunning(descendantPid) {
-					anyChildRunning = true
-					break
+		result, err := getAllApps()
+		if err != nil {
+			out.fail(err)
+		}
+		if *qf.all {
+			result.Apps = inventory.Flatten(result.Apps)
+		} else {
+			result.Apps = inventory.HideChildren(result.Apps)
+		}
+		if *qf.measureSize {
+			measureAppSizes(result.Apps, *qf.workers)
+		}
+		apps := queryApps(result.Apps, q)
+
+		out.output(appsJSON(apps, fields), func(w io.Writer) error {
+			// text 只输出名称，指定字段时输出表格
+			if *format == "text" {
+				if fields == nil {
+					for _, app := range apps {
+						fmt.Fprintln(w, app.DisplayName)
+					}
+					return nil
 				}
+				*format = report.FormatTable
 			}
-
-			// 如果主进程和所有子进程都已结束，退出循环
-			if !mainProcessRunning && !anyChildRunning {
-				result.Success = true
-				result.Message = fmt.Sprintf("应用 %s 已成功卸载", app.DisplayName)
-				return result
+			if fields == nil && *format != "json" {
+				fields = listFields
 			}
+			return writeApps(w, apps, fields, *format)
+		})
 
-		case <-timeout:
-			result.Error = "卸载超时，可能有进程仍在运行"
-			return result
-		}
-	}
-}
-
-// 根据应用名称查找应用
-func findAppByName(apps []App, name string) *App {
-	lowerName := strings.ToLower(name)
-	for _, app := range apps {
-		if strings.ToLower(app.DisplayName) == lowerName {
-			return &app
+	case "export":
+		fs := flag.NewFlagSet("export", flag.ContinueOnError)
+		format := fs.String("format", "json", "输出格式: json, table, csv, tsv, yaml, ndjson, html, cyclonedx, spdx")
+		qf := addQueryFlags(fs)
+		if _, err := parseFlags(fs, os.Args[2:]); err != nil {
+			out.usage("appman export [--format json|table|csv|tsv|yaml|ndjson|html|cyclonedx|spdx] [查询参数]")
 		}
-	}
-	return nil
-}
-
-// 获取所有已安装的应用列表
-func getAllApps() (*Result, error) {
-	result := &Result{
-		Success: true,
-		Apps:    []App{},
-	}
-
-	// 使用map存储临时应用列表，键为DisplayName
-	tempApps := make(map[string]App)
-
-	// 扫描注册表路径
-	paths := []struct {
-		baseKey registry.Key
-		path    string
-	}{
-		{registry.LOCAL_MACHINE, `Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall`},
-		{registry.LOCAL_MACHINE, `Software\Microsoft\Windows\CurrentVersion\Uninstall`},
-		{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Uninstall`},
-		{registry.CURRENT_USER, `Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall`},
-	}
-
-	for _, pathInfo := range paths {
-		key, err := registry.OpenKey(pathInfo.baseKey, pathInfo.path, registry.READ)
+		q, fields, err := qf.build()
 		if err != nil {
-			continue
+			out.fail(errcode.Wrap(errcode.InvalidArgument, nil, err))
+		}
+		sbomFormat := *format == "cyclonedx" || *format == "spdx"
+		if fields != nil && sbomFormat {
+			out.fail(errcode.Wrap(errcode.InvalidArgument, nil, fmt.Errorf("--fields 不能用于SBOM格式")))
 		}
 
-		subKeyNames, err := key.ReadSubKeyNames(-1)
+		result, err := getAllApps()
 		if err != nil {
-			key.Close()
-			continue
+			errorResult := Result{
+				Success: false,
+				Error:   err.Error(),
+				Code:    errcode.CodeOf(err),
+				Params:  errcode.ParamsOf(err),
+			}
+			out.finish(nil, exitCodeOf(errorResult.Code), err, func(w io.Writer) error {
+				jsonData, _ := json.Marshal(errorResult)
+				fmt.Fprintln(w, string(jsonData))
+				return nil
+			})
+		}
+		if *qf.all {
+			result.Apps = inventory.Flatten(result.Apps)
+		} else {
+			result.Apps = inventory.HideChildren(result.Apps)
 		}
+		if *qf.measureSize {
+			measureAppSizes(result.Apps, *qf.workers)
+		}
+		result.Apps = queryApps(result.Apps, q)
 
-		for _, subKeyName := range subKeyNames {
-			subKey, err := registry.OpenKey(key, subKeyName, registry.READ)
-			if err != nil {
-				continue
+		out.output(appsJSON(result.Apps, fields), func(w io.Writer) error {
+			if sbomFormat {
+				return writeSBOM(w, result.Apps, *format)
 			}
+			return writeApps(w, result.Apps, fields, *format)
+		})
 
-			// 读取DisplayName和UninstallString
-			displayName, _, _ := subKey.GetStringValue("DisplayName")
-			uninstallString, _, _ := subKey.GetStringValue("UninstallString")
-
-			// 检查SystemComponent值，如果为1则跳过
-			systemComponent, _, err := subKey.GetIntegerValue("SystemComponent")
-			if err == nil && systemComponent == 1 {
-				subKey.Close()
-				continue
-			}
+	case "uninstall":
+		runAppAction(out, os.Args[2:], uninstall.Uninstall)
+
+	case "repair":
+		runAppAction(out, os.Args[2:], uninstall.Repair)
+
+	case "modify":
+		runAppAction(out, os.Args[2:], uninstall.Modify)
+
+	case "search":
+		fs := flag.NewFlagSet("search", flag.ContinueOnError)
+		limit := fs.Int("limit", 20, "最多返回的数量，0 表示不限制")
+		minScore := fs.Float64("min-score", 0.4, "最低匹配分数(0~1)")
+		format := fs.String("format", "json", "输出格式: json, text")
+		args, err := parseFlags(fs, os.Args[2:])
+		if err != nil || len(args) == 0 {
+			out.usage("appman search <关键词> [--limit 20] [--min-score 0.4] [--format json|text]")
+		}
 
-			if displayName != "" && uninstallString != "" {
-				displayName = strings.TrimSpace(displayName)
-
-				// 读取其他信息
-				displayVersion, _, _ := subKey.GetStringValue("DisplayVersion")
-				publisher, _, _ := subKey.GetStringValue("Publisher")
-				installDate, _, _ := subKey.GetStringValue("InstallDate")
-				installLocation, _, _ := subKey.GetStringValue("InstallLocation")
-				displayIcon, _, _ := subKey.GetStringValue("DisplayIcon")
-				estimatedSize, _, _ := subKey.GetIntegerValue("EstimatedSize")
-
-				// 创建应用对象
-				app := App{
-					DisplayName:     displayName,
-					DisplayVersion:  displayVersion,
-					Publisher:       publisher,
-					InstallDate:     installDate,
-					UninstallString: uninstallString,
-					InstallLocation: installLocation,
-					DisplayIcon:     displayIcon,
-					RegistryKey:     getKeyName(pathInfo.baseKey) + `\` + pathInfo.path + `\` + subKeyName,
-					EstimatedSize:   uint32(estimatedSize),
-				}
+		result, err := getAllApps()
+		if err != nil {
+			out.fail(err)
+		}
+		searchResult := searchApps(result.Apps, strings.Join(args, " "), *minScore, *limit)
+		out.output(searchResult, func(w io.Writer) error {
+			return writeSearchResult(w, searchResult, *format)
+		})
+
+	case "usage":
+		fs := flag.NewFlagSet("usage", flag.ContinueOnError)
+		top := fs.Int("top", 10, "列出占用空间最大的应用数量，0 表示全部")
+		format := fs.String("format", "text", "输出格式: text, json, treemap")
+		measureSize := fs.Bool("measure-size", false, "统计安装目录的实际占用空间")
+		workers := fs.Int("workers", runtime.NumCPU(), "统计占用空间的并发数")
+		if _, err := parseFlags(fs, os.Args[2:]); err != nil || *top < 0 || *workers < 1 {
+			out.usage("appman usage [--top 10] [--format text|json|treemap] [--measure-size [--workers <n>]]")
+		}
 
-				// 检查是否需要更新临时列表
-				existingApp, exists := tempApps[displayName]
-				if !exists || compareVersions(displayVersion, existingApp.DisplayVersion) > 0 {
-					tempApps[displayName] = app
-				}
-			}
-			subKey.Close()
+		result, err := getAllApps()
+		if err != nil {
+			out.fail(err)
+		}
+		if *measureSize {
+			measureAppSizes(result.Apps, *workers)
+		}
+		usageResult := usageSummary(result.Apps, *top)
+		out.output(usageResult, func(w io.Writer) error {
+			return writeUsageResult(w, usageResult, *format)
+		})
+
+	case "snapshot":
+		if len(os.Args) < 4 || os.Args[2] != "save" {
+			out.usage("appman snapshot save <file>")
 		}
-		key.Close()
-	}
 
-	// 将map转换为数组
-	apps := make([]App, 0, len(tempApps))
-	for _, app := range tempApps {
-		apps = append(apps, app)
-	}
+		snapshot, err := takeSnapshot()
+		if err != nil {
+			out.fail(err)
+		}
+		if err := saveSnapshot(snapshot, os.Args[3]); err != nil {
+			out.fail(fmt.Errorf("无法保存快照: %w", err))
+		}
+		saved := &SnapshotSaved{Path: os.Args[3], Count: len(snapshot.Apps)}
+		out.output(saved, func(w io.Writer) error {
+			fmt.Fprintf(w, "已保存 %d 个应用到 %s\n", saved.Count, saved.Path)
+			return nil
+		})
+
+	case "diff":
+		fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+		format := fs.String("format", "text", "输出格式: text, json, markdown")
+		files, err := parseFlags(fs, os.Args[2:])
+		if err != nil || len(files) != 2 {
+			out.usage("appman diff <a> <b> [--format text|json|markdown]")
+		}
 
-	// 按DisplayName排序
-	sort.Slice(apps, func(i, j int) bool {
-		return apps[i].DisplayName < apps[j].DisplayName
-	})
+		from, err := loadSnapshot(files[0])
+		if err != nil {
+			out.fail(err)
+		}
+		to, err := loadSnapshot(files[1])
+		if err != nil {
+			out.fail(err)
+		}
 
-	result.Apps = apps
-	return result, nil
-}
+		diff := diffSnapshots(from, to)
+		out.output(diff, func(w io.Writer) error {
+			return writeDiff(w, diff, *format)
+		})
 
-// 简单的版本比较函数
-func compareVersions(v1, v2 string) int {
-	if v1 == v2 {
-		return 0
-	}
+	case "compare-versions":
+		if len(os.Args) != 4 {
+			out.usage("appman compare-versions <a> <b>")
+		}
+		cmp := compareVersions(os.Args[2], os.Args[3])
+		out.output(cmp, func(w io.Writer) error {
+			fmt.Fprintln(w, cmp)
+			return nil
+		})
+
+	case "outdated":
+		fs := flag.NewFlagSet("outdated", flag.ContinueOnError)
+		catalogDir := fs.String("catalog", "", "本地软件包目录")
+		format := fs.String("format", "text", "输出格式: text, json")
+		if _, err := parseFlags(fs, os.Args[2:]); err != nil || *catalogDir == "" {
+			out.usage("appman outdated --catalog <dir> [--format text|json]")
+		}
 
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
+		c, err := catalog.Load(*catalogDir)
+		if err != nil {
+			out.fail(fmt.Errorf("无法读取软件包目录: %w", err))
+		}
+		result, err := getAllApps()
+		if err != nil {
+			out.fail(err)
+		}
 
-	maxLen := len(parts1)
-	if len(parts2) > maxLen {
-		maxLen = len(parts2)
-	}
+		outdated := findOutdatedApps(result.Apps, c)
+		out.output(outdated, func(w io.Writer) error {
+			return writeOutdated(w, outdated, *format)
+		})
+
+	case "migrate":
+		fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+		catalogDir := fs.String("catalog", "", "本地winget清单目录")
+		outDir := fs.String("out", ".", "输出目录")
+		minConfidence := fs.Float64("min-confidence", 0.7, "最低匹配可信度(0~1)")
+		format := fs.String("format", "text", "输出格式: text, json")
+		args, err := parseFlags(fs, os.Args[2:])
+		if err != nil || len(args) != 1 || args[0] != "export" || *catalogDir == "" {
+			out.usage("appman migrate export --catalog <dir> [--out <dir>] [--min-confidence 0.7] [--format text|json]")
+		}
 
-	for i := 0; i < maxLen; i++ {
-		var num1, num2 int
-		if i < len(parts1) {
-			fmt.Sscanf(parts1[i], "%d", &num1)
+		c, err := catalog.Load(*catalogDir)
+		if err != nil {
+			out.fail(fmt.Errorf("无法读取软件包目录: %w", err))
 		}
-		if i < len(parts2) {
-			fmt.Sscanf(parts2[i], "%d", &num2)
+		result, err := getAllApps()
+		if err != nil {
+			out.fail(err)
 		}
 
-		if num1 < num2 {
-			return -1
-		} else if num1 > num2 {
-			return 1
+		migrateResult := matchWingetPackages(result.Apps, c, *minConfidence)
+		if err := exportMigration(migrateResult, *outDir); err != nil {
+			out.fail(fmt.Errorf("无法写入迁移文件: %w", err))
+		}
+		out.output(migrateResult, func(w io.Writer) error {
+			return writeMigrateResult(w, migrateResult, *format)
+		})
+
+	case "vulns":
+		fs := flag.NewFlagSet("vulns", flag.ContinueOnError)
+		feedDir := fs.String("feed", "", "本地NVD JSON数据目录")
+		minConfidence := fs.Float64("min-confidence", 0.5, "最低匹配可信度(0~1)")
+		format := fs.String("format", "text", "输出格式: text, json")
+		if _, err := parseFlags(fs, os.Args[2:]); err != nil || *feedDir == "" {
+			out.usage("appman vulns --feed <dir> [--min-confidence 0.5] [--format text|json]")
 		}
-	}
-
-	return 0
-}
 
-func init() {
-    // 设置控制台输入输出编码为UTF8
-    kernel32 := windows.NewLazySystemDLL("kernel32.dll")
-    setConsoleOutputCP := kernel32.NewProc("SetConsoleOutputCP")
-    setConsoleInputCP := kernel32.NewProc("SetConsoleCP")
-    
-    // 同时设置输入和输出编码
-    setConsoleOutputCP.Call(uintptr(65001)) // UTF-8
-    setConsoleInputCP.Call(uintptr(65001))  // UTF-8
-    
-    // 强制刷新控制台设置
-    fmt.Print("")
-}
+		db, err := vulns.Load(*feedDir)
+		if err != nil {
+			out.fail(fmt.Errorf("无法读取NVD数据: %w", err))
+		}
+		result, err := getAllApps()
+		if err != nil {
+			out.fail(err)
+		}
 
-func main() {
-    if len(os.Args) < 2 {
-		fmt.Println("用法: appman <command> [arguments]")
-		fmt.Println("可用命令:")
-		fmt.Println("  list              - 列出所有已安装的应用名称")
-		fmt.Println("  export            - 导出所有应用的详细信息(JSON格式)")
-		fmt.Println("  uninstall <name>  - 卸载指定的应用")
-		os.Exit(1)
-	}
+		vulnResult := findVulnerabilities(result.Apps, db, *minConfidence)
+		out.output(vulnResult, func(w io.Writer) error {
+			return writeVulnResult(w, vulnResult, *format)
+		})
+
+	case "reregister":
+		fs := flag.NewFlagSet("reregister", flag.ContinueOnError)
+		force := fs.Bool("force", false, "注册表项仍然存在时也写入备份中的值")
+		args, err := parseFlags(fs, os.Args[2:])
+		if err != nil || len(args) != 1 {
+			out.usage("appman reregister <备份文件|历史记录ID> [--force]")
+		}
 
-	command := os.Args[1]
+		result := reregister(args[0], *force)
+		var resultErr error
+		if !result.Success {
+			resultErr = &errcode.Error{Code: result.Code, Params: result.Params}
+		}
+		out.finish(result, exitCodeOf(result.Code), resultErr, writeJSON(result))
+
+	case "history":
+		fs := flag.NewFlagSet("history", flag.ContinueOnError)
+		since := fs.String("since", "", "不早于 YYYY-MM-DD，或 24h、7d、2w 等相对时间")
+		until := fs.String("until", "", "早于 YYYY-MM-DD，或相对时间")
+		action := fs.String("action", "", "操作: uninstall, repair, modify")
+		name := fs.String("name", "", "按名称或发布者过滤，支持通配符和 /正则/")
+		status := fs.String("status", "", "结果: success, failed")
+		limit := fs.Int("limit", 0, "最多输出的数量")
+		format := fs.String("format", "table", "输出格式: table, json")
+		if _, err := parseFlags(fs, os.Args[2:]); err != nil {
+			out.usage("appman history [--since 7d] [--until <date>] [--action uninstall|repair|modify] [--name <模式>] [--status success|failed] [--limit <n>] [--format table|json]")
+		}
 
-	switch command {
-	case "list":
-		result, err := getAllApps()
+		filter, err := historyFilter(*since, *until, *action, *name, *status, *limit)
 		if err != nil {
-			fmt.Printf("错误: 无法获取应用列表: %v\n", err)
-			os.Exit(1)
+			out.fail(errcode.Wrap(errcode.InvalidArgument, nil, err))
 		}
-		for _, app := range result.Apps {
-			fmt.Println(app.DisplayName)
+		entries, err := history.Read(historyPath())
+		if err != nil {
+			out.fail(err)
 		}
 
-	case "export":
+		historyResult := &HistoryResult{Success: true, Entries: filter.Apply(entries)}
+		out.output(historyResult, func(w io.Writer) error {
+			return writeHistory(w, historyResult, *format)
+		})
+
+	case "audit", "enforce":
+		fs := flag.NewFlagSet(command, flag.ContinueOnError)
+		policyFile := fs.String("policy", "", "策略规则文件(YAML或JSON)")
+		format := fs.String("format", "text", "输出格式: text, json")
+		strict := fs.Bool("strict", false, "warn 也视为违反策略")
+		dryRun := fs.Bool("dry-run", false, "只列出将要卸载的应用")
+		if _, err := parseFlags(fs, os.Args[2:]); err != nil || *policyFile == "" {
+			if command == "audit" {
+				out.usage("appman audit --policy <file> [--strict] [--format text|json]")
+			} else {
+				out.usage("appman enforce --policy <file> [--dry-run] [--format text|json]")
+			}
+		}
+
+		p, err := policy.Load(*policyFile)
+		if err != nil {
+			out.fail(fmt.Errorf("无法读取策略文件: %w", err))
+		}
 		result, err := getAllApps()
 		if err != nil {
-			errorResult := Result{
-				Success: false,
-				Error:   err.Error(),
-			}
-			jsonData, _ := json.Marshal(errorResult)
-			fmt.Println(string(jsonData))
-			os.Exit(1)
+			out.fail(err)
 		}
-		jsonData, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(jsonData))
 
-	case "uninstall":
-	    if len(os.Args) < 3 {
-	        fmt.Println("错误: 请指定要卸载的应用名称")
-	        os.Exit(1)
-	    }
-
-	    appName := os.Args[2]
-	    result, err := getAllApps()
-	    if err != nil {
-	        fmt.Printf("错误: 无法获取应用列表: %v\n", err)
-	        os.Exit(1)
-	    }
-
-	    matches := findMatchingApps(result.Apps, appName)
-	    if len(matches) == 0 {
-	        uninstallResult := UninstallResult{
-	            Success: false,
-	            Error:   fmt.Sprintf("未找到包含 '%s' 的应用", appName),
-	        }
-	        jsonData, _ := json.MarshalIndent(uninstallResult, "", "  ")
-	        fmt.Println(string(jsonData))
-	        os.Exit(1)
-	    }
-
-	    if len(matches) > 1 {
-	        // 使用标准error输出，确保正确显示中文
-	        width := 80  // 增加表格宽度
-	        fmt.Fprintf(os.Stderr, "\n")
-	        fmt.Fprintf(os.Stderr, "+--- 搜索到 %d 个匹配程序 ", len(matches))
-	        fmt.Fprintf(os.Stderr, strings.Repeat("-", width-len(fmt.Sprintf("--- 搜索到 %d 个匹配程序 ", len(matches)))-2))
-	        fmt.Fprintf(os.Stderr, "+\n")
-	        
-	        for i, app := range matches {
-	            displayName := app.DisplayName
-	            if len(displayName) > width-8 {
-	                displayName = displayName[:width-11] + "..."
-	            }
-	            fmt.Fprintf(os.Stderr, "| %2d) %-*s |\n", i+1, width-6, displayName)
-	        }
-	        
-	        fmt.Fprintf(os.Stderr, "+"+strings.Repeat("-", width-2)+"+\n")
-	        fmt.Fprintf(os.Stderr, "\n提示：找到多个匹配项，请选择并复制完整名称后重新运行命令\n\n")
-	        os.Exit(1)
-	    }
-
-	    // 只有一个匹配项时执行卸载
-	    uninstallResult := matches[0].Uninstall()
-	    jsonData, _ := json.MarshalIndent(uninstallResult, "", "  ")
-	    fmt.Println(string(jsonData))
+		auditResult := auditApps(result.Apps, p)
+		if command == "enforce" {
+			enforcePolicy(result.Apps, auditResult, *dryRun)
+		}
+
+		exitCode, err := auditExitCode(command, auditResult, *strict)
+		out.finish(auditResult, exitCode, err, func(w io.Writer) error {
+			return writeAuditResult(w, auditResult, *format)
+		})
 
 	default:
-		fmt.Printf("错误: 未知命令 '%s'\n", command)
-		os.Exit(1)
+		out.fail(errcode.Wrap(errcode.InvalidArgument, nil, fmt.Errorf("未知命令 '%s'", command)))
 	}
 }
@@ -0,0 +1,132 @@
+// Package arp 描述“程序和功能”（Add/Remove Programs）卸载注册表项中的值。
+//
+// Fields 列出 App 中有对应字段的标准值，其余值通过 Decode 保留原始类型放入 raw。
+package arp
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"unicode/utf16"
+)
+
+// 注册表值类型，与 winnt.h 中的 REG_* 常量一致
+const (
+	TypeNone     = 0
+	TypeSZ       = 1
+	TypeExpandSZ = 2
+	TypeBinary   = 3
+	TypeDWord    = 4
+	TypeMultiSZ  = 7
+	TypeQWord    = 11
+)
+
+var typeNames = map[uint32]string{
+	TypeNone:     "REG_NONE",
+	TypeSZ:       "REG_SZ",
+	TypeExpandSZ: "REG_EXPAND_SZ",
+	TypeBinary:   "REG_BINARY",
+	TypeDWord:    "REG_DWORD",
+	TypeMultiSZ:  "REG_MULTI_SZ",
+	TypeQWord:    "REG_QWORD",
+}
+
+// TypeName 返回值类型的名称，如 REG_SZ
+func TypeName(typ uint32) string {
+	if name, ok := typeNames[typ]; ok {
+		return name
+	}
+	return "REG_BINARY"
+}
+
+// Fields 是 App 中有对应字段的值名称，比较时不区分大小写
+var Fields = []string{
+	"DisplayName", "DisplayVersion", "Publisher", "InstallDate", "UninstallString",
+	"InstallLocation", "DisplayIcon", "EstimatedSize", "SystemComponent",
+	"ParentKeyName", "ParentDisplayName", "ReleaseType", "IsMinorUpgrade",
+	"QuietUninstallString", "ModifyPath", "NoModify", "NoRepair", "NoRemove",
+	"URLInfoAbout", "URLUpdateInfo", "HelpLink", "HelpTelephone", "Comments", "Contact",
+	"Readme", "Language", "VersionMajor", "VersionMinor", "ProductID",
+	"InstallSource", "WindowsInstaller",
+}
+
+var fieldSet = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, f := range Fields {
+		m[strings.ToLower(f)] = true
+	}
+	return m
+}()
+
+// IsField 判断值名称是否已有对应的 App 字段
+func IsField(name string) bool {
+	return fieldSet[strings.ToLower(name)]
+}
+
+// Value 是保留原始类型的注册表值。Data 的类型取决于 Type：
+// REG_SZ 和 REG_EXPAND_SZ 为未展开的字符串，REG_DWORD 和 REG_QWORD 为整数，
+// REG_MULTI_SZ 为字符串数组，其他类型为十六进制字符串
+type Value struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Decode 把注册表中的原始数据转换为 Value，长度不符的整数按二进制处理
+func Decode(typ uint32, data []byte) Value {
+	switch typ {
+	case TypeSZ, TypeExpandSZ:
+		return Value{Type: TypeName(typ), Data: decodeString(data)}
+	case TypeMultiSZ:
+		return Value{Type: TypeName(typ), Data: decodeMultiString(data)}
+	case TypeDWord:
+		if len(data) == 4 {
+			return Value{Type: TypeName(typ), Data: binary.LittleEndian.Uint32(data)}
+		}
+	case TypeQWord:
+		if len(data) == 8 {
+			return Value{Type: TypeName(typ), Data: binary.LittleEndian.Uint64(data)}
+		}
+	}
+	return Value{Type: TypeName(typ), Data: hex.EncodeToString(data)}
+}
+
+func decodeUTF16(data []byte) []uint16 {
+	u := make([]uint16, len(data)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return u
+}
+
+// 字符串到第一个NUL为止
+func decodeString(data []byte) string {
+	u := decodeUTF16(data)
+	for i, c := range u {
+		if c == 0 {
+			u = u[:i]
+			break
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+// 以NUL分隔、两个NUL结束的字符串列表
+func decodeMultiString(data []byte) []string {
+	result := []string{}
+	start := 0
+	u := decodeUTF16(data)
+	for i, c := range u {
+		if c != 0 {
+			continue
+		}
+		if i == start {
+			break
+		}
+		result = append(result, string(utf16.Decode(u[start:i])))
+		start = i + 1
+	}
+	if start < len(u) && u[len(u)-1] != 0 {
+		result = append(result, string(utf16.Decode(u[start:])))
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package arp
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16le(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(c), byte(c>>8))
+	}
+	return b
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		typ  uint32
+		data []byte
+		want Value
+	}{
+		{TypeSZ, utf16le("中文 App\x00"), Value{"REG_SZ", "中文 App"}},
+		{TypeSZ, utf16le("no terminator"), Value{"REG_SZ", "no terminator"}},
+		{TypeExpandSZ, utf16le(`%ProgramFiles%\App` + "\x00"), Value{"REG_EXPAND_SZ", `%ProgramFiles%\App`}},
+		{TypeMultiSZ, utf16le("a\x00bc\x00\x00"), Value{"REG_MULTI_SZ", []string{"a", "bc"}}},
+		{TypeMultiSZ, utf16le("a\x00b"), Value{"REG_MULTI_SZ", []string{"a", "b"}}},
+		{TypeMultiSZ, utf16le("\x00"), Value{"REG_MULTI_SZ", []string{}}},
+		{TypeDWord, []byte{0x01, 0x02, 0x00, 0x00}, Value{"REG_DWORD", uint32(0x201)}},
+		{TypeQWord, []byte{1, 0, 0, 0, 0, 0, 0, 0x80}, Value{"REG_QWORD", uint64(0x8000000000000001)}},
+		{TypeDWord, []byte{1, 2}, Value{"REG_DWORD", "0102"}},
+		{TypeBinary, []byte{0xde, 0xad}, Value{"REG_BINARY", "dead"}},
+		{TypeNone, nil, Value{"REG_NONE", ""}},
+	}
+	for _, tt := range tests {
+		if got := Decode(tt.typ, tt.data); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Decode(%d, %x) = %#v, want %#v", tt.typ, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestIsField(t *testing.T) {
+	for _, name := range []string{"DisplayName", "displayname", "NoRepair", "URLInfoAbout"} {
+		if !IsField(name) {
+			t.Errorf("IsField(%q) = false", name)
+		}
+	}
+	for _, name := range []string{"", "InstallerVariant", "Inno Setup: App Path"} {
+		if IsField(name) {
+			t.Errorf("IsField(%q) = true", name)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/cmdline"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/policy"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/uninstall"
+)
+
+type AuditedApp struct {
+	DisplayName    string          `json:"DisplayName"`
+	DisplayVersion string          `json:"DisplayVersion"`
+	Publisher      string          `json:"Publisher"`
+	RegistryKey    string          `json:"RegistryKey"`
+	InstallerType  string          `json:"installerType"`
+	Signature      string          `json:"signature,omitempty"`
+	Decision       policy.Decision `json:"decision"`
+	// enforce 命令的卸载结果
+	Uninstall *uninstall.Result `json:"uninstall,omitempty"`
+}
+
+type AuditResult struct {
+	Success bool         `json:"success"`
+	Apps    []AuditedApp `json:"apps"`
+	// 违反策略(deny 或 auto-uninstall)的应用数量
+	Violations int    `json:"violations"`
+	Warnings   int    `json:"warnings"`
+	Error      string `json:"error,omitempty"`
+}
+
+var innoUninstaller = regexp.MustCompile(`(?i)\\unins\d+\.exe`)
+
+// 根据卸载命令推断安装程序类型
+func detectInstallerType(app *App) string {
+	cmd := strings.ToLower(app.UninstallString)
+	switch {
+	case cmd == "":
+		return "unknown"
+	case strings.Contains(cmd, "msiexec"):
+		return "msi"
+	case innoUninstaller.MatchString(cmd):
+		return "inno"
+	case strings.Contains(cmd, `\update.exe`) && strings.Contains(cmd, "--uninstall"):
+		return "squirrel"
+	case strings.Contains(cmd, "installshield"):
+		return "installshield"
+	case strings.Contains(cmd, `\uninst.exe`) || strings.Contains(cmd, `\uninstall.exe`):
+		return "nsis"
+	default:
+		return "exe"
+	}
+}
+
+// 校验应用主程序的Authenticode签名，找不到主程序时校验卸载程序
+func signatureStatus(app *App) string {
+	path := mainExecutable(app)
+	if path == "" {
+		if cmd, _, err := cmdline.Parse(app.UninstallString); err == nil && filepath.IsAbs(cmd) && isFileExists(cmd) {
+			path = cmd
+		}
+	}
+	if path == "" {
+		return policy.SignatureUnknown
+	}
+	return verifySignature(path)
+}
+
+func verifySignature(path string) string {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return policy.SignatureUnknown
+	}
+
+	fileInfo := &windows.WinTrustFileInfo{
+		Size:     uint32(unsafe.Sizeof(windows.WinTrustFileInfo{})),
+		FilePath: pathPtr,
+	}
+	data := &windows.WinTrustData{
+		Size:                            uint32(unsafe.Sizeof(windows.WinTrustData{})),
+		UIChoice:                        windows.WTD_UI_NONE,
+		RevocationChecks:                windows.WTD_REVOKE_NONE,
+		UnionChoice:                     windows.WTD_CHOICE_FILE,
+		StateAction:                     windows.WTD_STATEACTION_VERIFY,
+		FileOrCatalogOrBlobOrSgnrOrCert: unsafe.Pointer(fileInfo),
+	}
+
+	err = windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+
+	// 释放校验过程中分配的状态
+	data.StateAction = windows.WTD_STATEACTION_CLOSE
+	windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+
+	switch err {
+	case nil:
+		return policy.SignatureSigned
+	case windows.Errno(windows.TRUST_E_NOSIGNATURE), windows.Errno(windows.TRUST_E_SUBJECT_FORM_UNKNOWN), windows.Errno(windows.TRUST_E_PROVIDER_UNKNOWN):
+		return policy.SignatureUnsigned
+	default:
+		return policy.SignatureInvalid
+	}
+}
+
+// 按策略评估所有应用，只有规则需要时才校验签名
+func auditApps(apps []App, p *policy.Policy) *AuditResult {
+	result := &AuditResult{
+		Success: true,
+		Apps:    []AuditedApp{},
+	}
+	checkSignature := p.NeedsSignature()
+
+	for i := range apps {
+		app := &apps[i]
+		audited := AuditedApp{
+			DisplayName:    app.DisplayName,
+			DisplayVersion: app.DisplayVersion,
+			Publisher:      app.Publisher,
+			RegistryKey:    app.RegistryKey,
+			InstallerType:  detectInstallerType(app),
+		}
+		if checkSignature {
+			audited.Signature = signatureStatus(app)
+		}
+
+		audited.Decision = p.Evaluate(policy.Subject{
+			DisplayName:   app.DisplayName,
+			Publisher:     app.Publisher,
+			Version:       app.DisplayVersion,
+			InstallerType: audited.InstallerType,
+			Signature:     audited.Signature,
+		})
+		if audited.Decision.Action == policy.ActionAllow {
+			continue
+		}
+
+		if audited.Decision.Violation() {
+			result.Violations++
+		} else {
+			result.Warnings++
+		}
+		result.Apps = append(result.Apps, audited)
+	}
+
+	return result
+}
+
+// 卸载策略要求移除(deny 或 auto-uninstall)的应用
+func enforcePolicy(apps []App, result *AuditResult, dryRun bool) {
+	for i := range result.Apps {
+		audited := &result.Apps[i]
+		action := audited.Decision.Action
+		if action != policy.ActionAutoUninstall && action != policy.ActionDeny {
+			continue
+		}
+
+		app := inventory.FindByKey(apps, audited.RegistryKey)
+		if app == nil {
+			continue
+		}
+		if dryRun {
+			audited.Uninstall = &uninstall.Result{Success: true, Message: fmt.Sprintf("将卸载 %s", app.DisplayName)}
+			continue
+		}
+
+		audited.Uninstall = recordAction(app, uninstall.ActionUninstall, uninstall.Uninstall)
+		if !audited.Uninstall.Success {
+			result.Success = false
+		}
+	}
+}
+
+// audit 和 enforce 的退出码。enforce 中全部卸载失败时返回 exitError，部分失败时返回 exitPartial，
+// err 为第一个失败的卸载错误；audit 存在违反策略的应用时返回 exitViolation
+func auditExitCode(command string, result *AuditResult, strict bool) (int, error) {
+	attempted, failed := 0, 0
+	var firstErr error
+	for _, app := range result.Apps {
+		if app.Uninstall == nil {
+			continue
+		}
+		attempted++
+		if !app.Uninstall.Success {
+			failed++
+			if firstErr == nil {
+				firstErr = &errcode.Error{Code: app.Uninstall.Code, Params: app.Uninstall.Params}
+			}
+		}
+	}
+	switch {
+	case failed > 0 && failed == attempted:
+		return exitError, firstErr
+	case failed > 0:
+		return exitPartial, firstErr
+	}
+
+	// 退出码 2 表示存在违反策略的应用，便于在脚本中区分运行错误
+	if command == "audit" && (result.Violations > 0 || (strict && result.Warnings > 0)) {
+		return exitViolation, nil
+	}
+	return exitOK, nil
+}
+
+func writeAuditResult(w io.Writer, result *AuditResult, format string) error {
+	switch format {
+	case "text":
+		for _, app := range result.Apps {
+			fmt.Fprintf(w, "[%s] %s %s", app.Decision.Action, app.DisplayName, app.DisplayVersion)
+			if app.Decision.Rule != "" {
+				fmt.Fprintf(w, "  规则: %s", app.Decision.Rule)
+			}
+			if app.Decision.Reason != "" {
+				fmt.Fprintf(w, "  原因: %s", app.Decision.Reason)
+			}
+			fmt.Fprintln(w)
+			if app.Uninstall != nil {
+				if app.Uninstall.Success {
+					fmt.Fprintf(w, "  %s\n", app.Uninstall.Message)
+				} else {
+					fmt.Fprintf(w, "  卸载失败: %s\n", app.Uninstall.Error)
+				}
+			}
+		}
+		fmt.Fprintf(w, "\n%d 个应用违反策略，%d 个警告\n", result.Violations, result.Warnings)
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/policy"
+)
+
+func TestDetectInstallerType(t *testing.T) {
+	tests := []struct {
+		uninstall, want string
+	}{
+		{`MsiExec.exe /X{23170F69-40C1-2702-2301-000001000000}`, "msi"},
+		{`"C:\Program Files\Git\unins000.exe"`, "inno"},
+		{`"C:\Users\me\AppData\Local\Discord\Update.exe" --uninstall`, "squirrel"},
+		{`"C:\Program Files (x86)\InstallShield Installation Information\{GUID}\setup.exe" -runfromtemp`, "installshield"},
+		{`"C:\Program Files\Notepad++\uninstall.exe"`, "nsis"},
+		{`"C:\Program Files\Contoso\remove.exe" /S`, "exe"},
+		{``, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := detectInstallerType(&App{UninstallString: tt.uninstall}); got != tt.want {
+			t.Errorf("detectInstallerType(%q) = %s, want %s", tt.uninstall, got, tt.want)
+		}
+	}
+}
+
+func TestAuditApps(t *testing.T) {
+	p, err := policy.Parse([]byte(`
+rules:
+  - name: no-remote
+    action: deny
+    match: {displayName: "AnyDesk*"}
+  - name: legacy-msi
+    action: warn
+    match: {installerType: [msi], version: "<2.0"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apps := []App{
+		{DisplayName: "AnyDesk", DisplayVersion: "8.0", RegistryKey: `HKLM\...\AnyDesk`},
+		{DisplayName: "Contoso Agent", DisplayVersion: "1.2", UninstallString: "MsiExec.exe /X{A}", RegistryKey: `HKLM\...\{A}`},
+		{DisplayName: "7-Zip", DisplayVersion: "23.01", UninstallString: `"C:\Program Files\7-Zip\Uninstall.exe"`},
+	}
+	result := auditApps(apps, p)
+	if result.Violations != 1 || result.Warnings != 1 || len(result.Apps) != 2 {
+		t.Fatalf("Unexpected audit result: %+v", result)
+	}
+	if result.Apps[0].Decision.Rule != "no-remote" || result.Apps[1].Decision.Rule != "legacy-msi" {
+		t.Errorf("Unexpected decisions: %+v", result.Apps)
+	}
+	if result.Apps[1].Signature != "" {
+		t.Error("Signature should not be checked when no rule needs it")
+	}
+
+	enforcePolicy(apps, result, true)
+	if result.Apps[0].Uninstall == nil || result.Apps[1].Uninstall != nil {
+		t.Errorf("Expected only the denied app to be scheduled: %+v", result.Apps)
+	}
+}
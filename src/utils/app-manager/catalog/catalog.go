@@ -0,0 +1,254 @@
+// Package catalog 读取本地软件包目录（winget 风格的 YAML 清单或简单的 JSON 源），
+// 并将已安装的应用与目录中的软件包对应起来。整个过程不访问网络。
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/versions"
+)
+
+// Entry 是目录中的一个软件包，Version 为目录中的最新版本
+type Entry struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Publisher string `json:"publisher,omitempty"`
+	Version   string `json:"version"`
+	// 所有版本的ProductCode（即ARP注册表子键名），旧版本安装后也能据此识别
+	ProductCodes []string `json:"productCodes,omitempty"`
+	UpgradeCodes []string `json:"upgradeCodes,omitempty"`
+	// 控制面板中显示的名称和发布者（winget清单中的AppsAndFeaturesEntries）
+	DisplayNames      []string `json:"displayNames,omitempty"`
+	DisplayPublishers []string `json:"displayPublishers,omitempty"`
+	// 匹配DisplayName的正则表达式，仅JSON源支持
+	NameRegex string `json:"nameRegex,omitempty"`
+
+	nameRegexp *regexp.Regexp
+}
+
+// Catalog 是加载到内存中的软件包目录
+type Catalog struct {
+	Entries []*Entry
+
+	byProductCode map[string]*Entry
+	byUpgradeCode map[string]*Entry
+	byDisplayName map[string][]*Entry
+	byName        map[string][]*Entry
+}
+
+// Load 递归读取目录下的所有 .yaml/.yml（winget清单）和 .json（JSON源）文件，
+// 不是软件包源的JSON文件会被跳过
+func Load(dir string) (*Catalog, error) {
+	builder := newBuilder()
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := builder.addWingetManifest(data); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		case ".json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := builder.addJSONFeed(data); err != nil {
+				// 目录中可能混有其他用途的JSON文件，跳过即可
+				if errors.Is(err, errNotFeed) {
+					slog.Warn("跳过不是软件包源的JSON文件", "path", path)
+					return nil
+				}
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.build()
+}
+
+// New 由给定的软件包列表创建目录
+func New(entries []*Entry) (*Catalog, error) {
+	builder := newBuilder()
+	for _, e := range entries {
+		builder.addEntry(e)
+	}
+	return builder.build()
+}
+
+type builder struct {
+	entries map[string]*Entry
+	winget  map[string]*wingetPackage
+}
+
+func newBuilder() *builder {
+	return &builder{
+		entries: make(map[string]*Entry),
+		winget:  make(map[string]*wingetPackage),
+	}
+}
+
+// errNotFeed 表示JSON文件格式正确，但既不是软件包数组，也不是 {"packages": [...]}
+var errNotFeed = errors.New("不是软件包源")
+
+// JSON源可以是软件包数组，也可以是 {"packages": [...]}
+func (b *builder) addJSONFeed(data []byte) error {
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var entries []*Entry
+	switch raw[0] {
+	case '[':
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return err
+		}
+	case '{':
+		var feed map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &feed); err != nil {
+			return err
+		}
+		packages, ok := feed["packages"]
+		if !ok {
+			return errNotFeed
+		}
+		if err := json.Unmarshal(packages, &entries); err != nil {
+			return err
+		}
+	default:
+		return errNotFeed
+	}
+
+	for _, e := range entries {
+		if e.ID == "" {
+			e.ID = e.Name
+		}
+		if e.ID == "" {
+			return fmt.Errorf("软件包缺少id和name")
+		}
+		b.addEntry(e)
+	}
+	return nil
+}
+
+// 同一ID出现多次时合并识别信息，并保留最高版本
+func (b *builder) addEntry(e *Entry) {
+	existing, ok := b.entries[strings.ToLower(e.ID)]
+	if !ok {
+		copied := *e
+		b.entries[strings.ToLower(e.ID)] = &copied
+		return
+	}
+
+	if versions.Compare(e.Version, existing.Version) > 0 {
+		existing.Version = e.Version
+		if e.Name != "" {
+			existing.Name = e.Name
+		}
+		if e.Publisher != "" {
+			existing.Publisher = e.Publisher
+		}
+	}
+	existing.ProductCodes = appendUnique(existing.ProductCodes, e.ProductCodes...)
+	existing.UpgradeCodes = appendUnique(existing.UpgradeCodes, e.UpgradeCodes...)
+	existing.DisplayNames = appendUnique(existing.DisplayNames, e.DisplayNames...)
+	existing.DisplayPublishers = appendUnique(existing.DisplayPublishers, e.DisplayPublishers...)
+	if existing.NameRegex == "" {
+		existing.NameRegex = e.NameRegex
+	}
+}
+
+func (b *builder) build() (*Catalog, error) {
+	for _, pkg := range b.winget {
+		b.addEntry(pkg.entry())
+	}
+
+	c := &Catalog{
+		byProductCode: make(map[string]*Entry),
+		byUpgradeCode: make(map[string]*Entry),
+		byDisplayName: make(map[string][]*Entry),
+		byName:        make(map[string][]*Entry),
+	}
+
+	for _, e := range b.entries {
+		if e.NameRegex != "" {
+			re, err := regexp.Compile("(?i)" + e.NameRegex)
+			if err != nil {
+				return nil, fmt.Errorf("软件包 %s 的nameRegex无效: %v", e.ID, err)
+			}
+			e.nameRegexp = re
+		}
+		c.Entries = append(c.Entries, e)
+	}
+
+	sort.Slice(c.Entries, func(i, j int) bool {
+		return strings.ToLower(c.Entries[i].ID) < strings.ToLower(c.Entries[j].ID)
+	})
+
+	for _, e := range c.Entries {
+		for _, code := range e.ProductCodes {
+			c.byProductCode[normalizeCode(code)] = e
+		}
+		for _, code := range e.UpgradeCodes {
+			c.byUpgradeCode[normalizeCode(code)] = e
+		}
+		for _, name := range e.DisplayNames {
+			addToIndex(c.byDisplayName, NormalizeName(name), e)
+		}
+		addToIndex(c.byName, NormalizeName(e.Name), e)
+	}
+
+	return c, nil
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, v) {
+				found = true
+				break
+			}
+		}
+		if !found && v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func addToIndex(index map[string][]*Entry, key string, e *Entry) {
+	if key == "" {
+		return
+	}
+	for _, existing := range index[key] {
+		if existing == e {
+			return
+		}
+	}
+	index[key] = append(index[key], e)
+}
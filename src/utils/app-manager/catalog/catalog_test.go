@@ -0,0 +1,163 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	c, err := Load("testdata")
+	if err != nil {
+		t.Fatal("Failed to load catalog:", err)
+	}
+	return c
+}
+
+func TestLoadMergesWingetManifests(t *testing.T) {
+	c := loadTestCatalog(t)
+
+	if len(c.Entries) != 4 {
+		t.Fatalf("Expected 4 packages, got %d", len(c.Entries))
+	}
+
+	var zip *Entry
+	for _, e := range c.Entries {
+		if e.ID == "7zip.7zip" {
+			zip = e
+		}
+	}
+	if zip == nil {
+		t.Fatal("7zip.7zip not found in catalog")
+	}
+	if zip.Version != "23.01" || zip.Name != "7-Zip" || zip.Publisher != "7-Zip" {
+		t.Errorf("Unexpected 7zip entry: %+v", zip)
+	}
+	// 旧版本的ProductCode也要保留
+	if len(zip.ProductCodes) != 3 {
+		t.Errorf("Expected 3 product codes, got %v", zip.ProductCodes)
+	}
+	if len(zip.UpgradeCodes) != 1 || len(zip.DisplayNames) != 1 {
+		t.Errorf("Expected AppsAndFeaturesEntries to be collected, got %+v", zip)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	c := loadTestCatalog(t)
+
+	tests := []struct {
+		app    Installed
+		wantID string
+		rule   string
+	}{
+		{Installed{Name: "7-Zip 22.01 (x64 edition)", ProductCode: "{23170f69-40c1-2702-2201-000001000000}"}, "7zip.7zip", RuleProductCode},
+		{Installed{Name: "7-Zip 23.01 (x64)", ProductCode: "7-Zip"}, "7zip.7zip", RuleProductCode},
+		{Installed{Name: "7-Zip Archiver", UpgradeCode: "23170F69-40C1-2702-0000-000004000000"}, "7zip.7zip", RuleUpgradeCode},
+		{Installed{Name: "7-Zip 24.07 (x64 edition)", Publisher: "Igor Pavlov", ProductCode: "{23170F69-40C1-2702-2407-000001000000}"}, "7zip.7zip", RuleAppsAndFeatures},
+		{Installed{Name: "Mozilla Firefox (x64 zh-CN)", Publisher: "Mozilla", ProductCode: "Mozilla Firefox 124.0 (x64 zh-CN)"}, "Mozilla.Firefox", RulePublisherName},
+		{Installed{Name: "Contoso Agent 4.1.3", Publisher: "Contoso, Ltd"}, "Contoso.Agent", RulePublisherName},
+		{Installed{Name: "WeChat", Publisher: "Tencent"}, "Tencent.WeChat", RuleNameRegex},
+		{Installed{Name: "微信", Publisher: "腾讯科技（深圳）有限公司"}, "Tencent.WeChat", RulePublisherName},
+		{Installed{Name: "Contoso Agent", Publisher: "Fabrikam"}, "", ""},
+		{Installed{Name: "Unknown Tool", Publisher: "Nobody"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		m, ok := c.Match(tt.app)
+		if tt.wantID == "" {
+			if ok {
+				t.Errorf("Match(%q) = %s, want no match", tt.app.Name, m.Entry.ID)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("Match(%q) found nothing, want %s", tt.app.Name, tt.wantID)
+			continue
+		}
+		if m.Entry.ID != tt.wantID || m.Rule != tt.rule {
+			t.Errorf("Match(%q) = %s via %s, want %s via %s", tt.app.Name, m.Entry.ID, m.Rule, tt.wantID, tt.rule)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	names := map[string]string{
+		"7-Zip 23.01 (x64)":           "7 zip",
+		"Mozilla Firefox (x64 en-US)": "mozilla firefox",
+		"Notepad++ 8.6.4 64-bit x64":  "notepad",
+		"Python 3.12.1":               "python",
+		"微信":                          "微信",
+	}
+	for in, want := range names {
+		if got := NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	publishers := map[string]string{
+		"Microsoft Corporation": "microsoft",
+		"Contoso, Ltd.":         "contoso",
+		"Google LLC":            "google",
+		"腾讯科技(深圳)有限公司":          "腾讯科技",
+		"Inc":                   "inc",
+	}
+	for in, want := range publishers {
+		if got := NormalizePublisher(in); got != want {
+			t.Errorf("NormalizePublisher(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMatchPrefersNameRegexOverName(t *testing.T) {
+	c, err := New([]*Entry{
+		{ID: "Contoso.Viewer", Name: "Viewer", Version: "1.0"},
+		{ID: "Fabrikam.Viewer", Name: "Fabrikam Viewer", Publisher: "Fabrikam", Version: "2.0", NameRegex: "^Viewer$"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := c.Match(Installed{Name: "Viewer"})
+	if !ok || m.Entry.ID != "Fabrikam.Viewer" || m.Rule != RuleNameRegex {
+		t.Errorf("Expected name regex match, got %+v", m)
+	}
+}
+
+func TestLoadSkipsNonFeedJSON(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"feed.json":     `[{"id": "Contoso.Agent", "version": "1.0"}]`,
+		"settings.json": `{"theme": "dark"}`,
+		"strings.json":  `"hello"`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatal("Failed to load catalog:", err)
+	}
+	if len(c.Entries) != 1 || c.Entries[0].ID != "Contoso.Agent" {
+		t.Errorf("Expected only the feed to be loaded, got %v", c.Entries)
+	}
+
+	// 内容有误的软件包源和无法解析的JSON文件仍然报错，错误中包含文件路径
+	for name, content := range map[string]string{
+		"bad.json":       `{"packages": [{"version": "1.0"}]}`,
+		"truncated.json": `{"packages": [{"id": "Contoso.Agent"`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("Expected an error mentioning %s, got %v", name, err)
+		}
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,176 @@
+package catalog
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 匹配规则，按可信度从高到低排列
+const (
+	RuleProductCode     = "ProductCode"
+	RuleUpgradeCode     = "UpgradeCode"
+	RuleAppsAndFeatures = "AppsAndFeaturesEntries"
+	RulePublisherName   = "publisher+name"
+	RuleNameRegex       = "name regex"
+	RuleName            = "name"
+)
+
+// Installed 描述一个已安装的应用，字段来自注册表的ARP信息
+type Installed struct {
+	Name      string
+	Publisher string
+	Version   string
+	// ARP注册表子键名，MSI安装的应用为 {GUID} 形式
+	ProductCode string
+	// MSI产品的升级代码，非MSI应用为空
+	UpgradeCode string
+}
+
+// Match 是一次匹配的结果，Confidence 取值范围为 0~1
+type Match struct {
+	Entry      *Entry  `json:"package"`
+	Rule       string  `json:"rule"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Match 查找与已安装应用对应的软件包
+func (c *Catalog) Match(app Installed) (*Match, bool) {
+	if app.ProductCode != "" {
+		if e, ok := c.byProductCode[normalizeCode(app.ProductCode)]; ok {
+			return &Match{Entry: e, Rule: RuleProductCode, Confidence: 1.0}, true
+		}
+	}
+
+	if app.UpgradeCode != "" {
+		if e, ok := c.byUpgradeCode[normalizeCode(app.UpgradeCode)]; ok {
+			return &Match{Entry: e, Rule: RuleUpgradeCode, Confidence: 0.95}, true
+		}
+	}
+
+	publisher := NormalizePublisher(app.Publisher)
+	// 控制面板名称相同时，发布者为空或与清单中任一发布者一致即可
+	for _, e := range c.byDisplayName[NormalizeName(app.Name)] {
+		if publisher == "" || publisherMatches(publisher, NormalizePublisher(e.Publisher)) || e.hasDisplayPublisher(publisher) {
+			return &Match{Entry: e, Rule: RuleAppsAndFeatures, Confidence: 0.9}, true
+		}
+	}
+
+	candidates := c.byName[NormalizeName(app.Name)]
+	for _, e := range candidates {
+		if publisher != "" && publisherMatches(publisher, NormalizePublisher(e.Publisher)) {
+			return &Match{Entry: e, Rule: RulePublisherName, Confidence: 0.9}, true
+		}
+	}
+
+	// 正则由目录维护者专门编写，比仅按名称匹配更可信，需要先检查
+	for _, e := range c.Entries {
+		if e.nameRegexp != nil && e.nameRegexp.MatchString(app.Name) {
+			return &Match{Entry: e, Rule: RuleNameRegex, Confidence: 0.7}, true
+		}
+	}
+
+	// 只有一个同名软件包，且缺少可用于核对的发布者信息时，仅按名称匹配
+	if len(candidates) == 1 && (publisher == "" || candidates[0].Publisher == "") {
+		return &Match{Entry: candidates[0], Rule: RuleName, Confidence: 0.6}, true
+	}
+
+	return nil, false
+}
+
+func (e *Entry) hasDisplayPublisher(normalized string) bool {
+	for _, p := range e.DisplayPublishers {
+		if publisherMatches(normalized, NormalizePublisher(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func publisherMatches(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return a == b || strings.HasPrefix(a, b+" ") || strings.HasPrefix(b, a+" ")
+}
+
+// 产品代码不区分大小写，花括号可有可无
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.Trim(strings.TrimSpace(code), "{}"))
+}
+
+var (
+	parenthesesPattern = regexp.MustCompile(`[(\[（【][^)\]）】]*[)\]）】]`)
+	versionPattern     = regexp.MustCompile(`^v?\d+(\.\d+)+\S*$`)
+)
+
+// 名称中与软件包身份无关的词
+var nameNoise = map[string]bool{
+	"x86":    true,
+	"x64":    true,
+	"amd64":  true,
+	"arm64":  true,
+	"32-bit": true,
+	"64-bit": true,
+	"32bit":  true,
+	"64bit":  true,
+}
+
+// NormalizeName 去掉名称中的版本号、架构和标点，用于不同来源之间的比较
+func NormalizeName(name string) string {
+	name = strings.ToLower(parenthesesPattern.ReplaceAllString(name, " "))
+
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if versionPattern.MatchString(word) || nameNoise[word] {
+			continue
+		}
+		words = append(words, word)
+	}
+
+	return collapsePunctuation(strings.Join(words, " "))
+}
+
+// 发布者名称中常见的公司后缀
+var publisherSuffixes = map[string]bool{
+	"inc":          true,
+	"incorporated": true,
+	"corp":         true,
+	"corporation":  true,
+	"co":           true,
+	"company":      true,
+	"ltd":          true,
+	"limited":      true,
+	"llc":          true,
+	"gmbh":         true,
+	"ag":           true,
+	"sa":           true,
+	"bv":           true,
+	"srl":          true,
+	"pty":          true,
+	"plc":          true,
+}
+
+// NormalizePublisher 去掉发布者名称中的公司后缀和标点
+func NormalizePublisher(publisher string) string {
+	publisher = strings.ToLower(parenthesesPattern.ReplaceAllString(publisher, " "))
+	publisher = strings.ReplaceAll(publisher, "有限责任公司", " ")
+	publisher = strings.ReplaceAll(publisher, "有限公司", " ")
+
+	words := strings.Fields(collapsePunctuation(publisher))
+	for len(words) > 1 && publisherSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// 将标点替换为空格并合并连续空白
+func collapsePunctuation(s string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(mapped), " ")
+}
@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/versions"
+)
+
+// winget清单中与识别相关的字段，singleton、installer、defaultLocale等清单类型共用
+type wingetManifest struct {
+	PackageIdentifier      string                 `yaml:"PackageIdentifier"`
+	PackageVersion         string                 `yaml:"PackageVersion"`
+	PackageName            string                 `yaml:"PackageName"`
+	Publisher              string                 `yaml:"Publisher"`
+	ManifestType           string                 `yaml:"ManifestType"`
+	ProductCode            string                 `yaml:"ProductCode"`
+	AppsAndFeaturesEntries []wingetARPEntry       `yaml:"AppsAndFeaturesEntries"`
+	Installers             []wingetInstallerEntry `yaml:"Installers"`
+}
+
+type wingetInstallerEntry struct {
+	ProductCode            string           `yaml:"ProductCode"`
+	AppsAndFeaturesEntries []wingetARPEntry `yaml:"AppsAndFeaturesEntries"`
+}
+
+type wingetARPEntry struct {
+	DisplayName    string `yaml:"DisplayName"`
+	Publisher      string `yaml:"Publisher"`
+	DisplayVersion string `yaml:"DisplayVersion"`
+	ProductCode    string `yaml:"ProductCode"`
+	UpgradeCode    string `yaml:"UpgradeCode"`
+}
+
+// 同一软件包所有版本的清单合并后的结果
+type wingetPackage struct {
+	id                string
+	latest            string
+	names             map[string]string
+	publishers        map[string]string
+	productCodes      []string
+	upgradeCodes      []string
+	displayNames      []string
+	displayPublishers []string
+}
+
+func (b *builder) addWingetManifest(data []byte) error {
+	var m wingetManifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	// 不是winget清单的YAML文件直接忽略
+	if m.PackageIdentifier == "" {
+		return nil
+	}
+
+	key := strings.ToLower(m.PackageIdentifier)
+	pkg, ok := b.winget[key]
+	if !ok {
+		pkg = &wingetPackage{
+			id:         m.PackageIdentifier,
+			names:      make(map[string]string),
+			publishers: make(map[string]string),
+		}
+		b.winget[key] = pkg
+	}
+
+	if pkg.latest == "" || versions.Compare(m.PackageVersion, pkg.latest) > 0 {
+		pkg.latest = m.PackageVersion
+	}
+
+	// locale清单可能是其他语言，只采用默认语言的名称
+	if m.ManifestType != "locale" {
+		if m.PackageName != "" {
+			pkg.names[m.PackageVersion] = m.PackageName
+		}
+		if m.Publisher != "" {
+			pkg.publishers[m.PackageVersion] = m.Publisher
+		}
+	}
+
+	pkg.addARP(m.ProductCode, m.AppsAndFeaturesEntries)
+	for _, installer := range m.Installers {
+		pkg.addARP(installer.ProductCode, installer.AppsAndFeaturesEntries)
+	}
+	return nil
+}
+
+func (p *wingetPackage) addARP(productCode string, entries []wingetARPEntry) {
+	p.productCodes = appendUnique(p.productCodes, productCode)
+	for _, e := range entries {
+		p.productCodes = appendUnique(p.productCodes, e.ProductCode)
+		p.upgradeCodes = appendUnique(p.upgradeCodes, e.UpgradeCode)
+		p.displayNames = appendUnique(p.displayNames, e.DisplayName)
+		p.displayPublishers = appendUnique(p.displayPublishers, e.Publisher)
+	}
+}
+
+// 返回版本最高的非空值，最新版本只有installer清单时也能得到名称
+func latestValue(values map[string]string) string {
+	var best, value string
+	for version, v := range values {
+		if value == "" || versions.Compare(version, best) > 0 {
+			best, value = version, v
+		}
+	}
+	return value
+}
+
+func (p *wingetPackage) entry() *Entry {
+	name := latestValue(p.names)
+	publisher := latestValue(p.publishers)
+	if publisher == "" && len(p.displayPublishers) > 0 {
+		publisher = p.displayPublishers[0]
+	}
+
+	return &Entry{
+		ID:                p.id,
+		Name:              name,
+		Publisher:         publisher,
+		Version:           p.latest,
+		ProductCodes:      p.productCodes,
+		UpgradeCodes:      p.upgradeCodes,
+		DisplayNames:      p.displayNames,
+		DisplayPublishers: p.displayPublishers,
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/applog"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+)
+
+// 解析子命令参数，允许标志和位置参数混排（例如 diff a.json b.json --format json）
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return positional, nil
+}
+
+// 所有命令共用的参数，可以出现在命令前后
+type globalOptions struct {
+	lang string         // 错误消息的语言，为空时使用系统语言
+	json bool           // 以JSON信封输出结果，见 envelope.go
+	log  applog.Options // --log-file、--log-level、--log-format 和 --log-redact
+}
+
+// 需要值的全局参数，其余为布尔参数
+var globalValueFlags = map[string]bool{
+	"lang":       true,
+	"log-file":   true,
+	"log-level":  true,
+	"log-format": true,
+	"json":       false,
+	"log-redact": false,
+}
+
+// 取出全局参数，返回其余参数。出错时仍返回已识别的参数，以便按 --json 输出错误
+func parseGlobalFlags(args []string) (*globalOptions, []string, error) {
+	opts := &globalOptions{log: applog.Options{Redact: true}}
+	var rest []string
+	var firstErr error
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		needsValue, ok := globalValueFlags[name]
+		if !strings.HasPrefix(args[i], "-") || !ok {
+			rest = append(rest, args[i])
+			continue
+		}
+
+		var err error
+		if needsValue && !hasValue {
+			if i+1 >= len(args) {
+				err = fmt.Errorf("--%s 需要一个值", name)
+				name = ""
+			} else {
+				i++
+				value = args[i]
+			}
+		}
+
+		switch name {
+		case "json":
+			opts.json, err = parseBoolFlag(value, hasValue)
+		case "log-redact":
+			opts.log.Redact, err = parseBoolFlag(value, hasValue)
+		case "lang":
+			if opts.lang = errcode.Match(value); opts.lang == "" {
+				err = fmt.Errorf("不支持的语言 '%s'，可选 %s", value, strings.Join(errcode.Languages(), "、"))
+			}
+		case "log-file":
+			opts.log.File = value
+		case "log-level":
+			if _, err = applog.ParseLevel(value); err == nil {
+				opts.log.Level = value
+			}
+		case "log-format":
+			if !applog.ValidFormat(value) {
+				err = fmt.Errorf("不支持的日志格式 '%s'，可选 text、json", value)
+				break
+			}
+			opts.log.Format = value
+		}
+		if err != nil && firstErr == nil {
+			firstErr = errcode.Wrap(errcode.InvalidArgument, nil, err)
+		}
+	}
+	return opts, rest, firstErr
+}
+
+// 布尔参数可以写作 --name 或 --name=true|false
+func parseBoolFlag(value string, hasValue bool) (bool, error) {
+	if !hasValue {
+		return true, nil
+	}
+	return strconv.ParseBool(value)
+}
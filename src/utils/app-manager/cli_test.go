@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/applog"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	opts, rest, err := parseGlobalFlags([]string{"--lang", "en", "uninstall", "7-Zip", "--lang=zh-Hans", "--json"})
+	if err != nil || opts.lang != errcode.ZhCN || !opts.json || !reflect.DeepEqual(rest, []string{"uninstall", "7-Zip"}) {
+		t.Errorf("parseGlobalFlags = %+v, %q, %v", opts, rest, err)
+	}
+
+	for _, args := range [][]string{{"list", "--lang"}, {"list", "--lang", "fr", "--json"}, {"list", "--json=maybe"}} {
+		if _, _, err := parseGlobalFlags(args); errcode.CodeOf(err) != errcode.InvalidArgument {
+			t.Errorf("parseGlobalFlags(%q) error = %v, want INVALID_ARGUMENT", args, err)
+		}
+	}
+	if opts, _, _ := parseGlobalFlags([]string{"--lang", "fr", "--json"}); !opts.json {
+		t.Error("--json should be recognized after an invalid --lang")
+	}
+}
+
+func TestParseGlobalLogFlags(t *testing.T) {
+	opts, rest, err := parseGlobalFlags([]string{"list", "--log-file", "appman.log", "--log-level=DEBUG", "--log-format", "json", "--log-redact=false"})
+	want := applog.Options{File: "appman.log", Level: "DEBUG", Format: "json", Redact: false}
+	if err != nil || opts.log != want || !reflect.DeepEqual(rest, []string{"list"}) {
+		t.Errorf("parseGlobalFlags = %+v, %q, %v", opts.log, rest, err)
+	}
+	if opts, _, _ := parseGlobalFlags([]string{"list"}); !opts.log.Redact {
+		t.Error("log redaction should be enabled by default")
+	}
+
+	for _, args := range [][]string{{"--log-level", "verbose"}, {"--log-format=xml"}, {"list", "--log-file"}} {
+		if _, _, err := parseGlobalFlags(args); errcode.CodeOf(err) != errcode.InvalidArgument {
+			t.Errorf("parseGlobalFlags(%q) error = %v, want INVALID_ARGUMENT", args, err)
+		}
+	}
+}
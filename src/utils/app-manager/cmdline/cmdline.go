@@ -0,0 +1,164 @@
+// Package cmdline 把注册表中保存的卸载、修改等命令行分离为可执行文件路径和参数。
+//
+//	path, args, err := cmdline.Parse(`"C:\Program Files\App\uninstall.exe" /S`)
+//
+// 不带路径的程序（如 MsiExec.exe）默认视为不存在，可以用 WithSearchDirs 指定查找目录。
+package cmdline
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+)
+
+type options struct {
+	exists     func(path string) bool
+	searchDirs []string
+}
+
+// Option 是 Parse 的选项
+type Option func(*options)
+
+// WithFileCheck 替换判断文件是否存在的函数，默认使用 os.Stat
+func WithFileCheck(exists func(path string) bool) Option {
+	return func(o *options) {
+		o.exists = exists
+	}
+}
+
+// WithSearchDirs 指定不带路径的程序的查找目录，通常为系统目录。
+// 此时程序名也可以省略 .exe，如 "msiexec /i {GUID}"
+func WithSearchDirs(dirs ...string) Option {
+	return func(o *options) {
+		o.searchDirs = append(o.searchDirs, dirs...)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// 在查找目录中找到不带路径的程序时返回完整路径
+func (o *options) resolve(cmd string) (string, bool) {
+	if o.exists(cmd) {
+		return cmd, true
+	}
+	if strings.ContainsAny(cmd, `\/:`) {
+		return "", false
+	}
+	for _, dir := range o.searchDirs {
+		if p := filepath.Join(dir, cmd); o.exists(p) {
+			slog.Debug("在查找目录中找到程序", "program", cmd, "path", p)
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Parse 解析命令行，将其分离为可执行文件路径和参数，可执行文件不存在时返回错误。
+// 错误为 *errcode.Error，代码为 InvalidCommand、NoExecutable 或 ExecutableNotFound。
+// 路径可以用引号包裹，没有引号时路径到第一个以 .exe 结尾的部分为止
+func Parse(cmdStr string, opts ...Option) (string, []string, error) {
+	o := &options{exists: fileExists}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var cmd string
+	var args []string
+
+	cmdStr = strings.TrimSpace(cmdStr)
+
+	// 处理已用引号包裹的路径
+	if strings.HasPrefix(cmdStr, "\"") {
+		endIdx := strings.Index(cmdStr[1:], "\"")
+		if endIdx == -1 {
+			return "", nil, errcode.New(errcode.InvalidCommand, errcode.Params{"command": cmdStr})
+		}
+		endIdx += 1 // 调整索引到原始字符串
+
+		cmd = cmdStr[1:endIdx]
+		resolved, ok := o.resolve(cmd)
+		if !ok {
+			return "", nil, errcode.New(errcode.ExecutableNotFound, errcode.Params{"path": cmd})
+		}
+		cmd = resolved
+
+		// 将剩余部分分割为参数
+		remainingStr := strings.TrimSpace(cmdStr[endIdx+1:])
+		if remainingStr != "" {
+			args = SplitArgs(remainingStr)
+		}
+		slog.Debug("解析命令行", "command", cmdStr, "path", cmd, "args", args, "quoted", true)
+		return cmd, args, nil
+	}
+
+	// 没有引号的情况，寻找可能的.exe
+	parts := strings.Split(cmdStr, " ")
+	cmdPart := ""
+
+	// 查找包含.exe的部分
+	for i, part := range parts {
+		cmdPart += part
+		if strings.HasSuffix(strings.ToLower(cmdPart), ".exe") {
+			cmd = cmdPart
+			args = parts[i+1:]
+			break
+		}
+		cmdPart += " "
+	}
+
+	// 省略 .exe 的系统程序
+	if cmd == "" && len(o.searchDirs) > 0 && parts[0] != "" {
+		if resolved, ok := o.resolve(parts[0] + ".exe"); ok {
+			args = SplitArgs(strings.Join(parts[1:], " "))
+			slog.Debug("解析命令行", "command", cmdStr, "path", resolved, "args", args, "omittedExe", true)
+			return resolved, args, nil
+		}
+	}
+
+	if cmd == "" {
+		return "", nil, errcode.New(errcode.NoExecutable, errcode.Params{"command": cmdStr})
+	}
+
+	resolved, ok := o.resolve(cmd)
+	if !ok {
+		return "", nil, errcode.New(errcode.ExecutableNotFound, errcode.Params{"path": cmd})
+	}
+	slog.Debug("解析命令行", "command", cmdStr, "path", resolved, "args", args)
+	return resolved, args, nil
+}
+
+// SplitArgs 按空格分割参数，引号内的空格不分割，引号保留在参数中
+func SplitArgs(argsStr string) []string {
+	var args []string
+	var currentArg string
+	inQuotes := false
+
+	for _, char := range argsStr {
+		switch char {
+		case '"':
+			inQuotes = !inQuotes
+			currentArg += string(char)
+		case ' ':
+			if inQuotes {
+				currentArg += string(char)
+			} else if currentArg != "" {
+				args = append(args, currentArg)
+				currentArg = ""
+			}
+		default:
+			currentArg += string(char)
+		}
+	}
+
+	if currentArg != "" {
+		args = append(args, currentArg)
+	}
+
+	return args
+}
@@ -0,0 +1,101 @@
+package cmdline
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+)
+
+func existing(paths ...string) Option {
+	return WithFileCheck(func(path string) bool {
+		for _, p := range paths {
+			if strings.EqualFold(p, path) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func TestParse(t *testing.T) {
+	files := existing(
+		`C:\Program Files\App\uninstall.exe`,
+		`C:\Apps & Games\Test (Beta)\remove!.exe`,
+		`C:\Apps\uninstall.exe`,
+		`C:/Apps/uninstall.exe`,
+		`C:\Program Files\My App\unins000.exe`,
+	)
+
+	tests := []struct {
+		cmd  string
+		path string
+		args []string
+	}{
+		{`"C:\Program Files\App\uninstall.exe" /S`, `C:\Program Files\App\uninstall.exe`, []string{"/S"}},
+		{`"C:\Apps & Games\Test (Beta)\remove!.exe" /force`, `C:\Apps & Games\Test (Beta)\remove!.exe`, []string{"/force"}},
+		{`C:\Apps\uninstall.exe /S /quiet`, `C:\Apps\uninstall.exe`, []string{"/S", "/quiet"}},
+		{`C:/Apps/uninstall.exe`, `C:/Apps/uninstall.exe`, []string{}},
+		{`C:\Program Files\My App\unins000.exe /SILENT`, `C:\Program Files\My App\unins000.exe`, []string{"/SILENT"}},
+		{`  "C:\Program Files\App\uninstall.exe"  --log "C:\a b\log.txt" `, `C:\Program Files\App\uninstall.exe`, []string{"--log", `"C:\a b\log.txt"`}},
+	}
+	for _, tt := range tests {
+		path, args, err := Parse(tt.cmd, files)
+		if err != nil || path != tt.path || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("Parse(%q) = %q, %q, %v, want %q, %q", tt.cmd, path, args, err, tt.path, tt.args)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	files := existing(`C:\Apps\uninstall.exe`)
+	tests := map[string]errcode.Code{
+		`"C:\Apps\uninstall.exe /S`:          errcode.InvalidCommand,
+		`"C:\Missing\uninstall.exe" /S`:      errcode.ExecutableNotFound,
+		`C:\Missing\uninstall.exe /S`:        errcode.ExecutableNotFound,
+		`rundll32 setupapi.dll,InstallHinf`:  errcode.NoExecutable,
+		`MsiExec.exe /X{23170F69-40C1-2702}`: errcode.ExecutableNotFound,
+		``:                                   errcode.NoExecutable,
+	}
+	for cmd, want := range tests {
+		if _, _, err := Parse(cmd, files); errcode.CodeOf(err) != want {
+			t.Errorf("Parse(%q) error = %v, want %s", cmd, err, want)
+		}
+	}
+}
+
+func TestParseSearchDirs(t *testing.T) {
+	sysDir := `C:\Windows\System32`
+	msiexec := filepath.Join(sysDir, "msiexec.exe")
+	opts := []Option{existing(msiexec), WithSearchDirs(sysDir)}
+
+	tests := []struct {
+		cmd  string
+		args []string
+	}{
+		{`MsiExec.exe /X{23170F69-40C1-2702-2301-000001000000}`, []string{"/X{23170F69-40C1-2702-2301-000001000000}"}},
+		{`msiexec /i {23170F69-40C1-2702-2301-000001000000} /qb`, []string{"/i", "{23170F69-40C1-2702-2301-000001000000}", "/qb"}},
+		{`"msiexec.exe" /f {A}`, []string{"/f", "{A}"}},
+	}
+	for _, tt := range tests {
+		path, args, err := Parse(tt.cmd, opts...)
+		if err != nil || !strings.EqualFold(path, msiexec) || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("Parse(%q) = %q, %q, %v", tt.cmd, path, args, err)
+		}
+	}
+
+	// 带路径的程序不在查找目录中查找
+	if _, _, err := Parse(`D:\msiexec.exe /x`, opts...); err == nil {
+		t.Error("Expected error for missing program with explicit path")
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	got := SplitArgs(`/S  --dir "C:\Program Files\App" -x`)
+	want := []string{"/S", "--dir", `"C:\Program Files\App"`, "-x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitArgs = %q, want %q", got, want)
+	}
+}
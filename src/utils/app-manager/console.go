@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/windows"
+)
+
+// 返回控制台窗口的宽度（列数），输出被重定向时返回0，表示不限制宽度
+func consoleWidth(f *os.File) int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(f.Fd()), &info); err == nil {
+		if width := int(info.Window.Right-info.Window.Left) + 1; width > 0 {
+			return width
+		}
+	}
+
+	// 在MSYS等终端中运行时不是Windows控制台，尝试使用COLUMNS环境变量
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// 判断文件是否为Windows控制台，用于决定能否交互式询问用户
+func isConsole(f *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
+
+// 判断代码页是否为中日韩的传统代码页，这些控制台字体中模糊宽度的字符显示为两列
+func isEastAsianCodePage(cp uint32) bool {
+	switch cp {
+	case 932, 936, 949, 950: // 日文、简体中文、韩文、繁体中文
+		return true
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+// Package cpe 根据注册表中的发布者、名称和版本生成 CPE 2.3 标识。
+package cpe
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/catalog"
+)
+
+// WFN 是解析后的CPE名称，各字段均为去掉转义后的值，"*" 表示任意值，"-" 表示不适用
+type WFN struct {
+	Part    string
+	Vendor  string
+	Product string
+	Version string
+	Update  string
+}
+
+// Candidate 是由应用信息推导出的一个可能的 vendor:product 组合
+type Candidate struct {
+	Vendor     string
+	Product    string
+	Confidence float64
+}
+
+// Format 生成 cpe:2.3:a:vendor:product:version:*:*:*:*:*:*:* 形式的标识
+func Format(vendor, product, version string) string {
+	return strings.Join([]string{
+		"cpe", "2.3", "a",
+		escape(vendor), escape(product), escape(version),
+		"*", "*", "*", "*", "*", "*", "*",
+	}, ":")
+}
+
+// Derive 由发布者、名称和版本推导CPE，信息不足或包含非ASCII字符时返回空字符串
+func Derive(publisher, name, version string) string {
+	vendor := Vendor(publisher)
+	product := Product(name, vendor)
+	version = strings.TrimSpace(version)
+
+	if vendor == "" || product == "" || version == "" || !isASCII(vendor+product+version) {
+		return ""
+	}
+	return Format(vendor, product, version)
+}
+
+// Vendor 将发布者名称转换为CPE中的vendor，例如 "Mozilla Corporation" -> "mozilla"
+func Vendor(publisher string) string {
+	return strings.ReplaceAll(catalog.NormalizePublisher(publisher), " ", "_")
+}
+
+// Product 将显示名称转换为CPE中的product，去掉版本号和与vendor重复的前缀，
+// 例如 "Mozilla Firefox 125.0 (x64 en-US)" -> "firefox"
+func Product(name, vendor string) string {
+	product := strings.ReplaceAll(catalog.NormalizeName(name), " ", "_")
+	if vendor != "" && strings.HasPrefix(product, vendor+"_") {
+		product = strings.TrimPrefix(product, vendor+"_")
+	}
+	return product
+}
+
+// Parse 解析 cpe:2.3: 开头的格式化字符串
+func Parse(s string) (WFN, error) {
+	fields := splitUnescaped(s)
+	if len(fields) < 7 || fields[0] != "cpe" || fields[1] != "2.3" {
+		return WFN{}, fmt.Errorf("invalid CPE: %s", s)
+	}
+
+	return WFN{
+		Part:    fields[2],
+		Vendor:  unescape(fields[3]),
+		Product: unescape(fields[4]),
+		Version: unescape(fields[5]),
+		Update:  unescape(fields[6]),
+	}, nil
+}
+
+// 按未转义的冒号分割
+func splitUnescaped(s string) []string {
+	var fields []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			current.WriteRune(r)
+			escaped = true
+		case r == ':':
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(fields, current.String())
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var (
+	parenthesesPattern = regexp.MustCompile(`[(\[][^)\]]*[)\]]`)
+	versionWordPattern = regexp.MustCompile(`^v?\d+(\.\d+)+\S*$|^(x86|x64|amd64|arm64|32-bit|64-bit)$`)
+)
+
+// 保留标点的产品名，例如 "Notepad++ 8.6 (64-bit)" -> "notepad++"，"7-Zip 23.01" -> "7-zip"
+func rawProduct(name string) string {
+	name = strings.ToLower(parenthesesPattern.ReplaceAllString(name, " "))
+	var words []string
+	for _, word := range strings.Fields(name) {
+		if !versionWordPattern.MatchString(word) {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, "_")
+}
+
+// NVD中vendor无法由发布者或产品名推导出的常见软件
+var vendorAliases = map[string]string{
+	"notepad++":        "notepad-plus-plus",
+	"vlc_media_player": "videolan",
+	"git":              "git-scm",
+	"foxit_pdf_reader": "foxit",
+}
+
+// Candidates 列出应用可能对应的 vendor:product 组合，按可信度从高到低排列。
+// NVD中的命名并不统一，例如 7-Zip 为 7-zip:7-zip，Firefox 为 mozilla:firefox，
+// 因此除了发布者外，也会尝试将产品名作为vendor。
+func Candidates(publisher, name string) []Candidate {
+	type weighted struct {
+		value      string
+		confidence float64
+	}
+
+	vendor := Vendor(publisher)
+	var vendors []weighted
+	if vendor != "" {
+		vendors = append(vendors, weighted{vendor, 1.0})
+		if idx := strings.Index(vendor, "_"); idx > 0 {
+			vendors = append(vendors, weighted{vendor[:idx], 0.8})
+		}
+	}
+
+	raw := rawProduct(name)
+	products := []weighted{
+		{raw, 1.0},
+		{Product(name, ""), 0.9},
+	}
+	for _, v := range vendors {
+		products = append(products,
+			weighted{strings.TrimPrefix(raw, v.value+"_"), 1.0},
+			weighted{Product(name, v.value), 0.9})
+	}
+	// 产品名本身作为vendor
+	vendors = append(vendors, weighted{raw, 0.7}, weighted{Product(name, ""), 0.6})
+	if alias, ok := vendorAliases[raw]; ok {
+		vendors = append(vendors, weighted{alias, 0.9})
+	}
+
+	best := make(map[string]int)
+	var candidates []Candidate
+	for _, v := range vendors {
+		for _, p := range products {
+			if v.value == "" || p.value == "" || !isASCII(v.value+p.value) {
+				continue
+			}
+			c := Candidate{Vendor: v.value, Product: p.value, Confidence: v.confidence * p.confidence}
+			key := c.Vendor + ":" + c.Product
+			if i, ok := best[key]; ok {
+				if c.Confidence > candidates[i].Confidence {
+					candidates[i].Confidence = c.Confidence
+				}
+				continue
+			}
+			best[key] = len(candidates)
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+	return candidates
+}
+
+// 字母、数字以及 "_"、"-"、"." 之外的字符需要用反斜杠转义
+func escape(s string) string {
+	if s == "" {
+		return "*"
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == ' ':
+			b.WriteRune('_')
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package cpe
+
+import "testing"
+
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		publisher, name, version string
+		want                     string
+	}{
+		{"Mozilla", "Mozilla Firefox (x64 en-US)", "125.0", "cpe:2.3:a:mozilla:firefox:125.0:*:*:*:*:*:*:*"},
+		{"Igor Pavlov", "7-Zip 23.01 (x64)", "23.01", "cpe:2.3:a:igor_pavlov:7_zip:23.01:*:*:*:*:*:*:*"},
+		{"Microsoft Corporation", "Microsoft Visual C++ 2015-2022 Redistributable (x64)", "14.38.33135.0", "cpe:2.3:a:microsoft:visual_c_2015_2022_redistributable:14.38.33135.0:*:*:*:*:*:*:*"},
+		{"Notepad++ Team", "Notepad++", "8.6.4", "cpe:2.3:a:notepad_team:notepad:8.6.4:*:*:*:*:*:*:*"},
+		{"Contoso", "Tool", "1.0+build:5", `cpe:2.3:a:contoso:tool:1.0\+build\:5:*:*:*:*:*:*:*`},
+		{"", "Firefox", "125.0", ""},
+		{"Mozilla", "Firefox", "", ""},
+		{"腾讯科技(深圳)有限公司", "微信", "3.9.10", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Derive(tt.publisher, tt.name, tt.version); got != tt.want {
+			t.Errorf("Derive(%q, %q, %q) = %q, want %q", tt.publisher, tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	wfn, err := Parse(`cpe:2.3:a:notepad-plus-plus:notepad\+\+:8.6.4:*:*:*:*:*:*:*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wfn.Part != "a" || wfn.Vendor != "notepad-plus-plus" || wfn.Product != "notepad++" || wfn.Version != "8.6.4" {
+		t.Errorf("Unexpected parse result: %+v", wfn)
+	}
+
+	wfn, err = Parse(`cpe:2.3:a:vendor:a\:b:1.0:-:*:*:*:*:*:*`)
+	if err != nil || wfn.Product != "a:b" || wfn.Update != "-" {
+		t.Errorf("Escaped colon not handled: %+v, %v", wfn, err)
+	}
+
+	if _, err := Parse("cpe:/a:mozilla:firefox:125.0"); err == nil {
+		t.Error("Expected error for CPE 2.2 URI")
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		publisher, name string
+		want            string
+	}{
+		{"Mozilla", "Mozilla Firefox (x64 en-US)", "mozilla:firefox"},
+		{"Igor Pavlov", "7-Zip 23.01 (x64)", "7-zip:7-zip"},
+		{"Notepad++ Team", "Notepad++ (64-bit x64)", "notepad:notepad++"},
+		{"Oracle Corporation", "Java 8 Update 381", "oracle:java_8_update_381"},
+		{"", "VLC media player", "vlc_media_player:vlc_media_player"},
+	}
+
+	for _, tt := range tests {
+		found := false
+		for _, c := range Candidates(tt.publisher, tt.name) {
+			if c.Vendor+":"+c.Product == tt.want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Candidates(%q, %q) = %+v, want %s among them", tt.publisher, tt.name, Candidates(tt.publisher, tt.name), tt.want)
+		}
+	}
+
+	if got := Candidates("腾讯", "微信"); len(got) != 0 {
+		t.Errorf("Expected no candidates for non-ASCII names, got %+v", got)
+	}
+
+	c := Candidates("Mozilla", "Mozilla Firefox")
+	if c[0].Confidence < c[len(c)-1].Confidence {
+		t.Error("Candidates are not sorted by confidence")
+	}
+}
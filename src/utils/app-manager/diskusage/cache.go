@@ -0,0 +1,89 @@
+package diskusage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache 按目录的修改时间缓存统计结果。
+// 目录的修改时间只在其直接子项增删时变化，因此另设最长有效期，过期后重新统计
+type Cache struct {
+	path   string
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+type cacheEntry struct {
+	Usage      *Usage    `json:"usage"`
+	MeasuredAt time.Time `json:"measuredAt"`
+}
+
+// OpenCache 读取缓存文件，文件不存在或损坏时从空缓存开始
+func OpenCache(path string, maxAge time.Duration) *Cache {
+	c := &Cache{
+		path:    path,
+		maxAge:  maxAge,
+		entries: make(map[string]cacheEntry),
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+func cacheKey(path string) string {
+	return strings.ToLower(filepath.Clean(path))
+}
+
+// Get 返回修改时间一致且未过期的结果
+func (c *Cache) Get(path string, modTime time.Time) (*Usage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[cacheKey(path)]
+	if !ok || e.Usage == nil || !e.Usage.ModTime.Equal(modTime) {
+		return nil, false
+	}
+	if c.maxAge > 0 && time.Since(e.MeasuredAt) > c.maxAge {
+		return nil, false
+	}
+	return e.Usage, true
+}
+
+// Put 保存统计结果
+func (c *Cache) Put(u *Usage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[cacheKey(u.Path)] = cacheEntry{Usage: u, MeasuredAt: time.Now()}
+	c.dirty = true
+}
+
+// Save 在有新结果时写回缓存文件
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package diskusage 统计目录实际占用的磁盘空间。
+//
+// 统计的是分配大小（按簇对齐，压缩和稀疏文件按实际占用计算），有多个硬链接的
+// 文件只计算一次，不进入符号链接和目录联接（junction），因此不会重复计算或陷入循环。
+package diskusage
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileID 唯一标识一个卷上的文件，同一文件的多个硬链接具有相同的 FileID
+type FileID struct {
+	Volume uint64
+	Index  uint64
+}
+
+// Info 是 Lstat 得到的文件信息
+type Info struct {
+	Size      int64 // 文件的逻辑大小
+	Allocated int64 // 实际占用的磁盘空间
+	ID        FileID
+	Links     uint32
+	Dir       bool
+	// 符号链接、目录联接等重解析点，不进入也不计算其目标
+	Reparse bool
+	ModTime time.Time
+}
+
+// Usage 是一个目录的统计结果
+type Usage struct {
+	Path      string    `json:"path"`
+	Allocated int64     `json:"allocated"`
+	Logical   int64     `json:"logical"`
+	Files     int       `json:"files"`
+	ModTime   time.Time `json:"modTime"` // 统计时目录本身的修改时间，用作缓存键
+	// 因权限等原因无法读取的文件或目录数量
+	Errors int `json:"errors,omitempty"`
+}
+
+// Measure 统计单个目录
+func Measure(root string) (*Usage, error) {
+	rootInfo, err := lstat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !rootInfo.Dir {
+		return nil, &os.PathError{Op: "measure", Path: root, Err: os.ErrInvalid}
+	}
+
+	w := &walker{
+		usage: &Usage{Path: root, ModTime: rootInfo.ModTime},
+		seen:  make(map[FileID]bool),
+	}
+	w.seen[rootInfo.ID] = true
+	w.walk(root)
+	return w.usage, nil
+}
+
+type walker struct {
+	usage *Usage
+	// 已计算过的文件和已进入的目录
+	seen map[FileID]bool
+}
+
+func (w *walker) walk(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.usage.Errors++
+		return
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		info, err := lstat(path)
+		if err != nil {
+			w.usage.Errors++
+			continue
+		}
+		if info.Reparse {
+			continue
+		}
+
+		// 多个硬链接只计算一次，同一目录不进入两次
+		hasID := info.ID != (FileID{})
+		if hasID && (info.Dir || info.Links > 1) {
+			if w.seen[info.ID] {
+				continue
+			}
+			w.seen[info.ID] = true
+		}
+
+		if info.Dir {
+			w.walk(path)
+			continue
+		}
+		w.usage.Allocated += info.Allocated
+		w.usage.Logical += info.Size
+		w.usage.Files++
+	}
+}
+
+// Result 是 MeasureAll 中一个目录的结果
+type Result struct {
+	Usage  *Usage
+	Err    error
+	Cached bool
+}
+
+// MeasureAll 使用最多 workers 个并发任务统计多个目录，cache 可以为 nil。
+// 重复的路径只统计一次
+func MeasureAll(paths []string, workers int, cache *Cache) map[string]Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make(map[string]Result)
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				r := measureCached(path, cache)
+				mu.Lock()
+				results[path] = r
+				mu.Unlock()
+			}
+		}()
+	}
+
+	queued := make(map[string]bool)
+	for _, p := range paths {
+		if p == "" || queued[p] {
+			continue
+		}
+		queued[p] = true
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func measureCached(path string, cache *Cache) Result {
+	if cache != nil {
+		if info, err := lstat(path); err == nil {
+			if u, ok := cache.Get(path, info.ModTime); ok {
+				return Result{Usage: u, Cached: true}
+			}
+		}
+	}
+
+	u, err := Measure(path)
+	if err != nil {
+		return Result{Err: err}
+	}
+	if cache != nil {
+		cache.Put(u)
+	}
+	return Result{Usage: u}
+}
@@ -0,0 +1,139 @@
+package diskusage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.bin"), 1000)
+	writeFile(t, filepath.Join(root, "sub", "b.bin"), 3000)
+	writeFile(t, filepath.Join(root, "sub", "deep", "c.bin"), 500)
+
+	u, err := Measure(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Files != 3 || u.Logical != 4500 {
+		t.Errorf("Measure = %d files, %d bytes, want 3 files, 4500 bytes", u.Files, u.Logical)
+	}
+	if u.Allocated <= 0 {
+		t.Errorf("Allocated = %d, want > 0", u.Allocated)
+	}
+}
+
+func TestMeasureHardLinks(t *testing.T) {
+	root := t.TempDir()
+	orig := filepath.Join(root, "a.bin")
+	writeFile(t, orig, 4096)
+	if err := os.Link(orig, filepath.Join(root, "b.bin")); err != nil {
+		t.Skipf("hard links not supported: %v", err)
+	}
+	os.Mkdir(filepath.Join(root, "sub"), 0755)
+	if err := os.Link(orig, filepath.Join(root, "sub", "c.bin")); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := Measure(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Files != 1 || u.Logical != 4096 {
+		t.Errorf("Measure = %d files, %d bytes, want hard links counted once", u.Files, u.Logical)
+	}
+}
+
+func TestMeasureSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "sub", "a.bin"), 100)
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(outside, "big.bin"), 10000)
+
+	if err := os.Symlink(root, filepath.Join(root, "sub", "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	os.Symlink(outside, filepath.Join(root, "outside"))
+	os.Symlink(filepath.Join(outside, "big.bin"), filepath.Join(root, "big.bin"))
+
+	u, err := Measure(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Files != 1 || u.Logical != 100 {
+		t.Errorf("Measure = %d files, %d bytes, want links not followed", u.Files, u.Logical)
+	}
+}
+
+func TestMeasureNotDir(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "a.bin")
+	writeFile(t, file, 10)
+
+	if _, err := Measure(file); err == nil {
+		t.Error("Measure(file) succeeded, want error")
+	}
+	if _, err := Measure(filepath.Join(root, "missing")); err == nil {
+		t.Error("Measure(missing) succeeded, want error")
+	}
+}
+
+func TestMeasureAllCache(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(a, "x.bin"), 100)
+	writeFile(t, filepath.Join(b, "y.bin"), 200)
+	cachePath := filepath.Join(t.TempDir(), "cache", "sizes.json")
+
+	cache := OpenCache(cachePath, time.Hour)
+	results := MeasureAll([]string{a, b, a, ""}, 4, cache)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for path, r := range results {
+		if r.Err != nil || r.Cached {
+			t.Errorf("%s: err=%v cached=%v, want fresh result", path, r.Err, r.Cached)
+		}
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 内容变化但目录修改时间未变时使用缓存
+	writeFile(t, filepath.Join(b, "y.bin"), 300)
+	cache = OpenCache(cachePath, time.Hour)
+	results = MeasureAll([]string{a, b}, 2, cache)
+	if !results[a].Cached || !results[b].Cached {
+		t.Errorf("second run not cached: %+v", results)
+	}
+
+	// 新增子项改变目录修改时间，重新统计
+	later := time.Now().Add(time.Minute)
+	writeFile(t, filepath.Join(b, "z.bin"), 50)
+	os.Chtimes(b, later, later)
+	results = MeasureAll([]string{a, b}, 2, cache)
+	if results[b].Cached || results[b].Usage.Logical != 350 {
+		t.Errorf("after change: cached=%v usage=%+v, want fresh 350 bytes", results[b].Cached, results[b].Usage)
+	}
+	if !results[a].Cached {
+		t.Error("unchanged directory not cached")
+	}
+
+	// 过期的结果不再使用
+	cache = OpenCache(cachePath, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if r := MeasureAll([]string{a}, 1, cache)[a]; r.Cached {
+		t.Error("expired entry used")
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package diskusage
+
+import (
+	"os"
+	"syscall"
+)
+
+func lstat(path string) (Info, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return Info{}, err
+	}
+
+	info := Info{
+		Size:      fi.Size(),
+		Allocated: fi.Size(),
+		Dir:       fi.IsDir(),
+		Reparse:   fi.Mode()&os.ModeSymlink != 0,
+		ModTime:   fi.ModTime(),
+		Links:     1,
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		info.ID = FileID{Volume: uint64(st.Dev), Index: uint64(st.Ino)}
+		info.Links = uint32(st.Nlink)
+		info.Allocated = int64(st.Blocks) * 512
+	}
+	return info, nil
+}
@@ -0,0 +1,68 @@
+package diskusage
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// FILE_STANDARD_INFO
+type fileStandardInfo struct {
+	AllocationSize int64
+	EndOfFile      int64
+	NumberOfLinks  uint32
+	DeletePending  bool
+	Directory      bool
+}
+
+func lstat(path string) (Info, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return Info{}, err
+	}
+
+	info := Info{
+		Size:      fi.Size(),
+		Allocated: fi.Size(),
+		Dir:       fi.IsDir(),
+		ModTime:   fi.ModTime(),
+		Links:     1,
+	}
+	if attr, ok := fi.Sys().(*syscall.Win32FileAttributeData); ok && attr.FileAttributes&windows.FILE_ATTRIBUTE_REPARSE_POINT != 0 {
+		info.Reparse = true
+		return info, nil
+	}
+
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return info, nil
+	}
+	// 只需要读取属性，FILE_FLAG_BACKUP_SEMANTICS 允许打开目录
+	h, err := windows.CreateFile(pathPtr, windows.FILE_READ_ATTRIBUTES,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		// 无法打开时使用逻辑大小，不影响统计
+		return info, nil
+	}
+	defer windows.CloseHandle(h)
+
+	var byHandle windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(h, &byHandle); err == nil {
+		info.ID = FileID{
+			Volume: uint64(byHandle.VolumeSerialNumber),
+			Index:  uint64(byHandle.FileIndexHigh)<<32 | uint64(byHandle.FileIndexLow),
+		}
+		info.Links = byHandle.NumberOfLinks
+	}
+
+	if !info.Dir {
+		var std fileStandardInfo
+		if err := windows.GetFileInformationByHandleEx(h, windows.FileStandardInfo, (*byte)(unsafe.Pointer(&std)), uint32(unsafe.Sizeof(std))); err == nil {
+			info.Allocated = std.AllocationSize
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/uninstall"
+)
+
+// 退出码，--json 时也写在信封的 exitCode 中
+const (
+	exitOK             = 0  // 成功
+	exitError          = 1  // 其他错误
+	exitViolation      = 2  // audit 发现违反策略的应用
+	exitNotFound       = 3  // 没有匹配的应用或备份
+	exitAmbiguous      = 4  // 匹配到多个应用
+	exitCancelled      = 5  // 用户取消了UAC提示或安装程序
+	exitRebootRequired = 6  // 成功，但需要重新启动才能完成
+	exitTimeout        = 7  // 等待安装程序超时
+	exitPartial        = 8  // enforce 中部分应用卸载失败
+	exitUsage          = 64 // 命令行参数错误
+)
+
+// 退出码说明，用于帮助信息
+var exitCodeHelp = []struct {
+	code int
+	text string
+}{
+	{exitOK, "成功"},
+	{exitError, "其他错误"},
+	{exitViolation, "audit 发现违反策略的应用"},
+	{exitNotFound, "没有匹配的应用或备份"},
+	{exitAmbiguous, "匹配到多个应用"},
+	{exitCancelled, "已取消"},
+	{exitRebootRequired, "成功，需要重新启动"},
+	{exitTimeout, "超时"},
+	{exitPartial, "部分应用处理失败"},
+	{exitUsage, "参数错误"},
+}
+
+// 根据错误代码确定退出码
+func exitCodeOf(code errcode.Code) int {
+	switch code {
+	case "":
+		return exitOK
+	case errcode.AppNotFound, errcode.BackupNotFound:
+		return exitNotFound
+	case errcode.AmbiguousMatch:
+		return exitAmbiguous
+	case errcode.Cancelled:
+		return exitCancelled
+	case errcode.Timeout:
+		return exitTimeout
+	case errcode.Usage, errcode.InvalidArgument:
+		return exitUsage
+	default:
+		return exitError
+	}
+}
+
+// 操作结果对应的退出码
+func actionExitCode(result *uninstall.Result) int {
+	if result.Success && result.RebootRequired {
+		return exitRebootRequired
+	}
+	return exitCodeOf(result.Code)
+}
+
+// envelopeVersion 在信封或 data 的结构发生不兼容的变化时增加
+const envelopeVersion = 1
+
+// Envelope 是 --json 时所有命令唯一的输出
+type Envelope struct {
+	Version  int            `json:"version"`
+	Command  string         `json:"command"`
+	Success  bool           `json:"success"`
+	ExitCode int            `json:"exitCode"`
+	Data     interface{}    `json:"data,omitempty"`
+	Error    *EnvelopeError `json:"error,omitempty"`
+}
+
+type EnvelopeError struct {
+	Code    errcode.Code   `json:"code"`
+	Message string         `json:"message"`
+	Params  errcode.Params `json:"params,omitempty"`
+}
+
+func newEnvelope(command string, data interface{}, exitCode int, err error) *Envelope {
+	env := &Envelope{
+		Version:  envelopeVersion,
+		Command:  command,
+		Success:  err == nil,
+		ExitCode: exitCode,
+		Data:     data,
+	}
+	if err != nil {
+		env.Error = &EnvelopeError{
+			Code:    errcode.CodeOf(err),
+			Message: err.Error(),
+			Params:  errcode.ParamsOf(err),
+		}
+	}
+	return env
+}
+
+// 命令的输出方式
+type cli struct {
+	command string
+	json    bool
+	stdout  io.Writer
+	exit    func(code int)
+	start   time.Time
+	// 日志文件，退出前关闭；os.Exit 不会执行 defer
+	log io.Closer
+}
+
+func newCLI(command string, jsonOutput bool) *cli {
+	return &cli{command: command, json: jsonOutput, stdout: os.Stdout, exit: os.Exit, start: time.Now()}
+}
+
+// 输出命令结果。--json 时 data 放在信封中，否则调用 write 按 --format 等参数输出
+func (c *cli) output(data interface{}, write func(w io.Writer) error) {
+	c.finish(data, exitOK, nil, write)
+}
+
+// 输出结果后以 exitCode 退出，err 为 nil 时视为成功。write 为 nil 时非JSON模式只输出错误
+func (c *cli) finish(data interface{}, exitCode int, err error, write func(w io.Writer) error) {
+	if c.json {
+		jsonData, _ := json.MarshalIndent(newEnvelope(c.command, data, exitCode, err), "", "  ")
+		fmt.Fprintln(c.stdout, string(jsonData))
+	} else if write != nil {
+		if werr := write(c.stdout); werr != nil {
+			c.fail(werr)
+			return
+		}
+	} else if err != nil {
+		c.printError(err)
+	}
+
+	if err != nil {
+		slog.Warn("命令失败", "command", c.command, "exitCode", exitCode, "code", errcode.CodeOf(err), "error", err, "duration", time.Since(c.start))
+	} else {
+		slog.Info("命令完成", "command", c.command, "exitCode", exitCode, "duration", time.Since(c.start))
+	}
+	if exitCode != exitOK {
+		c.quit(exitCode)
+	}
+}
+
+// 关闭日志文件后以 exitCode 退出
+func (c *cli) quit(exitCode int) {
+	c.closeLog()
+	c.exit(exitCode)
+}
+
+// 关闭日志文件，可以多次调用
+func (c *cli) closeLog() {
+	if c.log != nil {
+		c.log.Close()
+		c.log = nil
+	}
+}
+
+func (c *cli) printError(err error) {
+	if errcode.CodeOf(err) == errcode.Usage {
+		fmt.Fprintln(c.stdout, err.Error())
+		return
+	}
+	fmt.Fprintln(c.stdout, errcode.Text(errcode.Language(), "error", errcode.Params{"message": err.Error()}))
+}
+
+// 输出错误并退出，退出码由错误代码确定
+func (c *cli) fail(err error) {
+	c.finish(nil, exitCodeOf(errcode.CodeOf(err)), err, nil)
+}
+
+// 输出命令的用法并以 exitUsage 退出
+func (c *cli) usage(usage string) {
+	c.fail(errcode.New(errcode.Usage, errcode.Params{"usage": usage}))
+}
+
+// 以缩进的JSON输出 v，用于已经是JSON的输出格式
+func writeJSON(v interface{}) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		jsonData, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/uninstall"
+)
+
+func TestExitCodeOf(t *testing.T) {
+	tests := map[errcode.Code]int{
+		"":                      exitOK,
+		errcode.AppNotFound:     exitNotFound,
+		errcode.AmbiguousMatch:  exitAmbiguous,
+		errcode.Cancelled:       exitCancelled,
+		errcode.Timeout:         exitTimeout,
+		errcode.Usage:           exitUsage,
+		errcode.InvalidArgument: exitUsage,
+		errcode.InstallerFailed: exitError,
+		errcode.Internal:        exitError,
+	}
+	for code, want := range tests {
+		if got := exitCodeOf(code); got != want {
+			t.Errorf("exitCodeOf(%q) = %d, want %d", code, got, want)
+		}
+	}
+
+	if got := actionExitCode(&uninstall.Result{Success: true, RebootRequired: true}); got != exitRebootRequired {
+		t.Errorf("actionExitCode(reboot) = %d, want %d", got, exitRebootRequired)
+	}
+}
+
+func testCLI(jsonOutput bool) (*cli, *bytes.Buffer, *int) {
+	var buf bytes.Buffer
+	code := -1
+	return &cli{command: "list", json: jsonOutput, stdout: &buf, exit: func(c int) { code = c }}, &buf, &code
+}
+
+func TestCLIEnvelope(t *testing.T) {
+	errcode.SetLanguage(errcode.EnUS)
+
+	c, buf, code := testCLI(true)
+	c.output([]string{"7-Zip"}, func(w io.Writer) error {
+		t.Error("write should not be called with --json")
+		return nil
+	})
+	var env Envelope
+	if err := json.Unmarshal(buf.Bytes(), &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Version != envelopeVersion || env.Command != "list" || !env.Success || env.ExitCode != exitOK || env.Error != nil || *code != -1 {
+		t.Errorf("envelope = %+v, exit %d", env, *code)
+	}
+
+	c, buf, code = testCLI(true)
+	c.fail(errcode.New(errcode.AppNotFound, errcode.Params{"name": "foo"}))
+	env = Envelope{}
+	if err := json.Unmarshal(buf.Bytes(), &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Success || env.ExitCode != exitNotFound || env.Error == nil || env.Error.Code != errcode.AppNotFound || env.Error.Params["name"] != "foo" || *code != exitNotFound {
+		t.Errorf("envelope = %+v, exit %d", env, *code)
+	}
+}
+
+func TestCLIText(t *testing.T) {
+	errcode.SetLanguage(errcode.EnUS)
+
+	c, buf, code := testCLI(false)
+	c.output(nil, func(w io.Writer) error {
+		_, err := io.WriteString(w, "7-Zip\n")
+		return err
+	})
+	if buf.String() != "7-Zip\n" || *code != -1 {
+		t.Errorf("output = %q, exit %d", buf.String(), *code)
+	}
+
+	c, buf, code = testCLI(false)
+	c.usage("appman list")
+	if !strings.Contains(buf.String(), "appman list") || strings.HasPrefix(buf.String(), "错误") || *code != exitUsage {
+		t.Errorf("usage = %q, exit %d", buf.String(), *code)
+	}
+
+	c, buf, _ = testCLI(false)
+	c.fail(errcode.New(errcode.AppNotFound, errcode.Params{"name": "foo"}))
+	if !strings.HasPrefix(buf.String(), "Error: ") || !strings.Contains(buf.String(), "foo") {
+		t.Errorf("error = %q", buf.String())
+	}
+}
+
+type closeRecorder struct{ closed int }
+
+func (r *closeRecorder) Close() error {
+	r.closed++
+	return nil
+}
+
+func TestCLIClosesLogBeforeExit(t *testing.T) {
+	log := &closeRecorder{}
+	c, _, _ := testCLI(false)
+	c.log = log
+	c.exit = func(int) {
+		if log.closed != 1 {
+			t.Errorf("log closed %d times before exit, want 1", log.closed)
+		}
+	}
+	c.fail(errcode.New(errcode.Internal, nil))
+
+	c.closeLog()
+	if log.closed != 1 {
+		t.Errorf("log closed %d times, want 1", log.closed)
+	}
+}
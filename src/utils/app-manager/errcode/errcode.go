@@ -0,0 +1,131 @@
+// Package errcode 定义各命令结果中稳定的错误代码。
+//
+// 错误代码和参数用于程序判断，不随语言变化；错误消息由 locales 中的消息目录
+// 按语言生成，目前支持 zh-CN 和 en-US。目录中也包含少量非错误消息，见 Text：
+//
+//	err := errcode.New(errcode.ExecutableNotFound, errcode.Params{"path": path})
+//	errcode.Message(errcode.EnUS, errcode.CodeOf(err), errcode.ParamsOf(err))
+package errcode
+
+import (
+	"errors"
+)
+
+// Code 是错误代码，序列化为大写字符串
+type Code string
+
+const (
+	// 查找应用
+	AppNotFound    Code = "APP_NOT_FOUND"   // name
+	AmbiguousMatch Code = "AMBIGUOUS_MATCH" // name, count
+	ScanFailed     Code = "SCAN_FAILED"     // reason
+
+	// 解析命令行
+	InvalidCommand     Code = "INVALID_COMMAND"      // command
+	NoExecutable       Code = "NO_EXECUTABLE"        // command
+	ExecutableNotFound Code = "EXECUTABLE_NOT_FOUND" // path
+
+	// 卸载、修复和修改
+	RequiresParent      Code = "REQUIRES_PARENT"      // app, parent, action
+	NotSupported        Code = "NOT_SUPPORTED"        // app, action
+	NoCommand           Code = "NO_COMMAND"           // app, action
+	LaunchFailed        Code = "LAUNCH_FAILED"        // action, reason
+	AccessDenied        Code = "ACCESS_DENIED"        // action
+	Cancelled           Code = "CANCELLED"            // action
+	Timeout             Code = "TIMEOUT"              // action
+	InstallerFailed     Code = "INSTALLER_FAILED"     // action, exitCode
+	UnsupportedPlatform Code = "UNSUPPORTED_PLATFORM" // action
+
+	// 提取图标
+	FileNotFound      Code = "FILE_NOT_FOUND"      // path
+	NoIcons           Code = "NO_ICONS"            // path
+	InvalidIconIndex  Code = "INVALID_ICON_INDEX"  // index, count, max
+	IconExtractFailed Code = "ICON_EXTRACT_FAILED" // index
+	IconRenderFailed  Code = "ICON_RENDER_FAILED"  // call
+	InvalidIconSize   Code = "INVALID_ICON_SIZE"
+
+	// 备份和恢复ARP注册表项
+	BackupNotFound    Code = "BACKUP_NOT_FOUND"   // backup
+	InvalidBackup     Code = "INVALID_BACKUP"     // path, reason
+	AlreadyRegistered Code = "ALREADY_REGISTERED" // key
+	RestoreFailed     Code = "RESTORE_FAILED"     // key, reason
+	BackupFailed      Code = "BACKUP_FAILED"      // key, reason
+
+	// 命令行参数错误
+	Usage           Code = "USAGE"            // usage，命令的用法
+	InvalidArgument Code = "INVALID_ARGUMENT" // reason
+	// 其他无法归类的错误
+	Internal Code = "INTERNAL" // reason
+)
+
+// Codes 返回所有错误代码
+func Codes() []Code {
+	return []Code{
+		AppNotFound, AmbiguousMatch, ScanFailed,
+		InvalidCommand, NoExecutable, ExecutableNotFound,
+		RequiresParent, NotSupported, NoCommand, LaunchFailed, AccessDenied, Cancelled, Timeout, InstallerFailed, UnsupportedPlatform,
+		FileNotFound, NoIcons, InvalidIconIndex, IconExtractFailed, IconRenderFailed, InvalidIconSize,
+		BackupNotFound, InvalidBackup, AlreadyRegistered, RestoreFailed, BackupFailed,
+		Usage, InvalidArgument, Internal,
+	}
+}
+
+// Params 是错误消息的参数，键为消息模板中 {name} 形式的占位符
+type Params map[string]string
+
+// Error 是带错误代码的错误，Error() 按当前语言生成消息
+type Error struct {
+	Code   Code
+	Params Params
+	// 引起错误的底层错误，可以为 nil
+	Err error
+}
+
+// New 创建错误
+func New(code Code, params Params) *Error {
+	return &Error{Code: code, Params: params}
+}
+
+// Wrap 创建以 err 为底层错误的错误，err 的消息作为 reason 参数
+func Wrap(code Code, params Params, err error) *Error {
+	p := Params{}
+	for k, v := range params {
+		p[k] = v
+	}
+	if err != nil {
+		p["reason"] = err.Error()
+	}
+	return &Error{Code: code, Params: p, Err: err}
+}
+
+func (e *Error) Error() string {
+	return Message(Language(), e.Code, e.Params)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf 返回错误链中的错误代码，没有时为 Internal，err 为 nil 时为空
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return Internal
+}
+
+// ParamsOf 返回错误链中的错误参数，没有错误代码的错误以其消息作为 reason 参数
+func ParamsOf(err error) Params {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Params
+	}
+	return Params{"reason": err.Error()}
+}
@@ -0,0 +1,80 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Languages() {
+		for _, code := range Codes() {
+			if _, ok := catalogs()[lang][string(code)]; !ok {
+				t.Errorf("%s: missing message for %s", lang, code)
+			}
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	params := Params{"app": "KB1", "parent": "Office", "action": "repair"}
+	if got, want := Message(ZhCN, RequiresParent, params), "KB1 是 Office 的组成部分，不能单独修复，请修复 Office"; got != want {
+		t.Errorf("zh-CN = %q, want %q", got, want)
+	}
+	if got := Message(EnUS, RequiresParent, params); !strings.HasSuffix(got, "repair Office instead") {
+		t.Errorf("en-US = %q", got)
+	}
+	if got, want := Message(EnUS, ExecutableNotFound, Params{"path": `C:\a.exe`}), `executable not found: C:\a.exe`; got != want {
+		t.Errorf("en-US = %q, want %q", got, want)
+	}
+	if got := Message("fr-FR", Timeout, Params{"action": "uninstall"}); got != "卸载超时，可能有进程仍在运行" {
+		t.Errorf("fallback = %q", got)
+	}
+	if got := Message(EnUS, "UNKNOWN_CODE", nil); got != "UNKNOWN_CODE" {
+		t.Errorf("unknown code = %q", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := map[string]string{
+		"zh-Hans-CN": ZhCN,
+		"zh_TW":      ZhCN,
+		"EN-gb":      EnUS,
+		"fr-FR":      "",
+	}
+	for tag, want := range tests {
+		if got := Match(tag); got != want {
+			t.Errorf("Match(%q) = %q, want %q", tag, got, want)
+		}
+	}
+	if got := Match("fr-FR", "en-US"); got != EnUS {
+		t.Errorf("Match(fr-FR, en-US) = %q", got)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	err := fmt.Errorf("uninstall: %w", Wrap(LaunchFailed, Params{"action": "uninstall"}, errors.New("exit status 1")))
+	if CodeOf(err) != LaunchFailed || ParamsOf(err)["reason"] != "exit status 1" {
+		t.Errorf("CodeOf = %s, ParamsOf = %v", CodeOf(err), ParamsOf(err))
+	}
+	if CodeOf(errors.New("x")) != Internal || ParamsOf(errors.New("x"))["reason"] != "x" {
+		t.Error("plain errors should be INTERNAL")
+	}
+	if CodeOf(nil) != "" {
+		t.Error("CodeOf(nil) should be empty")
+	}
+}
+
+func TestMessagePath(t *testing.T) {
+	for _, code := range []Code{FileNotFound, NoIcons} {
+		for _, lang := range Languages() {
+			if got := Message(lang, code, Params{"path": `C:\a.exe`}); !strings.Contains(got, `C:\a.exe`) {
+				t.Errorf("%s %s = %q, want the path", lang, code, got)
+			}
+		}
+	}
+	if got := Text(EnUS, "error", Params{"message": "boom"}); got != "Error: boom" {
+		t.Errorf("en-US error = %q", got)
+	}
+}
@@ -0,0 +1,105 @@
+package errcode
+
+import (
+	"embed"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	catalogs = sync.OnceValue(loadCatalogs)
+
+	mu      sync.RWMutex
+	current = ZhCN
+)
+
+func loadCatalogs() map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for _, lang := range Languages() {
+		data, err := locales.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(err)
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic("locales/" + lang + ".json: " + err.Error())
+		}
+		result[lang] = messages
+	}
+	return result
+}
+
+// Languages 返回支持的语言
+func Languages() []string {
+	return []string{ZhCN, EnUS}
+}
+
+// Match 返回第一个支持的语言，tags 为 "en-GB"、"zh-Hans-CN" 等 BCP 47 标签，
+// 按主语言匹配。都不支持时返回空字符串
+func Match(tags ...string) string {
+	for _, tag := range tags {
+		primary, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(tag, "_", "-")), "-")
+		switch primary {
+		case "zh":
+			return ZhCN
+		case "en":
+			return EnUS
+		}
+	}
+	return ""
+}
+
+// SetLanguage 设置 Error() 使用的语言，默认为 zh-CN
+func SetLanguage(lang string) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = lang
+}
+
+// Language 返回 Error() 使用的语言
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Message 按语言生成错误消息。不支持的语言使用 zh-CN，
+// 目录中没有的代码返回代码本身。
+// 参数值在目录中有 "参数.值" 形式的翻译时（如 action.uninstall）使用翻译
+func Message(lang string, code Code, params Params) string {
+	return Text(lang, string(code), params)
+}
+
+// Text 按语言生成目录中的其他消息，如操作成功的提示，规则与 Message 相同
+func Text(lang, key string, params Params) string {
+	messages, ok := catalogs()[lang]
+	if !ok {
+		messages = catalogs()[ZhCN]
+	}
+	text, ok := messages[key]
+	if !ok {
+		return key
+	}
+
+	if len(params) == 0 {
+		return text
+	}
+	replacements := make([]string, 0, 2*len(params))
+	for k, v := range params {
+		if translated, ok := messages[k+"."+v]; ok {
+			v = translated
+		}
+		replacements = append(replacements, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(replacements...).Replace(text)
+}
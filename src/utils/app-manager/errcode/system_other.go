@@ -0,0 +1,23 @@
+//go:build !windows
+
+package errcode
+
+import (
+	"os"
+	"strings"
+)
+
+// SystemLanguage 按 LC_ALL、LC_MESSAGES 和 LANG 环境变量选择语言，都不支持时使用 en-US
+func SystemLanguage() string {
+	var tags []string
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		// 如 zh_CN.UTF-8
+		if v, _, _ := strings.Cut(os.Getenv(name), "."); v != "" {
+			tags = append(tags, v)
+		}
+	}
+	if lang := Match(tags...); lang != "" {
+		return lang
+	}
+	return EnUS
+}
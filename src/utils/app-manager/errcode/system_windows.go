@@ -0,0 +1,15 @@
+package errcode
+
+import "golang.org/x/sys/windows"
+
+// SystemLanguage 按用户的首选界面语言选择语言，都不支持时使用 en-US，无法读取时使用 zh-CN
+func SystemLanguage() string {
+	names, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME)
+	if err != nil {
+		return ZhCN
+	}
+	if lang := Match(names...); lang != "" {
+		return lang
+	}
+	return EnUS
+}
@@ -0,0 +1,164 @@
+// Package hierarchy 识别“程序和功能”中的更新、补丁和子组件，并找到它们所属的父产品。
+//
+// 子项通过卸载注册表项中的 ParentKeyName、ParentDisplayName、ReleaseType 或
+// IsMinorUpgrade 标识。ParentKeyName 是父产品在同一 Uninstall 键下的子键名。
+package hierarchy
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Entry 是一个卸载注册表项中与层级有关的值
+type Entry struct {
+	// 注册表项所在的 Uninstall 键，优先在同一个键下查找父产品
+	Group             string
+	KeyName           string
+	DisplayName       string
+	ParentKeyName     string
+	ParentDisplayName string
+	ReleaseType       string
+	IsMinorUpgrade    bool
+}
+
+// 表示更新的 ReleaseType，比较时不区分大小写
+var updateReleaseTypes = []string{"update", "hotfix", "security update", "service pack", "update rollup"}
+
+// IsUpdate 判断 ReleaseType 是否表示更新或补丁
+func IsUpdate(releaseType string) bool {
+	releaseType = strings.ToLower(strings.TrimSpace(releaseType))
+	for _, t := range updateReleaseTypes {
+		if releaseType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresParent 判断该项是否指明了父产品，这样的项不能单独卸载
+func (e *Entry) RequiresParent() bool {
+	return e.ParentKeyName != "" || e.ParentDisplayName != ""
+}
+
+// IsChild 判断该项是否为更新、补丁或子组件
+func (e *Entry) IsChild() bool {
+	return e.RequiresParent() || e.IsMinorUpgrade || IsUpdate(e.ReleaseType)
+}
+
+// Parents 返回每一项的父项索引，顶层项或找不到父项时为 -1。
+// 优先按 ParentKeyName 匹配，其次按 ParentDisplayName，没有指明父产品的更新按名称推断，
+// 形成环的项视为顶层项
+func Parents(entries []Entry) []int {
+	type key struct{ group, name string }
+	byKey := make(map[key]int)
+	byKeyName := make(map[string]int)
+	byDisplayName := make(map[string]int)
+	for i := range entries {
+		e := &entries[i]
+		name := strings.ToLower(e.KeyName)
+		if _, ok := byKey[key{e.Group, name}]; !ok && name != "" {
+			byKey[key{e.Group, name}] = i
+		}
+		if _, ok := byKeyName[name]; !ok && name != "" {
+			byKeyName[name] = i
+		}
+		// 同名时优先选择非子项作为父项
+		display := strings.ToLower(strings.TrimSpace(e.DisplayName))
+		if j, ok := byDisplayName[display]; display != "" && (!ok || entries[j].IsChild() && !e.IsChild()) {
+			byDisplayName[display] = i
+		}
+	}
+
+	parents := make([]int, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		parents[i] = -1
+		if !e.RequiresParent() {
+			// 只有 ReleaseType 或 IsMinorUpgrade 标识的更新没有指明父产品，按名称推断
+			if e.IsChild() {
+				parents[i] = parentByName(entries, i)
+			}
+			continue
+		}
+
+		if name := strings.ToLower(e.ParentKeyName); name != "" {
+			if j, ok := byKey[key{e.Group, name}]; ok {
+				parents[i] = j
+			} else if j, ok := byKeyName[name]; ok {
+				parents[i] = j
+			}
+		}
+		if parents[i] < 0 && e.ParentDisplayName != "" {
+			if j, ok := byDisplayName[strings.ToLower(strings.TrimSpace(e.ParentDisplayName))]; ok {
+				parents[i] = j
+			}
+		}
+		if parents[i] == i {
+			parents[i] = -1
+		}
+	}
+
+	// 沿父项向上查找，回到自身说明存在环，环上的项都视为顶层项
+	linked := append([]int{}, parents...)
+	for i := range linked {
+		for j, steps := linked[i], 0; j >= 0 && steps <= len(linked); j, steps = linked[j], steps+1 {
+			if j == i {
+				parents[i] = -1
+				break
+			}
+		}
+	}
+	return parents
+}
+
+// 在非子项中查找名称包含在 entries[i] 名称中的产品，如 "Update for Contoso App (KB1)"
+// 属于 "Contoso App"。名称必须按完整的词出现，有多个时选择名称最长的，优先同一个 Uninstall 键
+func parentByName(entries []Entry, i int) int {
+	name := strings.ToLower(entries[i].DisplayName)
+	best := -1
+	for j := range entries {
+		p := &entries[j]
+		if j == i || p.IsChild() {
+			continue
+		}
+		candidate := strings.ToLower(strings.TrimSpace(p.DisplayName))
+		if !containsWord(name, candidate) {
+			continue
+		}
+		if best < 0 {
+			best = j
+			continue
+		}
+		sameGroup, bestSameGroup := p.Group == entries[i].Group, entries[best].Group == entries[i].Group
+		longer := len(candidate) > len(strings.TrimSpace(entries[best].DisplayName))
+		if sameGroup && !bestSameGroup || sameGroup == bestSameGroup && longer {
+			best = j
+		}
+	}
+	return best
+}
+
+// 判断 word 是否作为完整的词出现在 s 中，前后不能紧接字母或数字
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(s) || !isWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,76 @@
+package hierarchy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsChild(t *testing.T) {
+	tests := []struct {
+		entry Entry
+		want  bool
+	}{
+		{Entry{DisplayName: "Office"}, false},
+		{Entry{ReleaseType: "Security Update"}, true},
+		{Entry{ReleaseType: "hotfix"}, true},
+		{Entry{ReleaseType: "Product"}, false},
+		{Entry{IsMinorUpgrade: true}, true},
+		{Entry{ParentKeyName: "Office16"}, true},
+		{Entry{ParentDisplayName: "Microsoft Office"}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.IsChild(); got != tt.want {
+			t.Errorf("IsChild(%+v) = %v, want %v", tt.entry, got, tt.want)
+		}
+	}
+	if (&Entry{ReleaseType: "Update"}).RequiresParent() {
+		t.Error("ReleaseType alone should not require a parent")
+	}
+}
+
+func TestParents(t *testing.T) {
+	entries := []Entry{
+		0:  {Group: "HKLM", KeyName: "Office16", DisplayName: "Microsoft Office"},
+		1:  {Group: "HKLM", KeyName: "KB123", DisplayName: "Update for Office (KB123)", ParentKeyName: "office16", ReleaseType: "Update"},
+		2:  {Group: "HKLM", KeyName: "LangPack", DisplayName: "Office Language Pack", ParentDisplayName: "Microsoft Office"},
+		3:  {Group: "HKLM", KeyName: "Orphan", DisplayName: "Update for Missing", ParentKeyName: "Missing"},
+		4:  {Group: "HKCU", KeyName: "Office16", DisplayName: "Microsoft Office"},
+		5:  {Group: "HKCU", KeyName: "KB456", DisplayName: "Update (KB456)", ParentKeyName: "Office16"},
+		6:  {Group: "HKLM", KeyName: "Hotfix", DisplayName: "Hotfix", ReleaseType: "Hotfix"},
+		7:  {Group: "HKLM", KeyName: "Self", DisplayName: "Self", ParentKeyName: "Self"},
+		8:  {Group: "HKLM", KeyName: "A", DisplayName: "A", ParentKeyName: "B"},
+		9:  {Group: "HKLM", KeyName: "B", DisplayName: "B", ParentKeyName: "A"},
+		10: {Group: "HKLM", KeyName: "SP", DisplayName: "Service Pack", ParentKeyName: "KB123"},
+	}
+	want := []int{-1, 0, 0, -1, -1, 4, -1, -1, -1, -1, 1}
+	if got := Parents(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parents = %v, want %v", got, want)
+	}
+}
+
+func TestParentsPreferProduct(t *testing.T) {
+	entries := []Entry{
+		{KeyName: "Patch", DisplayName: "Contoso", ReleaseType: "Update"},
+		{KeyName: "Product", DisplayName: "Contoso"},
+		{KeyName: "Child", DisplayName: "Contoso Addon", ParentDisplayName: "contoso"},
+	}
+	if got := Parents(entries); got[2] != 1 {
+		t.Errorf("parent of addon = %d, want 1", got[2])
+	}
+}
+
+func TestParentsByName(t *testing.T) {
+	entries := []Entry{
+		0: {Group: "HKLM", KeyName: "App", DisplayName: "Contoso App"},
+		1: {Group: "HKLM", KeyName: "KB1", DisplayName: "Hotfix for Contoso App (KB1)", ReleaseType: "Hotfix"},
+		2: {Group: "HKLM", KeyName: "Contoso", DisplayName: "Contoso"},
+		3: {Group: "HKLM", KeyName: "KB2", DisplayName: "Contoso Apps Update", ReleaseType: "Update"},
+		4: {Group: "HKLM", KeyName: "Minor", DisplayName: "Contoso App", IsMinorUpgrade: true},
+		5: {Group: "HKLM", KeyName: "KB3", DisplayName: "Security Update (KB3)", ReleaseType: "Security Update"},
+	}
+	// KB2 中的 "Contoso Apps" 不是 "Contoso App" 这个词，只能归到 "Contoso"
+	want := []int{-1, 0, -1, 2, 0, -1}
+	if got := Parents(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parents = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/history"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/query"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/uninstall"
+)
+
+// 操作历史记录文件，与占用空间缓存同在 %LOCALAPPDATA%\appman 下，不随漫游配置文件同步
+func historyPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "appman", "history.jsonl")
+}
+
+// 卸载前备份的注册表项，文件名为历史记录的ID
+func backupPath(id string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "appman", "backups", id+".reg")
+}
+
+// 执行卸载、修复或修改并写入历史记录，记录失败不影响操作结果。卸载前先备份ARP注册表项
+func recordAction(app *App, action string, run func(*App, ...uninstall.Option) *uninstall.Result, opts ...uninstall.Option) *uninstall.Result {
+	start := time.Now()
+	id := history.NewID(start)
+	// 放在最前面，调用者可以用 WithBackup("") 关闭备份
+	if path := backupPath(id); path != "" && action == uninstall.ActionUninstall {
+		opts = append([]uninstall.Option{uninstall.WithBackup(path)}, opts...)
+	}
+	result := run(app, opts...)
+
+	entry := &history.Entry{
+		ID:             id,
+		Timestamp:      start,
+		Action:         action,
+		App:            *app,
+		Command:        result.Command,
+		Backup:         result.Backup,
+		Invocation:     os.Args[1:],
+		Success:        result.Success,
+		Code:           result.Code,
+		Error:          result.Error,
+		Params:         result.Params,
+		ExitCode:       result.ExitCode,
+		RebootRequired: result.RebootRequired,
+		DurationMs:     time.Since(start).Milliseconds(),
+	}
+	if action == uninstall.ActionUninstall && result.Success {
+		entry.Verification, entry.Leftovers = verifyRemoval(app)
+	}
+
+	if path := historyPath(); path != "" {
+		if err := history.Append(path, entry); err != nil {
+			slog.Warn("无法写入历史记录", "path", path, "error", err)
+		}
+	}
+	return result
+}
+
+// 确认卸载后ARP注册表项已被删除，并列出仍然存在的注册表项和安装目录
+func verifyRemoval(app *App) (string, []string) {
+	verification := history.VerifyRemoved
+	leftovers := []string{}
+	if inventory.KeyExists(app.RegistryKey) {
+		verification = history.VerifyStillInstalled
+		leftovers = append(leftovers, app.RegistryKey)
+	}
+	if dir := app.InstallDir(); dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			leftovers = append(leftovers, dir)
+		}
+	}
+	slog.Info("确认卸载结果", "app", app.DisplayName, "verification", verification, "leftovers", leftovers)
+	return verification, leftovers
+}
+
+// 根据 history 命令的参数构造查询条件
+func historyFilter(since, until, action, name, status string, limit int) (*history.Filter, error) {
+	now := time.Now()
+	f := &history.Filter{Action: action, Status: status, Limit: limit}
+	var err error
+	if f.Since, err = history.ParseTime(since, now); err != nil {
+		return nil, fmt.Errorf("--since: %v", err)
+	}
+	if f.Until, err = history.ParseTime(until, now); err != nil {
+		return nil, fmt.Errorf("--until: %v", err)
+	}
+	if f.Name, err = query.ParsePattern(name); err != nil {
+		return nil, fmt.Errorf("--name: %v", err)
+	}
+	if status != "" && status != history.StatusSuccess && status != history.StatusFailed {
+		return nil, fmt.Errorf("--status 只能是 %s 或 %s", history.StatusSuccess, history.StatusFailed)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("--limit 不能为负数")
+	}
+	return f, nil
+}
+
+type HistoryResult struct {
+	Success bool            `json:"success"`
+	Entries []history.Entry `json:"entries"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// 表格中的结果列
+func historyStatus(e *history.Entry) string {
+	switch {
+	case e.Success && e.RebootRequired:
+		return "成功，需要重启"
+	case e.Success:
+		return "成功"
+	case e.Code != "":
+		return "失败 " + string(e.Code)
+	default:
+		return "失败"
+	}
+}
+
+func writeHistory(w io.Writer, result *HistoryResult, format string) error {
+	switch format {
+	case "table":
+		t := &table.Table{
+			Header: []string{"时间", "操作", "名称", "版本", "结果", "退出码", "耗时", "残留"},
+			Align:  []table.Align{5: table.AlignRight, 6: table.AlignRight, 7: table.AlignRight},
+		}
+		if f, ok := w.(*os.File); ok {
+			t.MaxWidth = consoleWidth(f)
+		}
+		for i := range result.Entries {
+			e := &result.Entries[i]
+			duration := (time.Duration(e.DurationMs) * time.Millisecond).Round(time.Second)
+			t.Rows = append(t.Rows, []string{
+				e.Timestamp.Local().Format("2006-01-02 15:04"),
+				e.Action,
+				e.App.DisplayName,
+				e.App.DisplayVersion,
+				historyStatus(e),
+				strconv.Itoa(e.ExitCode),
+				duration.String(),
+				strings.Join(e.Leftovers, "; "),
+			})
+		}
+		return t.Render(w)
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
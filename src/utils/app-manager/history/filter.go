@@ -0,0 +1,91 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/query"
+)
+
+// 按结果过滤
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Filter 是查询条件，零值匹配所有记录
+type Filter struct {
+	Since  time.Time      // 不早于
+	Until  time.Time      // 早于
+	Action string         // uninstall、repair、modify 等
+	Name   *query.Pattern // 应用名称或发布者
+	Status string         // success 或 failed
+	Limit  int            // 最多返回的数量，0 表示不限制
+}
+
+// Match 判断记录是否满足条件
+func (f *Filter) Match(e *Entry) bool {
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.Action != "" && !strings.EqualFold(e.Action, f.Action) {
+		return false
+	}
+	if f.Name != nil && !f.Name.Match(e.App.DisplayName) && !f.Name.Match(e.App.Publisher) {
+		return false
+	}
+	switch f.Status {
+	case StatusSuccess:
+		return e.Success
+	case StatusFailed:
+		return !e.Success
+	}
+	return true
+}
+
+// Apply 返回满足条件的记录，最新的在前
+func (f *Filter) Apply(entries []Entry) []Entry {
+	result := []Entry{}
+	for i := range entries {
+		if f.Match(&entries[i]) {
+			result = append(result, entries[i])
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[:f.Limit]
+	}
+	return result
+}
+
+var relativeUnits = map[string]time.Duration{
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseTime 解析 --since 和 --until 的值：YYYY-MM-DD 等日期（本地时间），
+// 或相对于 now 的时长，如 24h、7d、2w
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if unit, ok := relativeUnits[strings.ToLower(s[len(s)-1:])]; ok {
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	}
+	t, err := query.ParseDate(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的时间 '%s'，应为 YYYY-MM-DD 或 24h、7d、2w 等", s)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location()), nil
+}
@@ -0,0 +1,126 @@
+// Package history 把卸载、修复等操作记录到只追加的本地日志（JSON Lines，每行一条），
+// 并按时间、操作、应用名称和结果查询。
+//
+//	err := history.Append(path, &history.Entry{Action: "uninstall", App: app, ...})
+//	entries, err := history.Read(path)
+//	recent := history.Filter{Since: time.Now().AddDate(0, 0, -7)}.Apply(entries)
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+// 卸载后的确认结果
+const (
+	VerifyRemoved        = "removed"         // ARP注册表项已被删除
+	VerifyStillInstalled = "still-installed" // ARP注册表项仍然存在
+)
+
+// Entry 是一次操作的记录
+type Entry struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+	// 操作前的应用信息
+	App inventory.App `json:"app"`
+	// 启动的安装程序命令行
+	Command string `json:"command,omitempty"`
+	// appman 的命令行参数
+	Invocation []string `json:"invocation,omitempty"`
+
+	Success        bool           `json:"success"`
+	Code           errcode.Code   `json:"code,omitempty"`
+	Error          string         `json:"error,omitempty"`
+	Params         errcode.Params `json:"params,omitempty"`
+	ExitCode       int            `json:"exitCode"`
+	RebootRequired bool           `json:"rebootRequired,omitempty"`
+	DurationMs     int64          `json:"durationMs"`
+	// 卸载前备份的ARP注册表项（.reg 文件）
+	Backup string `json:"backup,omitempty"`
+	// 卸载成功后的确认结果和仍然存在的注册表项、目录
+	Verification string   `json:"verification,omitempty"`
+	Leftovers    []string `json:"leftovers,omitempty"`
+}
+
+// NewID 返回以时间开头的记录ID，按字符串排序即为时间顺序
+func NewID(t time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return t.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// Append 在日志末尾追加一条记录，ID 和 Timestamp 为空时自动填写。
+// 每条记录一次写入，多个进程同时追加也不会交错
+func Append(path string, e *Entry) error {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	if e.ID == "" {
+		e.ID = NewID(e.Timestamp)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// 单条记录的最大长度，App 包含子项和原始注册表值时可能较长
+const maxEntrySize = 16 << 20
+
+// Read 读取所有记录，按写入顺序返回。日志不存在时返回空列表，无法解析的行（如写入中断留下的半行）被跳过
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), maxEntrySize)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			slog.Warn("跳过无法解析的历史记录", "path", path, "line", line, "error", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("无法读取历史记录: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,112 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/query"
+)
+
+func TestAppendRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appman", "history.jsonl")
+	if entries, err := Read(path); err != nil || len(entries) != 0 {
+		t.Fatalf("Read(missing) = %v, %v", entries, err)
+	}
+
+	first := &Entry{Action: "uninstall", App: inventory.App{DisplayName: "7-Zip"}, Success: true}
+	if err := Append(path, first); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == "" || first.Timestamp.IsZero() {
+		t.Errorf("Append should fill ID and Timestamp, got %+v", first)
+	}
+
+	// 写入中断留下的半行不影响后续记录
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"id":"broken","app":{"Disp` + "\n")
+	f.Close()
+	if err := Append(path, &Entry{Action: "repair", App: inventory.App{DisplayName: "Git"}, ExitCode: 1603}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != first.ID || entries[1].App.DisplayName != "Git" || entries[1].ExitCode != 1603 {
+		t.Errorf("Read = %+v", entries)
+	}
+}
+
+func TestNewID(t *testing.T) {
+	ts := time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)
+	a, b := NewID(ts), NewID(ts)
+	if !strings.HasPrefix(a, "20260315T103000Z-") || a == b {
+		t.Errorf("NewID = %q, %q", a, b)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	entries := []Entry{
+		{ID: "a", Timestamp: day(1), Action: "uninstall", App: inventory.App{DisplayName: "7-Zip", Publisher: "Igor Pavlov"}, Success: true},
+		{ID: "b", Timestamp: day(5), Action: "repair", App: inventory.App{DisplayName: "Git"}, Success: false},
+		{ID: "c", Timestamp: day(9), Action: "uninstall", App: inventory.App{DisplayName: "Google Chrome", Publisher: "Google LLC"}, Success: false},
+		{ID: "d", Timestamp: day(10), Action: "uninstall", App: inventory.App{DisplayName: "Notepad++"}, Success: true},
+	}
+	pattern := func(s string) *query.Pattern {
+		p, err := query.ParsePattern(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{"all newest first", Filter{}, "dcba"},
+		{"since", Filter{Since: day(5)}, "dcb"},
+		{"until", Filter{Until: day(5)}, "a"},
+		{"action", Filter{Action: "Uninstall"}, "dca"},
+		{"publisher", Filter{Name: pattern("google")}, "c"},
+		{"failed", Filter{Status: StatusFailed}, "cb"},
+		{"limit", Filter{Status: StatusSuccess, Limit: 1}, "d"},
+	}
+	for _, tt := range tests {
+		ids := ""
+		for _, e := range tt.filter.Apply(entries) {
+			ids += e.ID
+		}
+		if ids != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, ids, tt.want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"":           {},
+		"7d":         now.AddDate(0, 0, -7),
+		"24h":        now.Add(-24 * time.Hour),
+		"2W":         now.AddDate(0, 0, -14),
+		"2026-03-01": time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for s, want := range tests {
+		if got, err := ParseTime(s, now); err != nil || !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"yesterday", "-3d", "d"} {
+		if _, err := ParseTime(s, now); err == nil {
+			t.Errorf("ParseTime(%q) should fail", s)
+		}
+	}
+}
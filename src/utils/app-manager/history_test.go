@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/history"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+func TestHistoryFilter(t *testing.T) {
+	f, err := historyFilter("7d", "", "uninstall", "chrome", "failed", 5)
+	if err != nil || f.Since.IsZero() || f.Action != "uninstall" || f.Name == nil || f.Status != history.StatusFailed || f.Limit != 5 {
+		t.Errorf("historyFilter = %+v, %v", f, err)
+	}
+
+	for _, args := range [][]string{{"yesterday", ""}, {"", "x"}} {
+		if _, err := historyFilter(args[0], args[1], "", "", "", 0); err == nil {
+			t.Errorf("historyFilter(%q) should fail", args)
+		}
+	}
+	if _, err := historyFilter("", "", "", "", "ok", 0); err == nil {
+		t.Error("invalid --status should fail")
+	}
+}
+
+func TestWriteHistoryTable(t *testing.T) {
+	result := &HistoryResult{Success: true, Entries: []history.Entry{{
+		Action:     "uninstall",
+		App:        inventory.App{DisplayName: "7-Zip", DisplayVersion: "23.01"},
+		Success:    true,
+		DurationMs: 12400,
+		Leftovers:  []string{`C:\Program Files\7-Zip`},
+	}}}
+	var buf bytes.Buffer
+	if err := writeHistory(&buf, result, "table"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"7-Zip", "23.01", "成功", "12s", `C:\Program Files\7-Zip`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("table missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package icons
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+)
+
+var (
+	moduser32  = syscall.NewLazyDLL("user32.dll")
+	modshell32 = syscall.NewLazyDLL("shell32.dll")
+	modgdi32   = syscall.NewLazyDLL("gdi32.dll")
+
+	procExtractIconExW     = modshell32.NewProc("ExtractIconExW")
+	procDestroyIcon        = moduser32.NewProc("DestroyIcon")
+	procGetIconInfo        = moduser32.NewProc("GetIconInfo")
+	procGetDIBits          = modgdi32.NewProc("GetDIBits")
+	procGetObject          = modgdi32.NewProc("GetObjectW")
+	procCreateCompatibleDC = modgdi32.NewProc("CreateCompatibleDC")
+	procDeleteDC           = modgdi32.NewProc("DeleteDC")
+	procDeleteObject       = modgdi32.NewProc("DeleteObject")
+	procSelectObject       = modgdi32.NewProc("SelectObject")
+
+	procExtractIconW = modshell32.NewProc("ExtractIconW")
+)
+
+const (
+	DIB_RGB_COLORS = 0
+	BI_RGB         = 0
+)
+
+func alignTo4(n int32) int32 {
+	return (n + 3) & ^int32(3)
+}
+
+type BITMAP struct {
+	BmType       int32
+	BmWidth      int32
+	BmHeight     int32
+	BmWidthBytes int32
+	BmPlanes     uint16
+	BmBitsPixel  uint16
+	BmBits       uintptr
+}
+
+type ICONINFO struct {
+	FIcon    int32
+	XHotspot uint32
+	YHotspot uint32
+	HbmMask  syscall.Handle
+	HbmColor syscall.Handle
+}
+
+type BITMAPINFOHEADER struct {
+	BiSize          uint32
+	BiWidth         int32
+	BiHeight        int32
+	BiPlanes        uint16
+	BiBitCount      uint16
+	BiCompression   uint32
+	BiSizeImage     uint32
+	BiXPelsPerMeter int32
+	BiYPelsPerMeter int32
+	BiClrUsed       uint32
+	BiClrImportant  uint32
+}
+
+// Count 返回文件中图标的数量，文件不存在或不包含图标时为0
+func Count(exePath string) int {
+	exePathUTF16, err := syscall.UTF16PtrFromString(exePath)
+	if err != nil {
+		return 0
+	}
+
+	count, _, _ := procExtractIconW.Call(
+		0,
+		uintptr(unsafe.Pointer(exePathUTF16)),
+		0xFFFFFFFF,
+	)
+
+	return int(count)
+}
+
+// 把图标句柄渲染为带透明通道的图像
+func hiconToImage(hicon syscall.Handle) (image.Image, error) {
+	var iconInfo ICONINFO
+	ret, _, _ := procGetIconInfo.Call(
+		uintptr(hicon),
+		uintptr(unsafe.Pointer(&iconInfo)),
+	)
+	if ret == 0 {
+		return nil, errcode.New(errcode.IconRenderFailed, errcode.Params{"call": "GetIconInfo"})
+	}
+	defer procDeleteObject.Call(uintptr(iconInfo.HbmColor))
+	defer procDeleteObject.Call(uintptr(iconInfo.HbmMask))
+
+	var bm BITMAP
+	ret, _, _ = procGetObject.Call(
+		uintptr(iconInfo.HbmColor),
+		uintptr(unsafe.Sizeof(bm)),
+		uintptr(unsafe.Pointer(&bm)),
+	)
+	if ret == 0 {
+		return nil, errcode.New(errcode.IconRenderFailed, errcode.Params{"call": "GetObject"})
+	}
+
+	width := int(bm.BmWidth)
+	height := int(bm.BmHeight)
+
+	if width <= 0 || height <= 0 {
+		return nil, errcode.New(errcode.InvalidIconSize, nil)
+	}
+
+	hdc, _, _ := procCreateCompatibleDC.Call(0)
+	if hdc == 0 {
+		return nil, errcode.New(errcode.IconRenderFailed, errcode.Params{"call": "CreateCompatibleDC"})
+	}
+	defer procDeleteDC.Call(hdc)
+
+	oldBmp, _, _ := procSelectObject.Call(hdc, uintptr(iconInfo.HbmColor))
+	if oldBmp == 0 {
+		return nil, errcode.New(errcode.IconRenderFailed, errcode.Params{"call": "SelectObject"})
+	}
+	defer procSelectObject.Call(hdc, oldBmp)
+
+	stride := alignTo4(int32(width) * 4)
+
+	bi := BITMAPINFOHEADER{
+		BiSize:          uint32(unsafe.Sizeof(BITMAPINFOHEADER{})),
+		BiWidth:         int32(width),
+		BiHeight:        int32(-height),
+		BiPlanes:        1,
+		BiBitCount:      32,
+		BiCompression:   BI_RGB,
+		BiSizeImage:     uint32(int(stride) * height),
+		BiXPelsPerMeter: 0,
+		BiYPelsPerMeter: 0,
+		BiClrUsed:       0,
+		BiClrImportant:  0,
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	ret, _, _ = procGetDIBits.Call(
+		uintptr(hdc),
+		uintptr(iconInfo.HbmColor),
+		0,
+		uintptr(height),
+		uintptr(unsafe.Pointer(&img.Pix[0])),
+		uintptr(unsafe.Pointer(&bi)),
+		DIB_RGB_COLORS,
+	)
+	if ret == 0 {
+		return nil, errcode.New(errcode.IconRenderFailed, errcode.Params{"call": "GetDIBits"})
+	}
+
+	// 转换BGRA到RGBA并初始化Alpha通道
+	hasAlphaChannel := false
+	for i := 0; i < len(img.Pix); i += 4 {
+		// Convert BGRA to RGBA
+		b := img.Pix[i]
+		img.Pix[i] = img.Pix[i+2]
+		img.Pix[i+2] = b
+
+		// Check if the image has a valid alpha channel
+		if img.Pix[i+3] > 0 {
+			hasAlphaChannel = true
+		}
+	}
+
+	// 处理掩码
+	if iconInfo.HbmMask != 0 {
+		oldMaskBmp, _, _ := procSelectObject.Call(hdc, uintptr(iconInfo.HbmMask))
+		if oldMaskBmp != 0 {
+			defer procSelectObject.Call(hdc, oldMaskBmp)
+
+			maskRowSize := alignTo4((int32(width) + 7) / 8)
+			maskData := make([]byte, int(maskRowSize)*height)
+
+			biMask := bi
+			biMask.BiBitCount = 1
+			biMask.BiSizeImage = uint32(len(maskData))
+
+			ret, _, _ = procGetDIBits.Call(
+				uintptr(hdc),
+				uintptr(iconInfo.HbmMask),
+				0,
+				uintptr(height),
+				uintptr(unsafe.Pointer(&maskData[0])),
+				uintptr(unsafe.Pointer(&biMask)),
+				DIB_RGB_COLORS,
+			)
+
+			if ret != 0 {
+				// If the image doesn't have an alpha channel, use the mask to determine transparency
+				if !hasAlphaChannel {
+					for y := 0; y < height; y++ {
+						for x := 0; x < width; x++ {
+							byteIndex := y*int(maskRowSize) + x/8
+							bitMask := byte(0x80 >> uint(x%8))
+							imgIndex := y*img.Stride + x*4
+
+							if maskData[byteIndex]&bitMask != 0 {
+								// If mask bit is 1, the pixel should be transparent
+								img.Pix[imgIndex+3] = 0
+							} else {
+								// If mask bit is 0, the pixel should be opaque
+								img.Pix[imgIndex+3] = 255
+							}
+						}
+					}
+				} else {
+					// If the image has an alpha channel, only use mask for pixels with alpha = 0
+					for y := 0; y < height; y++ {
+						for x := 0; x < width; x++ {
+							byteIndex := y*int(maskRowSize) + x/8
+							bitMask := byte(0x80 >> uint(x%8))
+							imgIndex := y*img.Stride + x*4
+
+							if img.Pix[imgIndex+3] == 0 {
+								if maskData[byteIndex]&bitMask != 0 {
+									// Keep it transparent
+									img.Pix[imgIndex+3] = 0
+								} else {
+									// Make it opaque
+									img.Pix[imgIndex+3] = 255
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	} else if !hasAlphaChannel {
+		// If there's no mask and no alpha channel, make non-black pixels opaque
+		for i := 0; i < len(img.Pix); i += 4 {
+			if img.Pix[i] > 0 || img.Pix[i+1] > 0 || img.Pix[i+2] > 0 {
+				img.Pix[i+3] = 255
+			} else {
+				img.Pix[i+3] = 0
+			}
+		}
+	}
+
+	return img, nil
+}
+
+type extractOptions struct {
+	outputDir string
+}
+
+// Option 是 SavePNG 的选项
+type Option func(*extractOptions)
+
+// WithOutputDir 设置保存PNG文件的目录，默认为当前目录下的 icons
+func WithOutputDir(dir string) Option {
+	return func(o *extractOptions) {
+		o.outputDir = dir
+	}
+}
+
+// Extract 使用系统API读取文件中第 index 个图标的大尺寸图像，错误代码见 errcode 包
+func Extract(exePath string, index int) (image.Image, error) {
+	if _, err := os.Stat(exePath); os.IsNotExist(err) {
+		return nil, errcode.New(errcode.FileNotFound, errcode.Params{"path": exePath})
+	}
+
+	iconCount := Count(exePath)
+	if iconCount <= 0 {
+		return nil, errcode.New(errcode.NoIcons, errcode.Params{"path": exePath})
+	}
+
+	if index < 0 || index >= iconCount {
+		return nil, errcode.New(errcode.InvalidIconIndex, errcode.Params{
+			"index": strconv.Itoa(index),
+			"count": strconv.Itoa(iconCount),
+			"max":   strconv.Itoa(iconCount - 1),
+		})
+	}
+
+	exePathUTF16, err := syscall.UTF16PtrFromString(exePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var largeIcon syscall.Handle
+	ret, _, _ := procExtractIconExW.Call(
+		uintptr(unsafe.Pointer(exePathUTF16)),
+		uintptr(index),
+		uintptr(unsafe.Pointer(&largeIcon)),
+		0,
+		1,
+	)
+
+	if ret == 0 || largeIcon == 0 {
+		return nil, errcode.New(errcode.IconExtractFailed, errcode.Params{"index": strconv.Itoa(index)})
+	}
+	defer procDestroyIcon.Call(uintptr(largeIcon))
+
+	return hiconToImage(largeIcon)
+}
+
+// SavePNG 提取图标并保存为 <文件名>_icon_<index>.png，返回PNG文件的绝对路径
+func SavePNG(exePath string, index int, opts ...Option) (string, error) {
+	o := &extractOptions{outputDir: "icons"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	img, err := Extract(exePath, index)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(o.outputDir, 0755); err != nil {
+		return "", err
+	}
+
+	baseName := filepath.Base(exePath)
+	outputPath := filepath.Join(o.outputDir, fmt.Sprintf("%s_icon_%d.png", baseName, index))
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return "", err
+	}
+
+	absPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return outputPath, nil
+	}
+	return absPath, nil
+}
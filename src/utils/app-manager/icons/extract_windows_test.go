@@ -1,4 +1,4 @@
-package main
+package icons
 
 import (
 	"image/png"
@@ -15,7 +15,7 @@ func TestExtractVCRedistIcon(t *testing.T) {
 		t.Skip("Test file not found:", exePath)
 	}
 
-	outputPath, err := extractIcon(exePath, index)
+	outputPath, err := SavePNG(exePath, index)
 	if err != nil {
 		t.Fatal("Failed to extract icon:", err)
 	}
@@ -32,7 +32,7 @@ func TestExtractVCRedistIcon(t *testing.T) {
 }
 
 func TestIconCountFunction(t *testing.T) {
-	// This test checks if getIconCount returns a positive number for files known to have icons
+	// This test checks if Count returns a positive number for files known to have icons
 	exePath := `C:\Windows\System32\shell32.dll`
 
 	// Skip if file doesn't exist on test system
@@ -40,7 +40,7 @@ func TestIconCountFunction(t *testing.T) {
 		t.Skip("Test file not found:", exePath)
 	}
 
-	count := getIconCount(exePath)
+	count := Count(exePath)
 	if count <= 0 {
 		t.Errorf("Expected positive icon count for shell32.dll, got %d", count)
 	}
@@ -55,9 +55,9 @@ func TestExtractingInvalidIcon(t *testing.T) {
 	}
 
 	// Try to extract an invalid index
-	count := getIconCount(exePath)
+	count := Count(exePath)
 	if count > 0 {
-		_, err := extractIcon(exePath, count+10) // Use an index that's guaranteed to be invalid
+		_, err := SavePNG(exePath, count+10) // Use an index that's guaranteed to be invalid
 		if err == nil {
 			t.Error("Expected error when extracting invalid icon index, but got nil")
 		}
@@ -72,7 +72,7 @@ func TestOutputFileHasValidContent(t *testing.T) {
 		t.Skip("Test file not found:", exePath)
 	}
 
-	outputPath, err := extractIcon(exePath, 0)
+	outputPath, err := SavePNG(exePath, 0)
 	if err != nil {
 		t.Fatal("Failed to extract icon:", err)
 	}
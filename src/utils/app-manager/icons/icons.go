@@ -0,0 +1,58 @@
+// Package icons 读取应用图标。
+//
+// ICO 直接解析 .ico 文件和PE文件中的图标资源，不依赖系统API，
+// 适合在报告中内嵌图标；Extract 和 SavePNG 使用系统API渲染图标，仅支持Windows。
+package icons
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/peres"
+)
+
+// ParseLocation 解析DisplayIcon等图标位置，格式为 "path" 或 "path,index"。
+// 路径中的环境变量不展开
+func ParseLocation(s string) (string, int) {
+	s = strings.TrimSpace(s)
+	index := 0
+	if idx := strings.LastIndex(s, ","); idx > 0 {
+		if n, err := strconv.Atoi(strings.TrimSpace(s[idx+1:])); err == nil {
+			index = n
+			s = s[:idx]
+		}
+	}
+
+	return strings.Trim(strings.TrimSpace(s), `"`), index
+}
+
+// ICO 读取 .ico 文件或 .exe、.dll 中第 index 个图标，返回只包含最接近 size 像素的
+// 单个图像的ICO数据。index 为负数时按资源ID查找，与Windows的约定相同
+func ICO(path string, index, size int) ([]byte, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".ico":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return peres.PickIcon(data, size)
+	case ".exe", ".dll":
+		f, err := peres.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return f.Icon(index, size)
+	default:
+		return nil, fmt.Errorf("不支持的图标文件类型 '%s'", ext)
+	}
+}
+
+// DataURI 把ICO数据转换为可以在HTML中使用的 data URI
+func DataURI(ico []byte) string {
+	return "data:image/x-icon;base64," + base64.StdEncoding.EncodeToString(ico)
+}
@@ -0,0 +1,105 @@
+// Package inventory 读取Windows“程序和功能”中已安装的应用。
+//
+//	apps, err := inventory.Scan()
+//
+// Scan 读取 HKLM 和 HKCU 下（包括 Wow6432Node）的卸载注册表项，同名应用只保留版本最高的，
+// 更新、补丁和子组件放在父产品的 Children 中。App 可以直接序列化为JSON，
+// 字段名与注册表值名称一致。
+package inventory
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/arp"
+)
+
+// App 是一个已安装的应用
+type App struct {
+	DisplayName     string `json:"DisplayName"`
+	DisplayVersion  string `json:"DisplayVersion"`
+	Publisher       string `json:"Publisher"`
+	InstallDate     string `json:"InstallDate"`
+	UninstallString string `json:"UninstallString"`
+	InstallLocation string `json:"InstallLocation"`
+	DisplayIcon     string `json:"DisplayIcon"`
+	RegistryKey     string `json:"RegistryKey"`
+	EstimatedSize   uint32 `json:"EstimatedSize"`
+	Scope           string `json:"Scope"`        // machine 或 user
+	Architecture    string `json:"Architecture"` // x86、x64 或 arm64
+	// 以下字段由上面的原始值计算得到
+	InstallDateISO    string `json:"InstallDateISO"`              // YYYY-MM-DD，无法确定时为空
+	InstallDateSource string `json:"InstallDateSource,omitempty"` // registry、keyLastWrite 或 installLocation
+	SizeBytes         int64  `json:"SizeBytes"`                   // 统计过安装目录时为实际占用，否则为 EstimatedSize
+	SizeHuman         string `json:"SizeHuman"`
+	SizeSource        string `json:"SizeSource,omitempty"` // estimated 或 measured
+	// MeasureSizes 统计的安装目录实际占用空间
+	MeasuredSizeBytes int64  `json:"MeasuredSizeBytes,omitempty"`
+	MeasuredSizeHuman string `json:"MeasuredSizeHuman,omitempty"`
+	// 更新、补丁和子组件指向其父产品，见 hierarchy 包
+	ParentKeyName     string `json:"ParentKeyName,omitempty"`
+	ParentDisplayName string `json:"ParentDisplayName,omitempty"`
+	ReleaseType       string `json:"ReleaseType,omitempty"` // 如 Update、Hotfix、Security Update
+	IsMinorUpgrade    bool   `json:"IsMinorUpgrade,omitempty"`
+	Children          []App  `json:"Children,omitempty"`
+	// 其他标准的卸载注册表值
+	QuietUninstallString string `json:"QuietUninstallString,omitempty"`
+	ModifyPath           string `json:"ModifyPath,omitempty"`
+	NoModify             bool   `json:"NoModify,omitempty"`
+	NoRepair             bool   `json:"NoRepair,omitempty"`
+	NoRemove             bool   `json:"NoRemove,omitempty"`
+	URLInfoAbout         string `json:"URLInfoAbout,omitempty"`
+	URLUpdateInfo        string `json:"URLUpdateInfo,omitempty"`
+	HelpLink             string `json:"HelpLink,omitempty"`
+	HelpTelephone        string `json:"HelpTelephone,omitempty"`
+	Comments             string `json:"Comments,omitempty"`
+	Contact              string `json:"Contact,omitempty"`
+	Readme               string `json:"Readme,omitempty"`
+	Language             uint32 `json:"Language,omitempty"` // LCID，如 2052 表示简体中文
+	VersionMajor         uint32 `json:"VersionMajor,omitempty"`
+	VersionMinor         uint32 `json:"VersionMinor,omitempty"`
+	ProductID            string `json:"ProductID,omitempty"`
+	InstallSource        string `json:"InstallSource,omitempty"`
+	WindowsInstaller     bool   `json:"WindowsInstaller,omitempty"`
+	UpgradeCode          string `json:"UpgradeCode,omitempty"` // MSI 产品所属的升级代码
+	// 没有对应字段的其他值，键为值名称，默认值为 @
+	Raw map[string]arp.Value `json:"raw,omitempty"`
+}
+
+// InstallDir 返回清理了引号和结尾分隔符的 InstallLocation，为空或为盘符根目录时返回空字符串
+func (app *App) InstallDir() string {
+	loc := filepath.Clean(strings.Trim(strings.TrimSpace(app.InstallLocation), `"`))
+	if loc == "." || filepath.Dir(loc) == loc {
+		return ""
+	}
+	return loc
+}
+
+// ProductCode 返回ARP注册表子键名，MSI安装的应用即为 {GUID} 形式的产品代码
+func (app *App) ProductCode() string {
+	return app.RegistryKey[strings.LastIndex(app.RegistryKey, `\`)+1:]
+}
+
+var productCodePattern = regexp.MustCompile(`^\{[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}\}$`)
+
+// MSIProductCode 在应用由Windows Installer安装且注册表子键为产品代码时返回产品代码，否则返回空字符串
+func (app *App) MSIProductCode() string {
+	if code := app.ProductCode(); app.WindowsInstaller && productCodePattern.MatchString(code) {
+		return code
+	}
+	return ""
+}
+
+// RequiresParent 判断应用是否为需要随父产品卸载的子组件或补丁
+func (app *App) RequiresParent() bool {
+	return app.ParentKeyName != "" || app.ParentDisplayName != ""
+}
+
+// ParentName 返回父产品的名称，没有 ParentDisplayName 时为 ParentKeyName
+func (app *App) ParentName() string {
+	if app.ParentDisplayName != "" {
+		return app.ParentDisplayName
+	}
+	return app.ParentKeyName
+}
@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/arp"
+)
+
+// 读取卸载注册表项中的其他标准值，没有对应字段的值放入 Raw
+func readARPValues(app *App, key registry.Key, upgradeCodes map[string]string) {
+	str := func(name string) string {
+		value, _, _ := key.GetStringValue(name)
+		return value
+	}
+	dword := func(name string) uint32 {
+		value, _, _ := key.GetIntegerValue(name)
+		return uint32(value)
+	}
+
+	app.QuietUninstallString = str("QuietUninstallString")
+	app.ModifyPath = str("ModifyPath")
+	app.NoModify = dword("NoModify") == 1
+	app.NoRepair = dword("NoRepair") == 1
+	app.NoRemove = dword("NoRemove") == 1
+	app.URLInfoAbout = str("URLInfoAbout")
+	app.URLUpdateInfo = str("URLUpdateInfo")
+	app.HelpLink = str("HelpLink")
+	app.HelpTelephone = str("HelpTelephone")
+	app.Comments = str("Comments")
+	app.Contact = str("Contact")
+	app.Readme = str("Readme")
+	app.Language = dword("Language")
+	app.VersionMajor = dword("VersionMajor")
+	app.VersionMinor = dword("VersionMinor")
+	app.ProductID = str("ProductID")
+	app.InstallSource = str("InstallSource")
+	app.WindowsInstaller = dword("WindowsInstaller") == 1
+	if app.WindowsInstaller {
+		app.UpgradeCode = upgradeCodes[strings.ToUpper(app.ProductCode())]
+	}
+	app.Raw = readRawValues(key)
+}
+
+// 读取没有对应字段的值，保留其注册表类型
+func readRawValues(key registry.Key) map[string]arp.Value {
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return nil
+	}
+
+	var raw map[string]arp.Value
+	for _, name := range names {
+		if arp.IsField(name) {
+			continue
+		}
+		n, valType, err := key.GetValue(name, nil)
+		if err != nil {
+			continue
+		}
+		data := make([]byte, n)
+		if n, valType, err = key.GetValue(name, data); err != nil {
+			continue
+		}
+
+		if raw == nil {
+			raw = make(map[string]arp.Value)
+		}
+		if name == "" {
+			name = "@"
+		}
+		raw[name] = arp.Decode(valType, data[:n])
+	}
+	return raw
+}
@@ -0,0 +1,88 @@
+package inventory
+
+import (
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/hierarchy"
+)
+
+func hierarchyEntry(app *App) hierarchy.Entry {
+	group, keyName := "", app.RegistryKey
+	if i := strings.LastIndex(app.RegistryKey, `\`); i >= 0 {
+		group, keyName = app.RegistryKey[:i], app.RegistryKey[i+1:]
+	}
+	return hierarchy.Entry{
+		Group:             group,
+		KeyName:           keyName,
+		DisplayName:       app.DisplayName,
+		ParentKeyName:     app.ParentKeyName,
+		ParentDisplayName: app.ParentDisplayName,
+		ReleaseType:       app.ReleaseType,
+		IsMinorUpgrade:    app.IsMinorUpgrade,
+	}
+}
+
+// IsChild 判断应用是否为更新、补丁或子组件
+func (app *App) IsChild() bool {
+	e := hierarchyEntry(app)
+	return e.IsChild()
+}
+
+// Nest 把更新、补丁和子组件放到父产品的 Children 中，返回顶层应用。
+// 找不到父产品的子项保留在顶层，但没有卸载命令的会被忽略
+func Nest(apps []App) []App {
+	entries := make([]hierarchy.Entry, len(apps))
+	for i := range apps {
+		entries[i] = hierarchyEntry(&apps[i])
+	}
+	parents := hierarchy.Parents(entries)
+
+	children := make([][]int, len(apps))
+	for i, p := range parents {
+		if p >= 0 {
+			children[p] = append(children[p], i)
+		}
+	}
+
+	var build func(i int) App
+	build = func(i int) App {
+		app := apps[i]
+		app.Children = nil
+		for _, c := range children[i] {
+			app.Children = append(app.Children, build(c))
+		}
+		return app
+	}
+
+	result := []App{}
+	for i, p := range parents {
+		if p >= 0 || (apps[i].UninstallString == "" && apps[i].RequiresParent()) {
+			continue
+		}
+		result = append(result, build(i))
+	}
+	return result
+}
+
+// HideChildren 去掉顶层中找不到父产品的更新、补丁和子组件，用于不带 --all 的默认列表
+func HideChildren(apps []App) []App {
+	result := []App{}
+	for i := range apps {
+		if !apps[i].IsChild() {
+			result = append(result, apps[i])
+		}
+	}
+	return result
+}
+
+// Flatten 展开所有子项，子项紧跟在父产品之后，返回的应用中 Children 为空
+func Flatten(apps []App) []App {
+	result := []App{}
+	for _, app := range apps {
+		children := app.Children
+		app.Children = nil
+		result = append(result, app)
+		result = append(result, Flatten(children)...)
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNest(t *testing.T) {
+	const uninstall = `HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\`
+	apps := []App{
+		{DisplayName: "Microsoft Office", RegistryKey: uninstall + "Office16", UninstallString: "setup.exe"},
+		{DisplayName: "Office Language Pack", RegistryKey: uninstall + "LP", ParentDisplayName: "Microsoft Office"},
+		{DisplayName: "Update (KB1)", RegistryKey: uninstall + "KB1", ParentKeyName: "Office16", ReleaseType: "Update", UninstallString: "msiexec /x"},
+		{DisplayName: "Orphan Patch", RegistryKey: uninstall + "KB2", ParentKeyName: "Missing"},
+		{DisplayName: "Orphan Update", RegistryKey: uninstall + "KB3", ParentKeyName: "Missing", UninstallString: "remove.exe"},
+		{DisplayName: "7-Zip", RegistryKey: uninstall + "7-Zip", UninstallString: "uninstall.exe"},
+	}
+
+	top := Nest(apps)
+	var names []string
+	for _, app := range top {
+		names = append(names, app.DisplayName)
+	}
+	if got := strings.Join(names, ","); got != "Microsoft Office,Orphan Update,7-Zip" {
+		t.Fatalf("top-level = %s", got)
+	}
+	if len(top[0].Children) != 2 || top[0].Children[1].DisplayName != "Update (KB1)" {
+		t.Errorf("children = %+v", top[0].Children)
+	}
+
+	flat := Flatten(top)
+	if len(flat) != 5 || flat[1].DisplayName != "Office Language Pack" || flat[0].Children != nil {
+		t.Errorf("Flatten = %+v", flat)
+	}
+	if top[0].Children == nil {
+		t.Error("Flatten modified its input")
+	}
+}
+
+func TestHideChildren(t *testing.T) {
+	const uninstall = `HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\`
+	apps := []App{
+		{DisplayName: "Contoso App", RegistryKey: uninstall + "App", UninstallString: "uninstall.exe"},
+		{DisplayName: "Hotfix for Contoso App (KB1)", RegistryKey: uninstall + "KB1", ReleaseType: "Hotfix", UninstallString: "remove.exe"},
+		{DisplayName: "Hotfix (KB2)", RegistryKey: uninstall + "KB2", ReleaseType: "Hotfix", UninstallString: "remove.exe"},
+		{DisplayName: "Minor Upgrade", RegistryKey: uninstall + "Minor", IsMinorUpgrade: true, UninstallString: "msiexec /x"},
+	}
+
+	top := Nest(apps)
+	if len(top[0].Children) != 1 || top[0].Children[0].DisplayName != "Hotfix for Contoso App (KB1)" {
+		t.Errorf("children = %+v", top[0].Children)
+	}
+
+	// 默认列表中不显示任何更新，--all 时全部显示
+	visible := HideChildren(top)
+	if len(visible) != 1 || visible[0].DisplayName != "Contoso App" {
+		t.Errorf("HideChildren = %+v", visible)
+	}
+	if flat := Flatten(top); len(flat) != len(apps) {
+		t.Errorf("Flatten = %+v", flat)
+	}
+}
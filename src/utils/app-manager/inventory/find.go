@@ -0,0 +1,70 @@
+package inventory
+
+import "strings"
+
+// FindByKey 按完整的注册表项路径查找应用
+func FindByKey(apps []App, key string) *App {
+	for i := range apps {
+		if apps[i].RegistryKey == key {
+			return &apps[i]
+		}
+	}
+	return nil
+}
+
+// FindByName 按名称查找应用，不区分大小写
+func FindByName(apps []App, name string) *App {
+	lowerName := strings.ToLower(name)
+	for i := range apps {
+		if strings.ToLower(apps[i].DisplayName) == lowerName {
+			return &apps[i]
+		}
+	}
+	return nil
+}
+
+// FindMatching 返回名称包含指定字符串的所有应用，不区分大小写
+func FindMatching(apps []App, name string) []App {
+	var matches []App
+	lowerName := strings.ToLower(name)
+
+	for _, app := range apps {
+		if strings.Contains(strings.ToLower(app.DisplayName), lowerName) {
+			matches = append(matches, app)
+		}
+	}
+
+	return matches
+}
+
+// Find 按注册表项、产品代码或名称查找应用。名称为子串匹配，完全相同的名称优先
+func Find(apps []App, id string) []App {
+	if app := FindByKey(apps, id); app != nil {
+		return []App{*app}
+	}
+	for _, app := range apps {
+		if strings.EqualFold(app.ProductCode(), id) {
+			return []App{app}
+		}
+	}
+	if app := FindByName(apps, id); app != nil {
+		return []App{*app}
+	}
+	return FindMatching(apps, id)
+}
+
+// FindStrict 供卸载等不可撤销的操作使用：只接受完整的注册表项路径或 {GUID} 形式的产品代码，
+// 其他情况按名称子串匹配，即使有完全相同的名称，也返回所有包含该字符串的应用由调用者处理歧义
+func FindStrict(apps []App, id string) []App {
+	if app := FindByKey(apps, id); app != nil {
+		return []App{*app}
+	}
+	if productCodePattern.MatchString(id) {
+		for _, app := range apps {
+			if strings.EqualFold(app.ProductCode(), id) {
+				return []App{app}
+			}
+		}
+	}
+	return FindMatching(apps, id)
+}
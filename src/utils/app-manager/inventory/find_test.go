@@ -0,0 +1,78 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	apps := []App{
+		{DisplayName: "Git", RegistryKey: `HKLM\X\Git_is1`},
+		{DisplayName: "GitHub Desktop", RegistryKey: `HKCU\X\GitHubDesktop`},
+		{DisplayName: "7-Zip", RegistryKey: `HKLM\X\{23170F69-40C1-2702-2301-000001000000}`},
+	}
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{`HKCU\X\GitHubDesktop`, []string{"GitHub Desktop"}},
+		{"{23170f69-40c1-2702-2301-000001000000}", []string{"7-Zip"}},
+		{"git", []string{"Git"}},
+		{"gi", []string{"Git", "GitHub Desktop"}},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, app := range Find(apps, tt.id) {
+			got = append(got, app.DisplayName)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Find(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestFindStrict(t *testing.T) {
+	apps := []App{
+		{DisplayName: "Git", RegistryKey: `HKLM\X\Git_is1`},
+		{DisplayName: "GitHub Desktop", RegistryKey: `HKCU\X\GitHubDesktop`},
+		{DisplayName: "7-Zip", RegistryKey: `HKLM\X\{23170F69-40C1-2702-2301-000001000000}`},
+	}
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{`HKCU\X\GitHubDesktop`, []string{"GitHub Desktop"}},
+		{"{23170f69-40c1-2702-2301-000001000000}", []string{"7-Zip"}},
+		// 名称完全相同时，其他包含该名称的应用也要列出
+		{"git", []string{"Git", "GitHub Desktop"}},
+		// 没有花括号的子键名不当作产品代码
+		{"Git_is1", nil},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, app := range FindStrict(apps, tt.id) {
+			got = append(got, app.DisplayName)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("FindStrict(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestMSIProductCode(t *testing.T) {
+	tests := []struct {
+		app  App
+		want string
+	}{
+		{App{WindowsInstaller: true, RegistryKey: `HKLM\X\{23170F69-40C1-2702-2301-000001000000}`}, "{23170F69-40C1-2702-2301-000001000000}"},
+		{App{WindowsInstaller: false, RegistryKey: `HKLM\X\{23170F69-40C1-2702-2301-000001000000}`}, ""},
+		{App{WindowsInstaller: true, RegistryKey: `HKLM\X\7-Zip`}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.app.MSIProductCode(); got != tt.want {
+			t.Errorf("MSIProductCode(%s) = %q, want %q", tt.app.RegistryKey, got, tt.want)
+		}
+	}
+}
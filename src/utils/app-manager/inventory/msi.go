@@ -0,0 +1,28 @@
+package inventory
+
+import "strings"
+
+// Windows Installer在注册表中保存GUID时使用的压缩格式，每一段内的字符顺序被反转：
+// {12345678-ABCD-EF01-2345-6789ABCDEF01} -> 87654321DCBA10FE32547698BADCFE10
+var packedGUIDGroups = []int{8, 4, 4, 2, 2, 2, 2, 2, 2, 2, 2}
+
+// 将压缩格式的GUID还原为 {XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX}
+func unpackGUID(packed string) string {
+	if len(packed) != 32 {
+		return ""
+	}
+
+	var parts []string
+	pos := 0
+	for _, n := range packedGUIDGroups {
+		group := []byte(packed[pos : pos+n])
+		for i, j := 0, len(group)-1; i < j; i, j = i+1, j-1 {
+			group[i], group[j] = group[j], group[i]
+		}
+		parts = append(parts, string(group))
+		pos += n
+	}
+
+	tail := strings.Join(parts[3:], "")
+	return strings.ToUpper("{" + parts[0] + "-" + parts[1] + "-" + parts[2] + "-" + tail[:4] + "-" + tail[4:] + "}")
+}
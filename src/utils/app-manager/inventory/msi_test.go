@@ -0,0 +1,17 @@
+package inventory
+
+import "testing"
+
+func TestUnpackGUID(t *testing.T) {
+	tests := map[string]string{
+		"87654321DCBA10FE32547698BADCFE10": "{12345678-ABCD-EF01-2345-6789ABCDEF01}",
+		"96F071321C0420723210000010000000": "{23170F69-40C1-2702-2301-000001000000}",
+		"TOO_SHORT":                        "",
+	}
+
+	for packed, want := range tests {
+		if got := unpackGUID(packed); got != want {
+			t.Errorf("unpackGUID(%q) = %q, want %q", packed, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// UpgradeCodes 读取所有MSI产品的升级代码，返回 产品代码 -> 升级代码
+func UpgradeCodes() map[string]string {
+	upgradeCodes := make(map[string]string)
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Classes\Installer\UpgradeCodes`, registry.READ)
+	if err != nil {
+		return upgradeCodes
+	}
+	defer key.Close()
+
+	packedUpgradeCodes, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return upgradeCodes
+	}
+
+	for _, packedUpgradeCode := range packedUpgradeCodes {
+		subKey, err := registry.OpenKey(key, packedUpgradeCode, registry.READ)
+		if err != nil {
+			continue
+		}
+		// 值名称为属于该升级代码的产品代码
+		packedProductCodes, _ := subKey.ReadValueNames(-1)
+		subKey.Close()
+
+		upgradeCode := unpackGUID(packedUpgradeCode)
+		for _, packedProductCode := range packedProductCodes {
+			if productCode := unpackGUID(packedProductCode); productCode != "" && upgradeCode != "" {
+				upgradeCodes[productCode] = upgradeCode
+			}
+		}
+	}
+
+	return upgradeCodes
+}
@@ -0,0 +1,68 @@
+package inventory
+
+import (
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/mui"
+)
+
+var (
+	procLocaleNameToLCID     = windows.NewLazySystemDLL("kernel32.dll").NewProc("LocaleNameToLCID")
+	procSHLoadIndirectString = windows.NewLazySystemDLL("shlwapi.dll").NewProc("SHLoadIndirectString")
+)
+
+// UILanguages 返回用户的首选界面语言，按优先顺序排列
+func UILanguages() []mui.Language {
+	names, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME)
+	if err != nil {
+		return nil
+	}
+
+	var langs []mui.Language
+	for _, name := range names {
+		namePtr, err := windows.UTF16PtrFromString(name)
+		if err != nil {
+			continue
+		}
+		lcid, _, _ := procLocaleNameToLCID.Call(uintptr(unsafe.Pointer(namePtr)), 0)
+		langs = append(langs, mui.Language{Name: name, ID: uint32(lcid)})
+	}
+	return langs
+}
+
+// 包资源引用需要读取应用包的 resources.pri，交给系统解析
+func loadIndirectString(s string) (string, error) {
+	if err := procSHLoadIndirectString.Find(); err != nil {
+		return "", err
+	}
+	src, err := windows.UTF16PtrFromString(s)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]uint16, 1024)
+	hr, _, _ := procSHLoadIndirectString.Call(uintptr(unsafe.Pointer(src)), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0)
+	if hr != 0 {
+		return "", windows.Errno(hr)
+	}
+	return windows.UTF16ToString(buf), nil
+}
+
+// SystemResolver 返回按用户界面语言解析间接字符串的解析器，可以用于 WithResolver
+func SystemResolver() *mui.Resolver {
+	r := &mui.Resolver{
+		Languages: UILanguages(),
+		Expand:    registry.ExpandString,
+		Fallback:  loadIndirectString,
+	}
+	if dir, err := windows.GetSystemDirectory(); err == nil {
+		r.SearchDirs = []string{dir}
+	}
+	return r
+}
+
+// 解析器缓存读取过的文件，多次扫描共用
+var systemStrings = sync.OnceValue(SystemResolver)
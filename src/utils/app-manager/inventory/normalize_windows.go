@@ -0,0 +1,62 @@
+package inventory
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/normalize"
+)
+
+// 安装日期的来源
+const (
+	DateSourceRegistry        = "registry"
+	DateSourceKeyLastWrite    = "keyLastWrite"
+	DateSourceInstallLocation = "installLocation"
+)
+
+// 根据原始注册表值计算标准化字段。InstallDate 缺失或无法解析时，
+// 依次使用卸载注册表项的最后写入时间和安装目录的创建时间
+func normalizeApp(app *App, key registry.Key) {
+	if date, ok := normalize.InstallDate(app.InstallDate); ok {
+		app.InstallDateISO = date.Format(normalize.ISODate)
+		app.InstallDateSource = DateSourceRegistry
+	} else if date, ok := keyLastWrite(key); ok {
+		app.InstallDateISO = date.Format(normalize.ISODate)
+		app.InstallDateSource = DateSourceKeyLastWrite
+	} else if date, ok := creationTime(app.InstallLocation); ok {
+		app.InstallDateISO = date.Format(normalize.ISODate)
+		app.InstallDateSource = DateSourceInstallLocation
+	}
+
+	app.SizeBytes = normalize.SizeBytes(app.EstimatedSize)
+	app.SizeHuman = normalize.HumanSize(app.SizeBytes)
+	if app.SizeBytes > 0 {
+		app.SizeSource = SizeSourceEstimated
+	}
+}
+
+func keyLastWrite(key registry.Key) (time.Time, bool) {
+	info, err := key.Stat()
+	if err != nil || info.ModTime().IsZero() {
+		return time.Time{}, false
+	}
+	return info.ModTime().Local(), true
+}
+
+func creationTime(path string) (time.Time, bool) {
+	if path == "" {
+		return time.Time{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	attr, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, attr.CreationTime.Nanoseconds()).Local(), true
+}
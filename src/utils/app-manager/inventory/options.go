@@ -0,0 +1,39 @@
+package inventory
+
+import "github.com/jasoft/YourUninstaler/src/utils/app-manager/mui"
+
+type scanOptions struct {
+	strings     *mui.Resolver
+	useSystem   bool
+	measureSize bool
+	measureOpts []MeasureOption
+}
+
+// Option 是 Scan 的选项
+type Option func(*scanOptions)
+
+// WithResolver 使用指定的解析器解析 "@file,-id" 形式的 DisplayName 和 Publisher，
+// 默认按用户的界面语言从系统资源中读取。r 为 nil 时保留原始字符串
+func WithResolver(r *mui.Resolver) Option {
+	return func(o *scanOptions) {
+		o.strings = r
+		o.useSystem = false
+	}
+}
+
+// WithMeasuredSizes 在扫描后统计各应用安装目录的实际占用空间，见 MeasureSizes
+func WithMeasuredSizes(opts ...MeasureOption) Option {
+	return func(o *scanOptions) {
+		o.measureSize = true
+		o.measureOpts = append(o.measureOpts, opts...)
+	}
+}
+
+// 解析间接字符串，其他字符串原样返回
+func (o *scanOptions) resolve(s string) string {
+	if o.strings == nil || !mui.IsIndirect(s) {
+		return s
+	}
+	text, _ := o.strings.Resolve(s)
+	return text
+}
@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// 注册表根键的缩写和全称
+var rootKeys = map[string]registry.Key{
+	"HKCR":                registry.CLASSES_ROOT,
+	"HKEY_CLASSES_ROOT":   registry.CLASSES_ROOT,
+	"HKCU":                registry.CURRENT_USER,
+	"HKEY_CURRENT_USER":   registry.CURRENT_USER,
+	"HKLM":                registry.LOCAL_MACHINE,
+	"HKEY_LOCAL_MACHINE":  registry.LOCAL_MACHINE,
+	"HKU":                 registry.USERS,
+	"HKEY_USERS":          registry.USERS,
+	"HKCC":                registry.CURRENT_CONFIG,
+	"HKEY_CURRENT_CONFIG": registry.CURRENT_CONFIG,
+}
+
+// SplitKey 把 App.RegistryKey 形式的名称（如 HKLM\Software\...）分为根键和子路径
+func SplitKey(name string) (registry.Key, string, error) {
+	root, path, _ := strings.Cut(name, `\`)
+	key, ok := rootKeys[strings.ToUpper(root)]
+	if !ok || path == "" {
+		return 0, "", fmt.Errorf("无效的注册表项 '%s'", name)
+	}
+	return key, path, nil
+}
+
+// OpenKey 打开 App.RegistryKey 形式的注册表项
+func OpenKey(name string, access uint32) (registry.Key, error) {
+	root, path, err := SplitKey(name)
+	if err != nil {
+		return 0, err
+	}
+	return registry.OpenKey(root, path, access)
+}
+
+// KeyExists 判断注册表项是否存在，用于卸载后确认ARP项已被删除。无权访问的项视为存在
+func KeyExists(name string) bool {
+	root, path, err := SplitKey(name)
+	if err != nil {
+		return false
+	}
+	key, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		return !errors.Is(err, registry.ErrNotExist)
+	}
+	key.Close()
+	return true
+}
@@ -0,0 +1,201 @@
+package inventory
+
+import (
+	"log/slog"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/versions"
+)
+
+// 扫描的卸载注册表路径
+var uninstallPaths = []struct {
+	baseKey registry.Key
+	path    string
+}{
+	{registry.LOCAL_MACHINE, `Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall`},
+	{registry.LOCAL_MACHINE, `Software\Microsoft\Windows\CurrentVersion\Uninstall`},
+	{registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Uninstall`},
+	{registry.CURRENT_USER, `Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall`},
+}
+
+// Scan 读取所有已安装的应用，按 DisplayName 排序。
+// 系统组件（SystemComponent=1）和没有卸载命令的应用不包括在内，子组件和补丁除外
+func Scan(opts ...Option) ([]App, error) {
+	o := &scanOptions{useSystem: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.useSystem {
+		o.strings = systemStrings()
+	}
+
+	start := time.Now()
+
+	// 使用map存储临时应用列表，键为DisplayName
+	tempApps := make(map[string]App)
+	upgradeCodes := UpgradeCodes()
+
+	for _, pathInfo := range uninstallPaths {
+		path := keyName(pathInfo.baseKey) + `\` + pathInfo.path
+		key, err := registry.OpenKey(pathInfo.baseKey, pathInfo.path, registry.READ)
+		if err != nil {
+			slog.Debug("无法打开注册表项", "path", path, "error", err)
+			continue
+		}
+
+		subKeyNames, err := key.ReadSubKeyNames(-1)
+		if err != nil {
+			slog.Warn("无法读取注册表子项", "path", path, "error", err)
+			key.Close()
+			continue
+		}
+		slog.Debug("扫描注册表", "path", path, "subKeys", len(subKeyNames))
+
+		for _, subKeyName := range subKeyNames {
+			subKey, err := registry.OpenKey(key, subKeyName, registry.READ)
+			if err != nil {
+				slog.Debug("无法打开注册表项", "path", path+`\`+subKeyName, "error", err)
+				continue
+			}
+
+			// 读取DisplayName和UninstallString，DisplayName可能是指向资源的间接字符串
+			displayName, _, _ := subKey.GetStringValue("DisplayName")
+			displayName = o.resolve(displayName)
+			uninstallString, _, _ := subKey.GetStringValue("UninstallString")
+
+			// 检查SystemComponent值，如果为1则跳过
+			systemComponent, _, err := subKey.GetIntegerValue("SystemComponent")
+			if err == nil && systemComponent == 1 {
+				slog.Debug("跳过系统组件", "key", subKeyName, "name", displayName)
+				subKey.Close()
+				continue
+			}
+
+			// 子组件和补丁可能没有卸载命令，随父产品卸载
+			parentKeyName, _, _ := subKey.GetStringValue("ParentKeyName")
+			parentDisplayName, _, _ := subKey.GetStringValue("ParentDisplayName")
+			parentDisplayName = o.resolve(parentDisplayName)
+			hasParent := parentKeyName != "" || parentDisplayName != ""
+
+			if displayName != "" && (uninstallString != "" || hasParent) {
+				displayName = strings.TrimSpace(displayName)
+
+				// 读取其他信息
+				displayVersion, _, _ := subKey.GetStringValue("DisplayVersion")
+				publisher, _, _ := subKey.GetStringValue("Publisher")
+				publisher = o.resolve(publisher)
+				installDate, _, _ := subKey.GetStringValue("InstallDate")
+				installLocation, _, _ := subKey.GetStringValue("InstallLocation")
+				displayIcon, _, _ := subKey.GetStringValue("DisplayIcon")
+				estimatedSize, _, _ := subKey.GetIntegerValue("EstimatedSize")
+				releaseType, _, _ := subKey.GetStringValue("ReleaseType")
+				isMinorUpgrade, _, _ := subKey.GetIntegerValue("IsMinorUpgrade")
+
+				// 创建应用对象
+				app := App{
+					DisplayName:       displayName,
+					DisplayVersion:    displayVersion,
+					Publisher:         publisher,
+					InstallDate:       installDate,
+					UninstallString:   uninstallString,
+					InstallLocation:   installLocation,
+					DisplayIcon:       displayIcon,
+					RegistryKey:       path + `\` + subKeyName,
+					EstimatedSize:     uint32(estimatedSize),
+					Scope:             registryScope(pathInfo.baseKey),
+					Architecture:      registryArch(pathInfo.path),
+					ParentKeyName:     parentKeyName,
+					ParentDisplayName: parentDisplayName,
+					ReleaseType:       releaseType,
+					IsMinorUpgrade:    isMinorUpgrade == 1,
+				}
+				readARPValues(&app, subKey, upgradeCodes)
+				normalizeApp(&app, subKey)
+
+				// 检查是否需要更新临时列表，子项按注册表项区分，不与同名产品合并
+				tempKey := displayName
+				if app.IsChild() {
+					tempKey = app.RegistryKey
+				}
+				existingApp, exists := tempApps[tempKey]
+				if !exists || versions.Compare(displayVersion, existingApp.DisplayVersion) > 0 {
+					if exists {
+						slog.Debug("同名应用使用较新的版本", "name", displayName, "version", displayVersion, "replaced", existingApp.RegistryKey)
+					}
+					tempApps[tempKey] = app
+				} else {
+					slog.Debug("同名应用保留较新的版本", "name", displayName, "version", existingApp.DisplayVersion, "skipped", app.RegistryKey)
+				}
+			} else {
+				slog.Debug("跳过没有名称或卸载命令的项", "key", subKeyName, "name", displayName)
+			}
+			subKey.Close()
+		}
+		key.Close()
+	}
+
+	// 将map转换为数组
+	apps := make([]App, 0, len(tempApps))
+	for _, app := range tempApps {
+		apps = append(apps, app)
+	}
+
+	// 按DisplayName排序
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].DisplayName < apps[j].DisplayName
+	})
+
+	slog.Info("扫描已安装的应用", "count", len(apps), "duration", time.Since(start))
+
+	if o.measureSize {
+		MeasureSizes(apps, o.measureOpts...)
+	}
+	return Nest(apps), nil
+}
+
+// 获取注册表根键的字符串表示
+func keyName(key registry.Key) string {
+	switch key {
+	case registry.CLASSES_ROOT:
+		return "HKCR"
+	case registry.CURRENT_USER:
+		return "HKCU"
+	case registry.LOCAL_MACHINE:
+		return "HKLM"
+	case registry.USERS:
+		return "HKU"
+	case registry.CURRENT_CONFIG:
+		return "HKCC"
+	default:
+		return "UNKNOWN_KEY_" + strconv.Itoa(int(key))
+	}
+}
+
+// 根据注册表根键判断安装范围
+func registryScope(baseKey registry.Key) string {
+	if baseKey == registry.CURRENT_USER {
+		return "user"
+	}
+	return "machine"
+}
+
+// Wow6432Node下的是32位应用，其他与系统架构相同
+func registryArch(path string) string {
+	if strings.Contains(strings.ToLower(path), `\wow6432node\`) {
+		return "x86"
+	}
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x64"
+	case "386":
+		return "x86"
+	default:
+		return runtime.GOARCH
+	}
+}
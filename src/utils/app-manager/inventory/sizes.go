@@ -0,0 +1,75 @@
+package inventory
+
+import (
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/diskusage"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/normalize"
+)
+
+// 占用空间的来源
+const (
+	SizeSourceEstimated = "estimated"
+	SizeSourceMeasured  = "measured"
+)
+
+type measureOptions struct {
+	workers int
+	cache   *diskusage.Cache
+}
+
+// MeasureOption 是 MeasureSizes 的选项
+type MeasureOption func(*measureOptions)
+
+// WithWorkers 设置同时统计的目录数量，默认为CPU数量
+func WithWorkers(n int) MeasureOption {
+	return func(o *measureOptions) {
+		if n > 0 {
+			o.workers = n
+		}
+	}
+}
+
+// WithSizeCache 使用按目录修改时间缓存的统计结果，调用方负责保存缓存
+func WithSizeCache(cache *diskusage.Cache) MeasureOption {
+	return func(o *measureOptions) {
+		o.cache = cache
+	}
+}
+
+// MeasureSizes 统计各应用安装目录的实际占用空间，统计成功的应用以实测值作为 SizeBytes
+func MeasureSizes(apps []App, opts ...MeasureOption) {
+	o := &measureOptions{workers: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	paths := []string{}
+	for i := range apps {
+		if loc := apps[i].InstallDir(); loc != "" {
+			paths = append(paths, loc)
+		}
+	}
+
+	start := time.Now()
+	results := diskusage.MeasureAll(paths, o.workers, o.cache)
+	slog.Info("统计安装目录占用空间", "dirs", len(paths), "workers", o.workers, "duration", time.Since(start))
+
+	for i := range apps {
+		r, ok := results[apps[i].InstallDir()]
+		if !ok {
+			continue
+		}
+		if r.Err != nil {
+			slog.Debug("无法统计安装目录", "app", apps[i].DisplayName, "dir", apps[i].InstallDir(), "error", r.Err)
+			continue
+		}
+		apps[i].MeasuredSizeBytes = r.Usage.Allocated
+		apps[i].MeasuredSizeHuman = normalize.HumanSize(r.Usage.Allocated)
+		apps[i].SizeBytes = apps[i].MeasuredSizeBytes
+		apps[i].SizeHuman = apps[i].MeasuredSizeHuman
+		apps[i].SizeSource = SizeSourceMeasured
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/normalize"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/query"
+)
+
+// list 和 export 共用的查询参数
+type queryFlags struct {
+	name            *string
+	publisher       *string
+	installedAfter  *string
+	installedBefore *string
+	minSize         *string
+	maxSize         *string
+	scope           *string
+	arch            *string
+	sort            *string
+	reverse         *bool
+	fields          *string
+	limit           *int
+	measureSize     *bool
+	all             *bool
+	workers         *int
+}
+
+func addQueryFlags(fs *flag.FlagSet) *queryFlags {
+	return &queryFlags{
+		name:            fs.String("name", "", "按名称过滤，支持通配符和 /正则/"),
+		publisher:       fs.String("publisher", "", "按发布者过滤，支持通配符和 /正则/"),
+		installedAfter:  fs.String("installed-after", "", "安装日期不早于 YYYY-MM-DD"),
+		installedBefore: fs.String("installed-before", "", "安装日期早于 YYYY-MM-DD"),
+		minSize:         fs.String("min-size", "", "最小占用空间，如 100MB"),
+		maxSize:         fs.String("max-size", "", "最大占用空间，如 1GB"),
+		scope:           fs.String("scope", "", "安装范围: machine, user"),
+		arch:            fs.String("arch", "", "架构: x86, x64, arm64"),
+		sort:            fs.String("sort", "", "排序: name, size, date, publisher"),
+		reverse:         fs.Bool("reverse", false, "倒序"),
+		fields:          fs.String("fields", "", "输出字段，逗号分隔，如 DisplayName,DisplayVersion"),
+		limit:           fs.Int("limit", 0, "最多输出的数量"),
+		all:             fs.Bool("all", false, "同时列出更新、补丁和子组件"),
+		measureSize:     fs.Bool("measure-size", false, "统计安装目录的实际占用空间"),
+		workers:         fs.Int("workers", runtime.NumCPU(), "统计占用空间的并发数"),
+	}
+}
+
+// 根据参数构造查询，fields 为空表示输出全部字段
+func (f *queryFlags) build() (q *query.Query, fields []string, err error) {
+	q = &query.Query{
+		Scope:   *f.scope,
+		Arch:    *f.arch,
+		Sort:    *f.sort,
+		Reverse: *f.reverse,
+		Limit:   *f.limit,
+	}
+
+	if q.Name, err = query.ParsePattern(*f.name); err != nil {
+		return nil, nil, fmt.Errorf("--name: %v", err)
+	}
+	if q.Publisher, err = query.ParsePattern(*f.publisher); err != nil {
+		return nil, nil, fmt.Errorf("--publisher: %v", err)
+	}
+	if q.InstalledAfter, err = query.ParseDate(*f.installedAfter); err != nil {
+		return nil, nil, err
+	}
+	if q.InstalledBefore, err = query.ParseDate(*f.installedBefore); err != nil {
+		return nil, nil, err
+	}
+	if q.MinSize, err = query.ParseSize(*f.minSize); err != nil {
+		return nil, nil, err
+	}
+	if q.MaxSize, err = query.ParseSize(*f.maxSize); err != nil {
+		return nil, nil, err
+	}
+	if !query.ValidSort(q.Sort) {
+		return nil, nil, fmt.Errorf("不支持的排序字段 '%s'", q.Sort)
+	}
+	if *f.workers < 1 {
+		return nil, nil, fmt.Errorf("--workers 必须大于 0")
+	}
+	if q.Limit < 0 {
+		return nil, nil, fmt.Errorf("--limit 不能为负数")
+	}
+
+	if *f.fields != "" {
+		if fields, err = query.ResolveFields(App{}, *f.fields); err != nil {
+			return nil, nil, err
+		}
+	}
+	return q, fields, nil
+}
+
+func appItem(app *App) query.Item {
+	// 安装日期未知时为零值
+	installDate, _ := time.Parse(normalize.ISODate, app.InstallDateISO)
+	return query.Item{
+		Name:        app.DisplayName,
+		Publisher:   app.Publisher,
+		InstallDate: installDate,
+		Size:        app.SizeBytes,
+		Scope:       app.Scope,
+		Arch:        app.Architecture,
+	}
+}
+
+// 返回满足查询条件的应用
+func queryApps(apps []App, q *query.Query) []App {
+	items := make([]query.Item, len(apps))
+	for i := range apps {
+		items[i] = appItem(&apps[i])
+	}
+
+	result := []App{}
+	for _, i := range q.Apply(items) {
+		result = append(result, apps[i])
+	}
+	return result
+}
+
+// 只保留所选字段的导出结果
+type FieldsResult struct {
+	Success bool           `json:"success"`
+	Apps    []query.Record `json:"apps"`
+	Error   string         `json:"error,omitempty"`
+}
+
+func selectAppFields(apps []App, fields []string) []query.Record {
+	records := []query.Record{}
+	for i := range apps {
+		records = append(records, query.Select(&apps[i], fields))
+	}
+	return records
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/catalog"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+type MigratePackage struct {
+	PackageIdentifier string  `json:"PackageIdentifier"`
+	DisplayName       string  `json:"DisplayName"`
+	Rule              string  `json:"rule"`
+	Confidence        float64 `json:"confidence"`
+}
+
+type UnmatchedApp struct {
+	DisplayName string `json:"DisplayName"`
+	Publisher   string `json:"Publisher"`
+	// 可信度不足时给出的候选软件包
+	Candidate  string  `json:"candidate,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+type MigrateResult struct {
+	Success    bool             `json:"success"`
+	Packages   []MigratePackage `json:"packages"`
+	Unmatched  []UnmatchedApp   `json:"unmatched"`
+	ImportFile string           `json:"importFile,omitempty"`
+	ScriptFile string           `json:"scriptFile,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// winget import 使用的文件格式
+type wingetImport struct {
+	Schema        string               `json:"$schema"`
+	CreationDate  string               `json:"CreationDate"`
+	Sources       []wingetImportSource `json:"Sources"`
+	WinGetVersion string               `json:"WinGetVersion"`
+}
+
+type wingetImportSource struct {
+	Packages      []wingetImportPackage `json:"Packages"`
+	SourceDetails wingetSourceDetails   `json:"SourceDetails"`
+}
+
+type wingetImportPackage struct {
+	PackageIdentifier string `json:"PackageIdentifier"`
+}
+
+type wingetSourceDetails struct {
+	Argument   string `json:"Argument"`
+	Identifier string `json:"Identifier"`
+	Name       string `json:"Name"`
+	Type       string `json:"Type"`
+}
+
+// 将已安装的应用对应到winget软件包，低于minConfidence的匹配视为未匹配
+func matchWingetPackages(apps []App, c *catalog.Catalog, minConfidence float64) *MigrateResult {
+	result := &MigrateResult{
+		Success:   true,
+		Packages:  []MigratePackage{},
+		Unmatched: []UnmatchedApp{},
+	}
+
+	upgradeCodes := inventory.UpgradeCodes()
+	seen := make(map[string]bool)
+	for _, app := range apps {
+		match, ok := c.Match(catalogInfo(&app, upgradeCodes))
+		if !ok || match.Confidence < minConfidence {
+			unmatched := UnmatchedApp{DisplayName: app.DisplayName, Publisher: app.Publisher}
+			if ok {
+				unmatched.Candidate = match.Entry.ID
+				unmatched.Confidence = match.Confidence
+			}
+			result.Unmatched = append(result.Unmatched, unmatched)
+			continue
+		}
+
+		// 同一软件包可能有多个ARP条目，例如同时安装了exe和msi版本
+		id := strings.ToLower(match.Entry.ID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result.Packages = append(result.Packages, MigratePackage{
+			PackageIdentifier: match.Entry.ID,
+			DisplayName:       app.DisplayName,
+			Rule:              match.Rule,
+			Confidence:        match.Confidence,
+		})
+	}
+
+	sort.Slice(result.Packages, func(i, j int) bool {
+		return strings.ToLower(result.Packages[i].PackageIdentifier) < strings.ToLower(result.Packages[j].PackageIdentifier)
+	})
+
+	return result
+}
+
+func writeWingetImport(path string, packages []MigratePackage) error {
+	source := wingetImportSource{
+		Packages: []wingetImportPackage{},
+		SourceDetails: wingetSourceDetails{
+			Argument:   "https://cdn.winget.microsoft.com/cache",
+			Identifier: "Microsoft.Winget.Source_8wekyb3d8bbwe",
+			Name:       "winget",
+			Type:       "Microsoft.PreIndexed.Package",
+		},
+	}
+	for _, p := range packages {
+		source.Packages = append(source.Packages, wingetImportPackage{PackageIdentifier: p.PackageIdentifier})
+	}
+
+	jsonData, err := json.MarshalIndent(wingetImport{
+		Schema:        "https://aka.ms/winget-packages.schema.2.0.json",
+		CreationDate:  time.Now().Format(time.RFC3339),
+		Sources:       []wingetImportSource{source},
+		WinGetVersion: "1.6.0",
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, jsonData, 0644)
+}
+
+// PowerShell单引号字符串中的单引号需要写两次
+func quotePowerShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func writeReinstallScript(path string, packages []MigratePackage) error {
+	var b strings.Builder
+	b.WriteString("# 由 appman migrate export 生成，在新电脑上运行以重新安装应用\r\n")
+	b.WriteString("$ErrorActionPreference = 'Continue'\r\n\r\n")
+	b.WriteString("$packages = @(\r\n")
+	for _, p := range packages {
+		fmt.Fprintf(&b, "    @{ Id = %s; Name = %s }\r\n", quotePowerShell(p.PackageIdentifier), quotePowerShell(p.DisplayName))
+	}
+	b.WriteString(")\r\n\r\n")
+	b.WriteString("$failed = @()\r\n")
+	b.WriteString("foreach ($p in $packages) {\r\n")
+	b.WriteString("    Write-Host \"正在安装 $($p.Name) ($($p.Id))...\"\r\n")
+	b.WriteString("    winget install --id $p.Id --exact --silent --accept-package-agreements --accept-source-agreements\r\n")
+	b.WriteString("    if ($LASTEXITCODE -ne 0) { $failed += $p }\r\n")
+	b.WriteString("}\r\n\r\n")
+	b.WriteString("if ($failed.Count -gt 0) {\r\n")
+	b.WriteString("    Write-Host \"以下应用安装失败:\"\r\n")
+	b.WriteString("    $failed | ForEach-Object { Write-Host \"  $($_.Name) ($($_.Id))\" }\r\n")
+	b.WriteString("    exit 1\r\n")
+	b.WriteString("}\r\n")
+
+	// 带BOM的UTF-8，Windows PowerShell 5.1才能正确读取中文
+	return os.WriteFile(path, append([]byte("\xef\xbb\xbf"), b.String()...), 0644)
+}
+
+// 生成winget导入文件和重新安装脚本
+func exportMigration(result *MigrateResult, outDir string) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+
+	result.ImportFile = filepath.Join(outDir, "winget-import.json")
+	if err := writeWingetImport(result.ImportFile, result.Packages); err != nil {
+		return err
+	}
+
+	result.ScriptFile = filepath.Join(outDir, "reinstall.ps1")
+	return writeReinstallScript(result.ScriptFile, result.Packages)
+}
+
+func writeMigrateResult(w io.Writer, result *MigrateResult, format string) error {
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "已匹配 %d 个winget软件包:\n", len(result.Packages))
+		for _, p := range result.Packages {
+			fmt.Fprintf(w, "  %s <- %s (%s, %.0f%%)\n", p.PackageIdentifier, p.DisplayName, p.Rule, p.Confidence*100)
+		}
+		fmt.Fprintf(w, "\n未匹配 %d 个应用:\n", len(result.Unmatched))
+		for _, u := range result.Unmatched {
+			if u.Candidate != "" {
+				fmt.Fprintf(w, "  %s (候选: %s, %.0f%%)\n", u.DisplayName, u.Candidate, u.Confidence*100)
+			} else {
+				fmt.Fprintf(w, "  %s\n", u.DisplayName)
+			}
+		}
+		fmt.Fprintf(w, "\n导入文件: %s\n重新安装脚本: %s\n", result.ImportFile, result.ScriptFile)
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
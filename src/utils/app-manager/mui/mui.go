@@ -0,0 +1,190 @@
+// Package mui 解析注册表中的间接字符串，如 "@%SystemRoot%\system32\foo.dll,-101"。
+//
+// 字符串从PE文件的字符串表中读取，优先使用语言子目录中的MUI文件
+// （如 system32\zh-CN\foo.dll.mui）。"@{Package?ms-resource://...}" 形式的字符串
+// 保存在应用包的 resources.pri 中，交给 Resolver.Fallback 处理。
+package mui
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/peres"
+)
+
+// Language 是一种界面语言
+type Language struct {
+	Name string // 如 zh-CN，用于查找MUI文件所在的子目录
+	ID   uint32 // 如 0x804，用于选择字符串表的语言
+}
+
+// Ref 是解析后的间接字符串
+type Ref struct {
+	// 包资源引用 @{...} 时为 true，此时只有 Source 有效
+	Package bool
+	Source  string // 文件路径（未展开环境变量）或包资源引用
+	ID      uint32
+}
+
+// IsIndirect 判断字符串是否为间接字符串
+func IsIndirect(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "@")
+}
+
+// Parse 解析间接字符串，格式为 @文件,-ID 或 @{包资源引用}。文件形式后面可以有
+// ;注释，ID 前的负号可以省略
+func Parse(s string) (Ref, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "@") {
+		return Ref{}, false
+	}
+	s = s[1:]
+
+	if strings.HasPrefix(s, "{") {
+		if !strings.HasSuffix(s, "}") || len(s) < 3 {
+			return Ref{}, false
+		}
+		return Ref{Package: true, Source: s}, true
+	}
+
+	if i := strings.Index(s, ";"); i >= 0 {
+		s = s[:i]
+	}
+	i := strings.LastIndex(s, ",")
+	if i <= 0 {
+		return Ref{}, false
+	}
+	num := strings.TrimPrefix(strings.TrimSpace(s[i+1:]), "-")
+	id, err := strconv.ParseUint(num, 10, 16)
+	if err != nil {
+		return Ref{}, false
+	}
+	file := strings.Trim(strings.TrimSpace(s[:i]), `"`)
+	if file == "" {
+		return Ref{}, false
+	}
+	return Ref{Source: file, ID: uint32(id)}, true
+}
+
+// Resolver 解析间接字符串并按文件缓存字符串表，可以在多个goroutine中使用
+type Resolver struct {
+	// 按优先顺序排列的界面语言
+	Languages []Language
+	// 展开路径中的环境变量，为 nil 时不展开
+	Expand func(string) (string, error)
+	// 相对路径的查找目录，通常为系统目录
+	SearchDirs []string
+	// 解析包资源引用，为 nil 时包资源引用保持原样
+	Fallback func(s string) (string, error)
+
+	mu    sync.Mutex
+	files map[string]map[uint32]string
+}
+
+// Resolve 返回间接字符串对应的文本。不是间接字符串或无法解析时返回原字符串和 false
+func (r *Resolver) Resolve(s string) (string, bool) {
+	ref, ok := Parse(s)
+	if !ok {
+		return s, false
+	}
+
+	if ref.Package {
+		if r.Fallback == nil {
+			return s, false
+		}
+		text, err := r.Fallback(strings.TrimSpace(s))
+		if err != nil || text == "" {
+			return s, false
+		}
+		return text, true
+	}
+
+	path := ref.Source
+	if r.Expand != nil {
+		expanded, err := r.Expand(path)
+		if err != nil {
+			return s, false
+		}
+		path = expanded
+	}
+	text, ok := r.lookup(path, ref.ID)
+	if !ok {
+		return s, false
+	}
+	return text, true
+}
+
+func (r *Resolver) lookup(path string, id uint32) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.files == nil {
+		r.files = make(map[string]map[uint32]string)
+	}
+	key := strings.ToLower(path)
+	table, ok := r.files[key]
+	if !ok {
+		table = r.load(path)
+		r.files[key] = table
+	}
+	text, ok := table[id]
+	return text, ok
+}
+
+// 读取文件的字符串表，MUI文件中的字符串优先，找不到文件时返回空表，结果同样会被缓存
+func (r *Resolver) load(path string) map[uint32]string {
+	table := make(map[uint32]string)
+	file := r.locate(path)
+	if file == "" {
+		return table
+	}
+
+	langs := make([]uint32, 0, len(r.Languages))
+	for _, l := range r.Languages {
+		langs = append(langs, l.ID)
+	}
+
+	// 语言无关的文件可能只有部分字符串，因此依次合并
+	candidates := []string{}
+	for _, l := range r.Languages {
+		if l.Name != "" {
+			candidates = append(candidates, filepath.Join(filepath.Dir(file), l.Name, filepath.Base(file)+".mui"))
+		}
+	}
+	candidates = append(candidates, file)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		f, err := peres.Open(candidates[i])
+		if err != nil {
+			continue
+		}
+		for id, s := range f.Strings(langs...) {
+			table[id] = s
+		}
+		f.Close()
+	}
+	return table
+}
+
+// 相对路径依次在 SearchDirs 中查找
+func (r *Resolver) locate(path string) string {
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		if isFile(path) {
+			return path
+		}
+		return ""
+	}
+	for _, dir := range r.SearchDirs {
+		if p := filepath.Join(dir, path); isFile(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,104 @@
+package mui
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Ref
+		ok   bool
+	}{
+		{`@%SystemRoot%\system32\foo.dll,-101`, Ref{Source: `%SystemRoot%\system32\foo.dll`, ID: 101}, true},
+		{`@"C:\Program Files\App\app.dll",-5;Contoso`, Ref{Source: `C:\Program Files\App\app.dll`, ID: 5}, true},
+		{`@shell32.dll,22`, Ref{Source: "shell32.dll", ID: 22}, true},
+		{`@{Microsoft.WindowsStore_8wekyb3d8bbwe?ms-resource://Microsoft.WindowsStore/Resources/AppName}`,
+			Ref{Package: true, Source: `{Microsoft.WindowsStore_8wekyb3d8bbwe?ms-resource://Microsoft.WindowsStore/Resources/AppName}`}, true},
+		{`Contoso App`, Ref{}, false},
+		{`@foo.dll`, Ref{}, false},
+		{`@foo.dll,-abc`, Ref{}, false},
+		{`@,-1`, Ref{}, false},
+		{`@{unterminated`, Ref{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+var (
+	zhCN = Language{Name: "zh-CN", ID: 0x804}
+	enUS = Language{Name: "en-US", ID: 0x409}
+)
+
+func TestResolve(t *testing.T) {
+	dir, _ := filepath.Abs("testdata")
+	expand := func(s string) (string, error) {
+		return strings.ReplaceAll(s, "%TESTDATA%", dir), nil
+	}
+
+	tests := []struct {
+		langs []Language
+		in    string
+		want  string
+		ok    bool
+	}{
+		// MUI文件中的字符串优先，MUI文件中没有的字符串使用原文件中的
+		{[]Language{zhCN, enUS}, `@%TESTDATA%\app.dll,-101`, "Contoso 应用", true},
+		{[]Language{zhCN, enUS}, `@%TESTDATA%\app.dll,-102`, "Contoso Ltd", true},
+		{[]Language{enUS}, `@%TESTDATA%\app.dll,-101`, "Contoso App", true},
+		{nil, `@app.dll,-101`, "Contoso App", true},
+		{[]Language{enUS}, `@%TESTDATA%\app.dll,-999`, `@%TESTDATA%\app.dll,-999`, false},
+		{[]Language{enUS}, `@%TESTDATA%\missing.dll,-101`, `@%TESTDATA%\missing.dll,-101`, false},
+		{[]Language{enUS}, `Contoso`, `Contoso`, false},
+		{[]Language{enUS}, `@{Package?ms-resource://x}`, `@{Package?ms-resource://x}`, false},
+	}
+	for _, tt := range tests {
+		r := &Resolver{Languages: tt.langs, Expand: expand, SearchDirs: []string{dir}}
+		in := filepath.FromSlash(strings.ReplaceAll(tt.in, `\`, "/"))
+		got, ok := r.Resolve(in)
+		want := tt.want
+		if !ok {
+			want = in
+		}
+		if ok != tt.ok || got != want {
+			t.Errorf("Resolve(%q) with %v = %q, %v, want %q, %v", tt.in, tt.langs, got, ok, want, tt.ok)
+		}
+	}
+}
+
+func TestResolveCache(t *testing.T) {
+	dir, _ := filepath.Abs("testdata")
+	r := &Resolver{Languages: []Language{enUS}, SearchDirs: []string{dir}}
+	if got, _ := r.Resolve("@app.dll,-101"); got != "Contoso App" {
+		t.Fatalf("Resolve = %q", got)
+	}
+	if len(r.files) != 1 {
+		t.Errorf("cache has %d files, want 1", len(r.files))
+	}
+	r.Resolve("@APP.DLL,-102")
+	if len(r.files) != 1 {
+		t.Errorf("same file cached twice: %d entries", len(r.files))
+	}
+}
+
+func TestResolvePackage(t *testing.T) {
+	r := &Resolver{Fallback: func(s string) (string, error) {
+		if strings.Contains(s, "Store") {
+			return "Microsoft Store", nil
+		}
+		return "", errors.New("not found")
+	}}
+	if got, ok := r.Resolve("@{Microsoft.WindowsStore?ms-resource://AppName}"); !ok || got != "Microsoft Store" {
+		t.Errorf("Resolve = %q, %v", got, ok)
+	}
+	if got, ok := r.Resolve("@{Other?ms-resource://AppName}"); ok || got != "@{Other?ms-resource://AppName}" {
+		t.Errorf("Resolve = %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,94 @@
+// Package normalize 把注册表中格式不统一的安装日期和大小转换为标准形式。
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISODate 是 InstallDateISO 使用的日期格式
+const ISODate = "2006-01-02"
+
+var (
+	compactDate = regexp.MustCompile(`^(\d{4})(\d{2})(\d{2})$`)
+	// 年在前: 2024-03-15、2024/3/15、2024.03.15
+	yearFirst = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})`)
+	// 年在后: 3/15/2024（美国）、15/03/2024、15.03.2024（欧洲）
+	yearLast = regexp.MustCompile(`^(\d{1,2})[-/.](\d{1,2})[-/.](\d{4})`)
+)
+
+// InstallDate 解析 InstallDate 注册表值，无法识别或日期不合理时返回 false。
+// 年在后且无法区分日和月时按美国格式（月/日/年）处理，这是安装程序最常见的写法
+func InstallDate(raw string) (time.Time, bool) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return time.Time{}, false
+	}
+
+	var year, month, day int
+	if m := compactDate.FindStringSubmatch(s); m != nil {
+		year, month, day = atoi(m[1]), atoi(m[2]), atoi(m[3])
+	} else if m := yearFirst.FindStringSubmatch(s); m != nil {
+		year, month, day = atoi(m[1]), atoi(m[2]), atoi(m[3])
+	} else if m := yearLast.FindStringSubmatch(s); m != nil {
+		first, second := atoi(m[1]), atoi(m[2])
+		year = atoi(m[3])
+		month, day = first, second
+		// 点分隔或第一个数大于12时为 日/月/年
+		if strings.Contains(s[:len(m[0])], ".") || first > 12 {
+			month, day = second, first
+		}
+	} else {
+		return time.Time{}, false
+	}
+
+	return validDate(year, month, day)
+}
+
+func validDate(year, month, day int) (time.Time, bool) {
+	if year < 1980 || year > 2100 || month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	// 拒绝 2月30日 之类会被 time.Date 进位的日期
+	if t.Day() != day {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// SizeBytes 把 EstimatedSize（KB）转换为字节
+func SizeBytes(estimatedKB uint32) int64 {
+	return int64(estimatedKB) * 1024
+}
+
+var sizeUnits = []string{"B", "KB", "MB", "GB", "TB"}
+
+// HumanSize 把字节数转换为易读的形式，如 "5.5 MB"，0 返回空字符串
+func HumanSize(bytes int64) string {
+	if bytes <= 0 {
+		return ""
+	}
+
+	size := float64(bytes)
+	unit := 0
+	for size >= 1024 && unit < len(sizeUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	if size >= 100 {
+		return fmt.Sprintf("%.0f %s", size, sizeUnits[unit])
+	}
+	return fmt.Sprintf("%.1f %s", size, sizeUnits[unit])
+}
@@ -0,0 +1,63 @@
+package normalize
+
+import "testing"
+
+func TestInstallDate(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string // 空字符串表示无法解析
+	}{
+		{"20240315", "2024-03-15"},
+		{"2024-03-15", "2024-03-15"},
+		{"2024/3/5", "2024-03-05"},
+		{"2024.03.15", "2024-03-15"},
+		{"3/15/2024", "2024-03-15"},
+		{"03/05/2024", "2024-03-05"}, // 无法区分时按 月/日/年
+		{"15/03/2024", "2024-03-15"},
+		{"15.03.2024", "2024-03-15"},
+		{"05.03.2024", "2024-03-05"}, // 点分隔为 日.月.年
+		{"2024-03-15 10:20:30", "2024-03-15"},
+		{" 20240315 ", "2024-03-15"},
+		{"", ""},
+		{"0", ""},
+		{"20241315", ""},
+		{"20240230", ""},
+		{"19700101", ""},
+		{"unknown", ""},
+		{"13/13/2024", ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := InstallDate(tt.raw)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("InstallDate(%q) = %s, want failure", tt.raw, got.Format(ISODate))
+			}
+			continue
+		}
+		if !ok || got.Format(ISODate) != tt.want {
+			t.Errorf("InstallDate(%q) = %s, %v, want %s", tt.raw, got.Format(ISODate), ok, tt.want)
+		}
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, ""},
+		{512, "512 B"},
+		{SizeBytes(1), "1.0 KB"},
+		{SizeBytes(5632), "5.5 MB"},
+		{SizeBytes(600 * 1024), "600 MB"},
+		{SizeBytes(3 * 1024 * 1024), "3.0 GB"},
+		{5 << 40, "5.0 TB"},
+		{5 << 50, "5120 TB"},
+	}
+	for _, tt := range tests {
+		if got := HumanSize(tt.bytes); got != tt.want {
+			t.Errorf("HumanSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
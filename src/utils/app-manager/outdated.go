@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/catalog"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+)
+
+type OutdatedApp struct {
+	DisplayName      string  `json:"DisplayName"`
+	InstalledVersion string  `json:"installedVersion"`
+	LatestVersion    string  `json:"latestVersion"`
+	PackageID        string  `json:"packageId"`
+	Rule             string  `json:"rule"`
+	Confidence       float64 `json:"confidence"`
+}
+
+type OutdatedResult struct {
+	Success  bool          `json:"success"`
+	Outdated []OutdatedApp `json:"outdated"`
+	// 在目录中找不到对应软件包的应用数量
+	Unmatched int    `json:"unmatched"`
+	Error     string `json:"error,omitempty"`
+}
+
+// 转换为目录匹配所需的信息，upgradeCodes来自inventory.UpgradeCodes
+func catalogInfo(app *App, upgradeCodes map[string]string) catalog.Installed {
+	productCode := app.ProductCode()
+	return catalog.Installed{
+		Name:        app.DisplayName,
+		Publisher:   app.Publisher,
+		Version:     app.DisplayVersion,
+		ProductCode: productCode,
+		UpgradeCode: upgradeCodes[strings.ToUpper(productCode)],
+	}
+}
+
+// 将已安装的应用与本地目录比较，找出有新版本的应用
+func findOutdatedApps(apps []App, c *catalog.Catalog) *OutdatedResult {
+	result := &OutdatedResult{
+		Success:  true,
+		Outdated: []OutdatedApp{},
+	}
+
+	upgradeCodes := inventory.UpgradeCodes()
+	for _, app := range apps {
+		match, ok := c.Match(catalogInfo(&app, upgradeCodes))
+		if !ok {
+			result.Unmatched++
+			continue
+		}
+
+		if compareVersions(match.Entry.Version, app.DisplayVersion) > 0 {
+			result.Outdated = append(result.Outdated, OutdatedApp{
+				DisplayName:      app.DisplayName,
+				InstalledVersion: app.DisplayVersion,
+				LatestVersion:    match.Entry.Version,
+				PackageID:        match.Entry.ID,
+				Rule:             match.Rule,
+				Confidence:       match.Confidence,
+			})
+		}
+	}
+
+	return result
+}
+
+func writeOutdated(w io.Writer, result *OutdatedResult, format string) error {
+	switch format {
+	case "text":
+		t := &table.Table{
+			Header: []string{"名称", "已安装", "最新", "软件包", "匹配规则", "可信度"},
+			Align:  []table.Align{5: table.AlignRight},
+		}
+		if f, ok := w.(*os.File); ok {
+			t.MaxWidth = consoleWidth(f)
+		}
+		for _, o := range result.Outdated {
+			t.Rows = append(t.Rows, []string{o.DisplayName, o.InstalledVersion, o.LatestVersion, o.PackageID, o.Rule,
+				fmt.Sprintf("%.0f%%", o.Confidence*100)})
+		}
+		if err := t.Render(w); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n%d 个应用有新版本，%d 个应用未在目录中找到\n", len(result.Outdated), result.Unmatched)
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
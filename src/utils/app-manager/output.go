@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/icons"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/query"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/report"
+)
+
+// list 在表格等格式下默认输出的字段
+var listFields = []string{"DisplayName", "DisplayVersion", "Publisher", "InstallDateISO", "SizeHuman"}
+
+// 表格等格式中全部字段不包括嵌套的子项和原始注册表值
+func flatAppFields() []string {
+	fields := []string{}
+	for _, name := range query.FieldNames(App{}) {
+		if name != "Children" && name != "raw" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// --format json 输出的对象，fields 为空时包含全部字段
+func appsJSON(apps []App, fields []string) interface{} {
+	if fields != nil {
+		return FieldsResult{Success: true, Apps: selectAppFields(apps, fields)}
+	}
+	return Result{Success: true, Apps: apps}
+}
+
+// 按格式输出应用列表，fields 为空时输出全部字段
+func writeApps(w io.Writer, apps []App, fields []string, format string) error {
+	if format == "json" {
+		jsonData, err := json.MarshalIndent(appsJSON(apps, fields), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+		return nil
+	}
+
+	if !report.Supported(format) {
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	if fields == nil {
+		fields = flatAppFields()
+	}
+
+	r := &report.Report{
+		Generated: time.Now(),
+		Columns:   fields,
+		Rows:      selectAppFields(apps, fields),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		r.Title = hostname + " 已安装的应用"
+	}
+	if f, ok := w.(*os.File); ok && format == report.FormatTable {
+		r.Width = consoleWidth(f)
+	}
+	if format == report.FormatHTML {
+		r.Icons = make([]string, len(apps))
+		for i := range apps {
+			r.Icons[i] = appIconDataURI(&apps[i])
+		}
+	}
+	return report.Write(w, r, format)
+}
+
+// 解析DisplayIcon，格式为 "path" 或 "path,index"，路径中可以包含环境变量
+func parseIconLocation(s string) (string, int) {
+	path, index := icons.ParseLocation(s)
+	if expanded, err := registry.ExpandString(path); err == nil {
+		path = expanded
+	}
+	return path, index
+}
+
+// 读取应用图标并转换为 data URI，失败时返回空字符串
+func appIconDataURI(app *App) string {
+	path, index := parseIconLocation(app.DisplayIcon)
+	if path == "" || !isFileExists(path) {
+		return ""
+	}
+
+	ico, err := icons.ICO(path, index, 32)
+	if err != nil {
+		return ""
+	}
+	return icons.DataURI(ico)
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestParseIconLocation(t *testing.T) {
+	tests := []struct {
+		in    string
+		path  string
+		index int
+	}{
+		{`C:\Program Files\7-Zip\7zFM.exe`, `C:\Program Files\7-Zip\7zFM.exe`, 0},
+		{`"C:\Program Files\Git\git-bash.exe",0`, `C:\Program Files\Git\git-bash.exe`, 0},
+		{`C:\Windows\system32\shell32.dll,-154`, `C:\Windows\system32\shell32.dll`, -154},
+		{`C:\Apps\a,b\app.ico`, `C:\Apps\a,b\app.ico`, 0},
+		{``, ``, 0},
+	}
+	for _, tt := range tests {
+		path, index := parseIconLocation(tt.in)
+		if path != tt.path || index != tt.index {
+			t.Errorf("parseIconLocation(%q) = %q, %d, want %q, %d", tt.in, path, index, tt.path, tt.index)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package peres
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"sort"
+	"unicode/utf16"
+)
+
+// 测试用的资源，由 buildPE 写入只有一个 .rsrc 节的最小PE文件
+type testResource struct {
+	Type ID
+	Name ID
+	Lang uint32
+	Data []byte
+}
+
+type resNode struct {
+	id       ID
+	children []*resNode
+	data     []byte
+
+	offset uint32
+}
+
+const rsrcRVA = 0x1000
+
+// 按PE规范排列：字符串标识在前，数字标识按升序
+func sortNodes(nodes []*resNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i].id, nodes[j].id
+		if (a.Name != "") != (b.Name != "") {
+			return a.Name != ""
+		}
+		if a.Name != "" {
+			return a.Name < b.Name
+		}
+		return a.Num < b.Num
+	})
+}
+
+func child(parent *resNode, id ID) *resNode {
+	for _, c := range parent.children {
+		if c.id == id {
+			return c
+		}
+	}
+	c := &resNode{id: id}
+	parent.children = append(parent.children, c)
+	return c
+}
+
+func buildRsrc(resources []testResource) []byte {
+	root := &resNode{}
+	for _, r := range resources {
+		lang := child(child(child(root, r.Type), r.Name), ID{Num: r.Lang})
+		lang.data = r.Data
+	}
+
+	// 按层排列目录，之后依次是数据项、字符串和数据
+	var dirs, leaves []*resNode
+	queue := []*resNode{root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.children == nil {
+			leaves = append(leaves, n)
+			continue
+		}
+		sortNodes(n.children)
+		dirs = append(dirs, n)
+		queue = append(queue, n.children...)
+	}
+
+	var offset uint32
+	for _, d := range dirs {
+		d.offset = offset
+		offset += 16 + 8*uint32(len(d.children))
+	}
+	for _, l := range leaves {
+		l.offset = offset
+		offset += 16
+	}
+	names := make(map[string]uint32)
+	for _, d := range dirs {
+		for _, c := range d.children {
+			if c.id.Name != "" {
+				if _, ok := names[c.id.Name]; !ok {
+					names[c.id.Name] = offset
+					offset += 2 + 2*uint32(len(utf16.Encode([]rune(c.id.Name))))
+				}
+			}
+		}
+	}
+	dataOffsets := make(map[*resNode]uint32)
+	for _, l := range leaves {
+		offset = (offset + 3) &^ 3
+		dataOffsets[l] = offset
+		offset += uint32(len(l.data))
+	}
+
+	buf := make([]byte, offset)
+	le := binary.LittleEndian
+	for _, d := range dirs {
+		named := 0
+		for _, c := range d.children {
+			if c.id.Name != "" {
+				named++
+			}
+		}
+		le.PutUint16(buf[d.offset+12:], uint16(named))
+		le.PutUint16(buf[d.offset+14:], uint16(len(d.children)-named))
+		for i, c := range d.children {
+			e := d.offset + 16 + 8*uint32(i)
+			if c.id.Name != "" {
+				le.PutUint32(buf[e:], names[c.id.Name]|0x80000000)
+			} else {
+				le.PutUint32(buf[e:], c.id.Num)
+			}
+			if c.children != nil {
+				le.PutUint32(buf[e+4:], c.offset|0x80000000)
+			} else {
+				le.PutUint32(buf[e+4:], c.offset)
+			}
+		}
+	}
+	for _, l := range leaves {
+		le.PutUint32(buf[l.offset:], rsrcRVA+dataOffsets[l])
+		le.PutUint32(buf[l.offset+4:], uint32(len(l.data)))
+		copy(buf[dataOffsets[l]:], l.data)
+	}
+	for name, off := range names {
+		u := utf16.Encode([]rune(name))
+		le.PutUint16(buf[off:], uint16(len(u)))
+		for i, c := range u {
+			le.PutUint16(buf[off+2+2*uint32(i):], c)
+		}
+	}
+	return buf
+}
+
+// buildPE 生成一个只包含资源节的64位PE文件，resources 为空时不含资源目录
+func buildPE(resources []testResource) []byte {
+	var rsrc []byte
+	if len(resources) > 0 {
+		rsrc = buildRsrc(resources)
+	}
+	rawSize := (uint32(len(rsrc)) + 0x1ff) &^ 0x1ff
+
+	var buf bytes.Buffer
+	dos := make([]byte, 0x40)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3c:], 0x40)
+	buf.Write(dos)
+	buf.WriteString("PE\x00\x00")
+
+	binary.Write(&buf, binary.LittleEndian, pe.FileHeader{
+		Machine:              pe.IMAGE_FILE_MACHINE_AMD64,
+		NumberOfSections:     1,
+		SizeOfOptionalHeader: uint16(binary.Size(pe.OptionalHeader64{})),
+		Characteristics:      pe.IMAGE_FILE_EXECUTABLE_IMAGE | pe.IMAGE_FILE_DLL,
+	})
+	oh := pe.OptionalHeader64{
+		Magic:               0x20b,
+		SectionAlignment:    0x1000,
+		FileAlignment:       0x200,
+		SizeOfImage:         rsrcRVA + 0x1000,
+		SizeOfHeaders:       0x200,
+		NumberOfRvaAndSizes: 16,
+	}
+	if len(rsrc) > 0 {
+		oh.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_RESOURCE] = pe.DataDirectory{VirtualAddress: rsrcRVA, Size: uint32(len(rsrc))}
+	}
+	binary.Write(&buf, binary.LittleEndian, oh)
+
+	section := pe.SectionHeader32{
+		VirtualSize:      uint32(len(rsrc)),
+		VirtualAddress:   rsrcRVA,
+		SizeOfRawData:    rawSize,
+		PointerToRawData: 0x200,
+		Characteristics:  0x40000040,
+	}
+	copy(section.Name[:], ".rsrc")
+	binary.Write(&buf, binary.LittleEndian, section)
+
+	buf.Write(make([]byte, 0x200-buf.Len()))
+	buf.Write(rsrc)
+	buf.Write(make([]byte, int(rawSize)-len(rsrc)))
+	return buf.Bytes()
+}
@@ -0,0 +1,148 @@
+package peres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// IconImage 描述图标组中的一个尺寸
+type IconImage struct {
+	Width    int // 0 表示256
+	Height   int
+	BitCount int
+	ID       uint16
+}
+
+// Icon 把图标组中最接近 size 像素的图像打包为 .ico 文件
+func (f *File) Icon(index, size int) ([]byte, error) {
+	images, err := f.IconGroup(index)
+	if err != nil {
+		return nil, err
+	}
+
+	img := images[bestIcon(images, size)]
+	data, err := f.Lookup(TypeIcon, ID{Num: uint32(img.ID)}, 0)
+	if err != nil {
+		return nil, err
+	}
+	return packIcon(img, data), nil
+}
+
+// PickIcon 从 .ico 文件中选出最接近 size 像素的图像，重新打包为只含一个图像的 .ico 文件
+func PickIcon(ico []byte, size int) ([]byte, error) {
+	// ICONDIR: Reserved, Type(1为图标), Count，之后是16字节的 ICONDIRENTRY
+	if len(ico) < 6 || binary.LittleEndian.Uint16(ico[2:]) != 1 {
+		return nil, fmt.Errorf("不是有效的图标文件")
+	}
+	count := int(binary.LittleEndian.Uint16(ico[4:]))
+	if count == 0 || len(ico) < 6+16*count {
+		return nil, fmt.Errorf("不是有效的图标文件")
+	}
+
+	images := make([]IconImage, count)
+	for i := range images {
+		e := ico[6+16*i:]
+		images[i] = IconImage{
+			Width:    int(e[0]),
+			Height:   int(e[1]),
+			BitCount: int(binary.LittleEndian.Uint16(e[6:])),
+		}
+	}
+
+	best := bestIcon(images, size)
+	e := ico[6+16*best:]
+	length := binary.LittleEndian.Uint32(e[8:])
+	offset := binary.LittleEndian.Uint32(e[12:])
+	if uint64(offset)+uint64(length) > uint64(len(ico)) {
+		return nil, fmt.Errorf("图标数据超出文件范围")
+	}
+	return packIcon(images[best], ico[offset:offset+length]), nil
+}
+
+// 优先选择不小于 size 的最小图像，都小于 size 时选择最大的，尺寸相同时选择颜色更多的
+func bestIcon(images []IconImage, size int) int {
+	best := 0
+	for i := range images {
+		if betterIcon(images[i], images[best], size) {
+			best = i
+		}
+	}
+	return best
+}
+
+// 打包为 ICONDIR + 一个 ICONDIRENTRY，图像数据紧随其后
+func packIcon(img IconImage, data []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, 1})
+	binary.Write(&buf, binary.LittleEndian, struct {
+		Width, Height, ColorCount, Reserved uint8
+		Planes, BitCount                    uint16
+		Size, Offset                        uint32
+	}{uint8(img.Width), uint8(img.Height), 0, 0, 1, uint16(img.BitCount), uint32(len(data)), 6 + 16})
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func iconWidth(img IconImage) int {
+	if img.Width == 0 {
+		return 256
+	}
+	return img.Width
+}
+
+func betterIcon(a, b IconImage, size int) bool {
+	wa, wb := iconWidth(a), iconWidth(b)
+	if wa != wb {
+		if (wa >= size) != (wb >= size) {
+			return wa >= size
+		}
+		if wa >= size {
+			return wa < wb
+		}
+		return wa > wb
+	}
+	return a.BitCount > b.BitCount
+}
+
+// IconGroup 读取图标组，index 的含义与 DisplayIcon 和 ExtractIconEx 相同：
+// 非负数为第几个图标组，负数为图标组的资源ID
+func (f *File) IconGroup(index int) ([]IconImage, error) {
+	names := f.Names(TypeGroupIcon)
+
+	var name ID
+	if index >= 0 {
+		if index >= len(names) {
+			return nil, ErrNotFound
+		}
+		name = names[index]
+	} else {
+		name = ID{Num: uint32(-index)}
+	}
+
+	data, err := f.Lookup(TypeGroupIcon, name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// GRPICONDIR: Reserved, Type, Count，之后是14字节的 GRPICONDIRENTRY
+	if len(data) < 6 {
+		return nil, fmt.Errorf("图标组 %s 无效", name)
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+	if count == 0 || len(data) < 6+14*count {
+		return nil, fmt.Errorf("图标组 %s 无效", name)
+	}
+
+	images := make([]IconImage, count)
+	for i := range images {
+		e := data[6+14*i:]
+		images[i] = IconImage{
+			Width:    int(e[0]),
+			Height:   int(e[1]),
+			BitCount: int(binary.LittleEndian.Uint16(e[6:])),
+			ID:       binary.LittleEndian.Uint16(e[12:]),
+		}
+	}
+	return images, nil
+}
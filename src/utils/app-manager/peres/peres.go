@@ -0,0 +1,289 @@
+// Package peres 读取PE文件（exe/dll）中的资源，不依赖Windows API，
+// 因此可以在任何平台上解析和测试。
+package peres
+
+import (
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf16"
+)
+
+// 资源类型
+const (
+	TypeIcon      = 3
+	TypeString    = 6
+	TypeGroupIcon = 14
+)
+
+// 单个资源数据的大小上限，图标和字符串表远小于此值
+const maxDataSize = 64 << 20
+
+// ErrNotFound 表示文件中没有请求的资源
+var ErrNotFound = errors.New("资源不存在")
+
+// ID 是资源的类型、名称或语言，资源可以用数字或字符串标识
+type ID struct {
+	Num  uint32
+	Name string // 非空时为字符串标识
+}
+
+func (id ID) String() string {
+	if id.Name != "" {
+		return id.Name
+	}
+	return fmt.Sprintf("#%d", id.Num)
+}
+
+// Entry 是资源目录中的一项，Dir 和 Data 只有一个非空
+type Entry struct {
+	ID   ID
+	Dir  []Entry
+	Data *Data
+}
+
+// Data 是一个资源的数据位置
+type Data struct {
+	RVA      uint32
+	Size     uint32
+	CodePage uint32
+}
+
+// File 是打开的PE文件
+type File struct {
+	pe      *pe.File
+	closer  io.Closer
+	section *pe.Section
+	rsrcRVA uint32
+	// 资源目录的第一层（资源类型），按文件中的顺序排列
+	Root []Entry
+}
+
+// Open 打开PE文件并读取资源目录
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := NewFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	file.closer = f
+	return file, nil
+}
+
+// NewFile 从 io.ReaderAt 读取PE文件的资源目录
+func NewFile(r io.ReaderAt) (*File, error) {
+	p, err := pe.NewFile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var dir pe.DataDirectory
+	switch oh := p.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		if oh.NumberOfRvaAndSizes > pe.IMAGE_DIRECTORY_ENTRY_RESOURCE {
+			dir = oh.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_RESOURCE]
+		}
+	case *pe.OptionalHeader64:
+		if oh.NumberOfRvaAndSizes > pe.IMAGE_DIRECTORY_ENTRY_RESOURCE {
+			dir = oh.DataDirectory[pe.IMAGE_DIRECTORY_ENTRY_RESOURCE]
+		}
+	}
+
+	file := &File{pe: p, rsrcRVA: dir.VirtualAddress}
+	if dir.VirtualAddress == 0 || dir.Size == 0 {
+		return file, nil
+	}
+
+	file.section = sectionFor(p, dir.VirtualAddress)
+	if file.section == nil {
+		p.Close()
+		return nil, fmt.Errorf("资源目录不在任何节中")
+	}
+
+	// 防止损坏的文件中目录项互相引用导致死循环
+	visited := make(map[uint32]bool)
+	file.Root, err = file.readDir(0, 0, visited)
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// Close 关闭文件
+func (f *File) Close() error {
+	f.pe.Close()
+	if f.closer != nil {
+		return f.closer.Close()
+	}
+	return nil
+}
+
+func sectionFor(p *pe.File, rva uint32) *pe.Section {
+	for _, s := range p.Sections {
+		size := s.VirtualSize
+		if size == 0 {
+			size = s.Size
+		}
+		if rva >= s.VirtualAddress && rva < s.VirtualAddress+size {
+			return s
+		}
+	}
+	return nil
+}
+
+// 读取资源节中相对资源目录起点 offset 处的数据
+func (f *File) readAt(buf []byte, offset uint32) error {
+	pos := int64(f.rsrcRVA-f.section.VirtualAddress) + int64(offset)
+	_, err := f.section.ReadAt(buf, pos)
+	return err
+}
+
+func (f *File) readDir(offset uint32, depth int, visited map[uint32]bool) ([]Entry, error) {
+	if depth > 2 || visited[offset] {
+		return nil, fmt.Errorf("资源目录结构无效")
+	}
+	visited[offset] = true
+
+	header := make([]byte, 16)
+	if err := f.readAt(header, offset); err != nil {
+		return nil, err
+	}
+	count := int(binary.LittleEndian.Uint16(header[12:])) + int(binary.LittleEndian.Uint16(header[14:]))
+
+	raw := make([]byte, 8*count)
+	if err := f.readAt(raw, offset+16); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, count)
+	for i := 0; i < count; i++ {
+		name := binary.LittleEndian.Uint32(raw[i*8:])
+		target := binary.LittleEndian.Uint32(raw[i*8+4:])
+
+		var entry Entry
+		if name&0x80000000 != 0 {
+			s, err := f.readName(name &^ 0x80000000)
+			if err != nil {
+				return nil, err
+			}
+			entry.ID.Name = s
+		} else {
+			entry.ID.Num = name
+		}
+
+		if target&0x80000000 != 0 {
+			dir, err := f.readDir(target&^0x80000000, depth+1, visited)
+			if err != nil {
+				return nil, err
+			}
+			entry.Dir = dir
+		} else {
+			buf := make([]byte, 12)
+			if err := f.readAt(buf, target); err != nil {
+				return nil, err
+			}
+			entry.Data = &Data{
+				RVA:      binary.LittleEndian.Uint32(buf[0:]),
+				Size:     binary.LittleEndian.Uint32(buf[4:]),
+				CodePage: binary.LittleEndian.Uint32(buf[8:]),
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// 资源名称为长度前缀的UTF-16字符串
+func (f *File) readName(offset uint32) (string, error) {
+	buf := make([]byte, 2)
+	if err := f.readAt(buf, offset); err != nil {
+		return "", err
+	}
+	n := binary.LittleEndian.Uint16(buf)
+	buf = make([]byte, 2*int(n))
+	if err := f.readAt(buf, offset+2); err != nil {
+		return "", err
+	}
+	return decodeUTF16(buf), nil
+}
+
+// ReadData 读取资源数据
+func (f *File) ReadData(d *Data) ([]byte, error) {
+	s := sectionFor(f.pe, d.RVA)
+	if s == nil {
+		return nil, fmt.Errorf("资源数据不在任何节中")
+	}
+	// 大小来自文件本身，分配内存前需要确认数据确实在节内
+	offset := d.RVA - s.VirtualAddress
+	if d.Size > maxDataSize || uint64(offset)+uint64(d.Size) > uint64(s.Size) {
+		return nil, fmt.Errorf("资源数据大小无效: %d", d.Size)
+	}
+	buf := make([]byte, d.Size)
+	if _, err := s.ReadAt(buf, int64(offset)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Names 返回某类型下的所有资源名称，按文件中的顺序排列
+func (f *File) Names(typ uint32) []ID {
+	var ids []ID
+	for _, e := range f.find(f.Root, ID{Num: typ}) {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+// Lookup 查找资源，lang 为0时使用文件中的第一种语言
+func (f *File) Lookup(typ uint32, name ID, lang uint32) ([]byte, error) {
+	for _, e := range f.find(f.Root, ID{Num: typ}) {
+		if e.ID != name {
+			continue
+		}
+		data := pickLang(e.Dir, lang)
+		if data == nil {
+			break
+		}
+		return f.ReadData(data)
+	}
+	return nil, ErrNotFound
+}
+
+func (f *File) find(entries []Entry, id ID) []Entry {
+	for _, e := range entries {
+		if e.ID == id {
+			return e.Dir
+		}
+	}
+	return nil
+}
+
+func pickLang(entries []Entry, lang uint32) *Data {
+	for _, e := range entries {
+		if e.ID.Num == lang && e.Data != nil {
+			return e.Data
+		}
+	}
+	for _, e := range entries {
+		if e.Data != nil {
+			return e.Data
+		}
+	}
+	return nil
+}
+
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(u))
+}
@@ -0,0 +1,240 @@
+package peres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+)
+
+// 生成 GRPICONDIR，每个尺寸对应一个 RT_ICON 资源
+func groupIcon(sizes []int, firstID uint16) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, uint16(len(sizes))})
+	for i, size := range sizes {
+		buf.Write([]byte{byte(size), byte(size), 0, 0})
+		binary.Write(&buf, binary.LittleEndian, [2]uint16{1, 32})
+		binary.Write(&buf, binary.LittleEndian, uint32(100))
+		binary.Write(&buf, binary.LittleEndian, firstID+uint16(i))
+	}
+	return buf.Bytes()
+}
+
+func iconResources() []testResource {
+	return []testResource{
+		{Type: ID{Num: TypeGroupIcon}, Name: ID{Num: 101}, Lang: 1033, Data: groupIcon([]int{16, 32, 0}, 1)},
+		{Type: ID{Num: TypeGroupIcon}, Name: ID{Name: "MAINICON"}, Lang: 1033, Data: groupIcon([]int{48}, 4)},
+		{Type: ID{Num: TypeIcon}, Name: ID{Num: 1}, Lang: 1033, Data: []byte("icon-16")},
+		{Type: ID{Num: TypeIcon}, Name: ID{Num: 2}, Lang: 1033, Data: []byte("icon-32")},
+		{Type: ID{Num: TypeIcon}, Name: ID{Num: 3}, Lang: 1033, Data: []byte("\x89PNG-256")},
+		{Type: ID{Num: TypeIcon}, Name: ID{Num: 4}, Lang: 1033, Data: []byte("icon-48")},
+	}
+}
+
+func openTestFile(t *testing.T, resources []testResource) *File {
+	t.Helper()
+	f, err := NewFile(bytes.NewReader(buildPE(resources)))
+	if err != nil {
+		t.Fatal("Failed to parse PE:", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestNames(t *testing.T) {
+	f := openTestFile(t, iconResources())
+	names := f.Names(TypeGroupIcon)
+	if len(names) != 2 || names[0].Name != "MAINICON" || names[1].Num != 101 {
+		t.Errorf("Unexpected icon group names: %v", names)
+	}
+}
+
+func TestIcon(t *testing.T) {
+	f := openTestFile(t, iconResources())
+
+	tests := []struct {
+		index, size int
+		want        string
+	}{
+		{0, 32, "icon-48"},    // 第一个图标组是字符串标识的 MAINICON
+		{-101, 32, "icon-32"}, // 负数为资源ID
+		{-101, 24, "icon-32"},
+		{-101, 16, "icon-16"},
+		{-101, 64, "\x89PNG-256"},
+		{1, 512, "\x89PNG-256"},
+	}
+	for _, tt := range tests {
+		ico, err := f.Icon(tt.index, tt.size)
+		if err != nil {
+			t.Errorf("Icon(%d, %d) failed: %v", tt.index, tt.size, err)
+			continue
+		}
+		if binary.LittleEndian.Uint16(ico[2:]) != 1 || binary.LittleEndian.Uint16(ico[4:]) != 1 {
+			t.Errorf("Icon(%d, %d) has invalid ICONDIR", tt.index, tt.size)
+		}
+		if size := binary.LittleEndian.Uint32(ico[14:]); int(size) != len(tt.want) {
+			t.Errorf("Icon(%d, %d) declares %d bytes, want %d", tt.index, tt.size, size, len(tt.want))
+		}
+		if got := string(ico[22:]); got != tt.want {
+			t.Errorf("Icon(%d, %d) = %q, want %q", tt.index, tt.size, got, tt.want)
+		}
+	}
+
+	if _, err := f.Icon(2, 32); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing index, got %v", err)
+	}
+	if _, err := f.Icon(-7, 32); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing ID, got %v", err)
+	}
+}
+
+func TestLookupLanguage(t *testing.T) {
+	f := openTestFile(t, []testResource{
+		{Type: ID{Num: 10}, Name: ID{Num: 1}, Lang: 1033, Data: []byte("en")},
+		{Type: ID{Num: 10}, Name: ID{Num: 1}, Lang: 2052, Data: []byte("zh")},
+	})
+
+	if data, err := f.Lookup(10, ID{Num: 1}, 2052); err != nil || string(data) != "zh" {
+		t.Errorf("Lookup(2052) = %q, %v", data, err)
+	}
+	// 没有请求的语言时使用第一种
+	if data, err := f.Lookup(10, ID{Num: 1}, 1041); err != nil || string(data) != "en" {
+		t.Errorf("Lookup(1041) = %q, %v", data, err)
+	}
+}
+
+func TestNoResources(t *testing.T) {
+	f := openTestFile(t, nil)
+	if len(f.Root) != 0 {
+		t.Errorf("Expected no resources, got %v", f.Root)
+	}
+	if _, err := f.Icon(0, 32); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestInvalidFile(t *testing.T) {
+	if _, err := NewFile(bytes.NewReader([]byte("not a pe file"))); err == nil {
+		t.Error("Expected error for invalid file")
+	}
+}
+
+func TestPickIcon(t *testing.T) {
+	f := openTestFile(t, iconResources())
+	ico32, _ := f.Icon(-101, 32)
+	ico16, _ := f.Icon(-101, 16)
+
+	// 拼接成包含两个图像的 .ico 文件
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, 2})
+	entry16, entry32 := append([]byte{}, ico16[6:22]...), append([]byte{}, ico32[6:22]...)
+	binary.LittleEndian.PutUint32(entry16[12:], 6+32)
+	binary.LittleEndian.PutUint32(entry32[12:], 6+32+uint32(len(ico16)-22))
+	buf.Write(entry16)
+	buf.Write(entry32)
+	buf.Write(ico16[22:])
+	buf.Write(ico32[22:])
+
+	picked, err := PickIcon(buf.Bytes(), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(picked, ico32) {
+		t.Errorf("PickIcon() = %q, want %q", picked, ico32)
+	}
+
+	if _, err := PickIcon([]byte("GIF89a"), 32); err == nil {
+		t.Error("Expected error for non-icon data")
+	}
+	truncated := append([]byte{}, buf.Bytes()[:40]...)
+	if _, err := PickIcon(truncated, 32); err == nil {
+		t.Error("Expected error for truncated icon")
+	}
+}
+
+// 生成一个字符串表块，最多16个字符串，空字符串只写长度0
+func stringBlock(strs ...string) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < 16; i++ {
+		var u []uint16
+		if i < len(strs) {
+			u = utf16.Encode([]rune(strs[i]))
+		}
+		binary.Write(&buf, binary.LittleEndian, uint16(len(u)))
+		binary.Write(&buf, binary.LittleEndian, u)
+	}
+	return buf.Bytes()
+}
+
+func stringResources() []testResource {
+	// 字符串 101 在第7块的第5个位置
+	enBlock := make([]string, 6)
+	enBlock[5] = "Windows Media Player"
+	zhBlock := make([]string, 6)
+	zhBlock[5] = "Windows 媒体播放器"
+	return []testResource{
+		{Type: ID{Num: TypeString}, Name: ID{Num: 7}, Lang: 1033, Data: stringBlock(enBlock...)},
+		{Type: ID{Num: TypeString}, Name: ID{Num: 7}, Lang: 2052, Data: stringBlock(zhBlock...)},
+		{Type: ID{Num: TypeString}, Name: ID{Num: 1}, Lang: 0, Data: stringBlock("", "Neutral")},
+	}
+}
+
+func TestString(t *testing.T) {
+	f := openTestFile(t, stringResources())
+
+	tests := []struct {
+		id    uint32
+		langs []uint32
+		want  string
+	}{
+		{101, []uint32{2052}, "Windows 媒体播放器"},
+		{101, []uint32{1041, 1033}, "Windows Media Player"},
+		{101, []uint32{0x1004}, "Windows 媒体播放器"}, // zh-SG 使用同一主语言的 zh-CN
+		{101, nil, "Windows Media Player"},
+		{1, []uint32{2052}, "Neutral"},
+	}
+	for _, tt := range tests {
+		got, err := f.String(tt.id, tt.langs...)
+		if err != nil || got != tt.want {
+			t.Errorf("String(%d, %v) = %q, %v, want %q", tt.id, tt.langs, got, err, tt.want)
+		}
+	}
+
+	for _, id := range []uint32{0, 100, 500} {
+		if _, err := f.String(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for string %d, got %v", id, err)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	f := openTestFile(t, stringResources())
+	all := f.Strings(2052)
+	if len(all) != 2 || all[101] != "Windows 媒体播放器" || all[1] != "Neutral" {
+		t.Errorf("Unexpected strings: %v", all)
+	}
+}
+
+func TestTruncatedStringBlock(t *testing.T) {
+	f := openTestFile(t, []testResource{
+		{Type: ID{Num: TypeString}, Name: ID{Num: 1}, Lang: 1033, Data: []byte{5, 0, 'a', 0}},
+	})
+	if _, err := f.String(0); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error for truncated block, got %v", err)
+	}
+}
+
+func TestOversizedData(t *testing.T) {
+	f := openTestFile(t, []testResource{
+		{Type: ID{Num: TypeIcon}, Name: ID{Num: 1}, Lang: 1033, Data: []byte("icon-16")},
+	})
+	data := f.Root[0].Dir[0].Dir[0].Data
+
+	for _, size := range []uint32{0x10000, maxDataSize + 1, 0xffffffff} {
+		data.Size = size
+		if _, err := f.Lookup(TypeIcon, ID{Num: 1}, 0); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected error for data size %#x, got %v", size, err)
+		}
+	}
+}
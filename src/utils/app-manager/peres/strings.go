@@ -0,0 +1,103 @@
+package peres
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// 字符串表资源按16个字符串一块保存，块的资源ID为 字符串ID/16+1
+const stringsPerBlock = 16
+
+// String 读取字符串表（RT_STRING）中的字符串。langs 为按优先顺序排列的语言ID，
+// 都没有时依次使用同一主语言、中性语言和文件中的第一种语言
+func (f *File) String(id uint32, langs ...uint32) (string, error) {
+	block, err := f.stringBlock(id/stringsPerBlock+1, langs)
+	if err != nil {
+		return "", err
+	}
+	s := block[id%stringsPerBlock]
+	if s == "" {
+		return "", ErrNotFound
+	}
+	return s, nil
+}
+
+// Strings 读取整个字符串表，每一块按 langs 分别选择语言，与 String 的结果一致
+func (f *File) Strings(langs ...uint32) map[uint32]string {
+	result := make(map[uint32]string)
+	for _, e := range f.find(f.Root, ID{Num: TypeString}) {
+		if e.ID.Name != "" || e.ID.Num == 0 {
+			continue
+		}
+		block, err := f.stringBlock(e.ID.Num, langs)
+		if err != nil {
+			continue
+		}
+		for i, s := range block {
+			if s != "" {
+				result[(e.ID.Num-1)*stringsPerBlock+uint32(i)] = s
+			}
+		}
+	}
+	return result
+}
+
+func (f *File) stringBlock(blockID uint32, langs []uint32) ([stringsPerBlock]string, error) {
+	var block [stringsPerBlock]string
+	for _, e := range f.find(f.Root, ID{Num: TypeString}) {
+		if e.ID != (ID{Num: blockID}) {
+			continue
+		}
+		data := pickLangs(e.Dir, langs)
+		if data == nil {
+			break
+		}
+		raw, err := f.ReadData(data)
+		if err != nil {
+			return block, err
+		}
+		return decodeStringBlock(raw)
+	}
+	return block, ErrNotFound
+}
+
+// 每个字符串以UTF-16字符数开头，空字符串只有长度0
+func decodeStringBlock(raw []byte) ([stringsPerBlock]string, error) {
+	var block [stringsPerBlock]string
+	pos := 0
+	for i := range block {
+		if pos+2 > len(raw) {
+			// 末尾的空字符串可能被省略
+			break
+		}
+		n := int(binary.LittleEndian.Uint16(raw[pos:])) * 2
+		pos += 2
+		if pos+n > len(raw) {
+			return block, fmt.Errorf("字符串表数据不完整")
+		}
+		block[i] = decodeUTF16(raw[pos : pos+n])
+		pos += n
+	}
+	return block, nil
+}
+
+// 主语言ID是语言ID的低10位，如 zh-CN(0x804) 和 zh-TW(0x404) 的主语言相同
+const primaryLangMask = 0x3ff
+
+func pickLangs(entries []Entry, langs []uint32) *Data {
+	for _, lang := range langs {
+		for _, e := range entries {
+			if e.ID.Num == lang && e.Data != nil {
+				return e.Data
+			}
+		}
+	}
+	for _, lang := range langs {
+		for _, e := range entries {
+			if e.ID.Num&primaryLangMask == lang&primaryLangMask && e.Data != nil {
+				return e.Data
+			}
+		}
+	}
+	return pickLang(entries, 0)
+}
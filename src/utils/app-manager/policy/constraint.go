@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/versions"
+)
+
+// constraint 是版本约束，外层为"或"，内层为"且"，例如 "<1.5 || >=2.0, <2.3"
+type constraint [][]comparison
+
+type comparison struct {
+	op      string
+	version string
+}
+
+// 按长度排列，保证 ">=" 先于 ">" 匹配
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+func parseConstraint(s string) (constraint, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var c constraint
+	for _, alternative := range strings.Split(s, "||") {
+		var group []comparison
+		for _, part := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' }) {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			cmp := comparison{op: "="}
+			for _, op := range operators {
+				if strings.HasPrefix(part, op) {
+					cmp.op = op
+					part = strings.TrimSpace(part[len(op):])
+					break
+				}
+			}
+			if part == "" {
+				return nil, fmt.Errorf("缺少版本号: %s", s)
+			}
+			cmp.version = part
+			group = append(group, cmp)
+		}
+		if len(group) == 0 {
+			return nil, fmt.Errorf("无效的版本约束: %s", s)
+		}
+		c = append(c, group)
+	}
+	return c, nil
+}
+
+func (c constraint) matches(version string) bool {
+	for _, group := range c {
+		ok := true
+		for _, cmp := range group {
+			if !cmp.matches(version) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c comparison) matches(version string) bool {
+	result := versions.Compare(version, c.version)
+	switch c.op {
+	case ">=":
+		return result >= 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case "<":
+		return result < 0
+	case "!=":
+		return result != 0
+	default:
+		return result == 0
+	}
+}
@@ -0,0 +1,252 @@
+// Package policy 按规则文件评估已安装的应用，规则文件可以是 YAML 或 JSON。
+//
+// 规则按顺序匹配，第一条命中的规则决定应用的处理方式；没有规则命中时使用
+// default 指定的动作（默认为 allow）。
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 规则动作
+const (
+	ActionAllow         = "allow"
+	ActionWarn          = "warn"
+	ActionDeny          = "deny"
+	ActionAutoUninstall = "auto-uninstall"
+)
+
+// 签名状态
+const (
+	SignatureSigned   = "signed"
+	SignatureUnsigned = "unsigned"
+	SignatureInvalid  = "invalid"
+	SignatureUnknown  = "unknown"
+)
+
+// Policy 是一个规则文件
+type Policy struct {
+	Default string `yaml:"default" json:"default"`
+	Rules   []Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule 是一条规则，Match 中的条件同时满足时命中
+type Rule struct {
+	Name   string `yaml:"name" json:"name"`
+	Action string `yaml:"action" json:"action"`
+	Reason string `yaml:"reason" json:"reason,omitempty"`
+	Match  Match  `yaml:"match" json:"match"`
+
+	name      *regexp.Regexp
+	publisher *regexp.Regexp
+	version   constraint
+}
+
+// Match 描述规则的匹配条件，未填写的条件不参与匹配。
+// DisplayName 和 Publisher 为不区分大小写的通配符（如 "*TeamViewer*"），
+// 用 /.../ 包裹时按正则表达式处理。Version 为版本约束，如 ">=1.0, <2.0"。
+type Match struct {
+	DisplayName   string   `yaml:"displayName" json:"displayName,omitempty"`
+	Publisher     string   `yaml:"publisher" json:"publisher,omitempty"`
+	Version       string   `yaml:"version" json:"version,omitempty"`
+	InstallerType []string `yaml:"installerType" json:"installerType,omitempty"`
+	Signature     []string `yaml:"signature" json:"signature,omitempty"`
+}
+
+// Subject 是待评估的应用
+type Subject struct {
+	DisplayName   string
+	Publisher     string
+	Version       string
+	InstallerType string
+	Signature     string
+}
+
+// Decision 是一个应用的评估结果，没有规则命中时 Rule 为空
+type Decision struct {
+	Action string `json:"action"`
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Violation 表示该结果是否违反策略
+func (d Decision) Violation() bool {
+	return d.Action == ActionDeny || d.Action == ActionAutoUninstall
+}
+
+// Load 读取并校验规则文件，JSON是YAML的子集，因此两种格式使用同一解析器
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse 解析并校验规则内容
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Default == "" {
+		p.Default = ActionAllow
+	}
+	if !validAction(p.Default) {
+		return nil, fmt.Errorf("无效的默认动作 '%s'", p.Default)
+	}
+
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if !validAction(r.Action) {
+			return nil, fmt.Errorf("规则 %s: 无效的动作 '%s'", r.Name, r.Action)
+		}
+
+		var err error
+		if r.name, err = compilePattern(r.Match.DisplayName); err != nil {
+			return nil, fmt.Errorf("规则 %s: displayName: %v", r.Name, err)
+		}
+		if r.publisher, err = compilePattern(r.Match.Publisher); err != nil {
+			return nil, fmt.Errorf("规则 %s: publisher: %v", r.Name, err)
+		}
+		if r.version, err = parseConstraint(r.Match.Version); err != nil {
+			return nil, fmt.Errorf("规则 %s: version: %v", r.Name, err)
+		}
+		for _, s := range r.Match.Signature {
+			if !validSignature(s) {
+				return nil, fmt.Errorf("规则 %s: 无效的签名状态 '%s'", r.Name, s)
+			}
+		}
+	}
+
+	return &p, nil
+}
+
+// NeedsSignature 表示是否有规则依赖签名状态，校验签名较慢，不需要时可以跳过
+func (p *Policy) NeedsSignature() bool {
+	for _, r := range p.Rules {
+		if len(r.Match.Signature) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate 返回第一条命中规则的动作
+func (p *Policy) Evaluate(s Subject) Decision {
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.matches(s) {
+			return Decision{Action: r.Action, Rule: r.Name, Reason: r.Reason}
+		}
+	}
+	return Decision{Action: p.Default}
+}
+
+func (r *Rule) matches(s Subject) bool {
+	if r.name != nil && !r.name.MatchString(s.DisplayName) {
+		return false
+	}
+	if r.publisher != nil && !r.publisher.MatchString(s.Publisher) {
+		return false
+	}
+	if r.version != nil && !r.version.matches(s.Version) {
+		return false
+	}
+	if len(r.Match.InstallerType) > 0 && !containsFold(r.Match.InstallerType, s.InstallerType) {
+		return false
+	}
+	if len(r.Match.Signature) > 0 && !containsFold(r.Match.Signature, s.Signature) {
+		return false
+	}
+	return true
+}
+
+// 通配符转换为正则表达式，/.../ 形式直接作为正则表达式
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+	}
+
+	// 校验通配符语法
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// 与 path.Match 的语法一致：[...] 为字符类，[^...] 为排除，\ 转义下一个字符。
+	// 不同的是 * 和 ? 也匹配 /，名称中的 / 没有特殊含义
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			i++
+			b.WriteString("[")
+			if runes[i] == '^' {
+				b.WriteString("^")
+				i++
+			}
+			for ; runes[i] != ']'; i++ {
+				if runes[i] == '-' {
+					b.WriteString("-")
+					continue
+				}
+				if runes[i] == '\\' {
+					i++
+				}
+				fmt.Fprintf(&b, `\x{%x}`, runes[i])
+			}
+			b.WriteString("]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func validAction(action string) bool {
+	switch action {
+	case ActionAllow, ActionWarn, ActionDeny, ActionAutoUninstall:
+		return true
+	}
+	return false
+}
+
+func validSignature(s string) bool {
+	switch s {
+	case SignatureSigned, SignatureUnsigned, SignatureInvalid, SignatureUnknown:
+		return true
+	}
+	return false
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
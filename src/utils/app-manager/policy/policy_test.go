@@ -0,0 +1,122 @@
+package policy
+
+import "testing"
+
+func TestEvaluate(t *testing.T) {
+	p, err := Load("testdata/rules.yaml")
+	if err != nil {
+		t.Fatal("Failed to load policy:", err)
+	}
+	if !p.NeedsSignature() {
+		t.Error("Expected NeedsSignature to be true")
+	}
+
+	tests := []struct {
+		subject Subject
+		action  string
+		rule    string
+	}{
+		{Subject{DisplayName: "TeamViewer Host", Publisher: "TeamViewer Germany GmbH", Version: "15.50", Signature: SignatureSigned}, ActionAllow, "approved-remote-support"},
+		{Subject{DisplayName: "TeamViewer Host", Publisher: "TeamViewer Germany GmbH", Version: "15.50", Signature: SignatureUnsigned}, ActionDeny, "remote-access-tools"},
+		{Subject{DisplayName: "TeamViewer 14", Version: "14.7.1965"}, ActionAutoUninstall, "old-teamviewer"},
+		{Subject{DisplayName: "AnyDesk", Version: "8.0.8"}, ActionDeny, "remote-access-tools"},
+		{Subject{DisplayName: "anydesk", Version: "8.0.8"}, ActionDeny, "remote-access-tools"},
+		{Subject{DisplayName: "Contoso Agent", InstallerType: "msi", Signature: SignatureUnsigned}, ActionWarn, "unsigned-msi"},
+		{Subject{DisplayName: "Contoso Agent", InstallerType: "msi", Signature: SignatureSigned}, ActionAllow, ""},
+		{Subject{DisplayName: "7-Zip 23.01 (x64)"}, ActionAllow, ""},
+	}
+
+	for _, tt := range tests {
+		d := p.Evaluate(tt.subject)
+		if d.Action != tt.action || d.Rule != tt.rule {
+			t.Errorf("Evaluate(%+v) = %+v, want %s by %q", tt.subject, d, tt.action, tt.rule)
+		}
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	p, err := Load("testdata/rules.json")
+	if err != nil {
+		t.Fatal("Failed to load policy:", err)
+	}
+	if d := p.Evaluate(Subject{DisplayName: "Firefox", Publisher: "Mozilla"}); d.Action != ActionAllow {
+		t.Errorf("Expected allow, got %+v", d)
+	}
+	if d := p.Evaluate(Subject{DisplayName: "Unknown"}); d.Action != ActionWarn || d.Rule != "" {
+		t.Errorf("Expected default warn, got %+v", d)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	invalid := []string{
+		`default: block`,
+		`rules: [{action: remove}]`,
+		`rules: [{action: deny, match: {displayName: "/[/"}}]`,
+		`rules: [{action: deny, match: {displayName: "[abc"}}]`,
+		`rules: [{action: deny, match: {displayName: 'abc\'}}]`,
+		`rules: [{action: deny, match: {version: ">="}}]`,
+		`rules: [{action: deny, match: {signature: [maybe]}}]`,
+	}
+	for _, data := range invalid {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", data)
+		}
+	}
+}
+
+func TestVersionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint, version string
+		want                bool
+	}{
+		{">=1.0, <2.0", "1.5", true},
+		{">=1.0, <2.0", "2.0", false},
+		{"<1.5 || >=2.0, <2.3", "1.2", true},
+		{"<1.5 || >=2.0, <2.3", "1.8", false},
+		{"<1.5 || >=2.0, <2.3", "2.2.9", true},
+		{"1.2", "1.2.0", true},
+		{"!=1.2", "1.2.0", false},
+		{"> 3.0-beta", "3.0", true},
+	}
+	for _, tt := range tests {
+		c, err := parseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("parseConstraint(%q) failed: %v", tt.constraint, err)
+		}
+		if got := c.matches(tt.version); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestWildcard(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"Microsoft Visual C++ 20[01]*", "Microsoft Visual C++ 2010 x64 Redistributable", true},
+		{"Microsoft Visual C++ 20[01]*", "Microsoft Visual C++ 2015-2022 Redistributable", true},
+		{"Microsoft Visual C++ 20[01]*", "Microsoft Visual C++ 2008 Redistributable", true},
+		{"Microsoft Visual C++ 20[1-9]*", "Microsoft Visual C++ 2008 Redistributable", false},
+		{"Python 3.[^0-9]*", "Python 3.x", true},
+		{"Python 3.[^0-9]*", "Python 3.12", false},
+		{"[a-c]ny*", "AnyDesk", true},
+		{`What\?`, "What?", true},
+		{`What\?`, "Whats", false},
+		{`\*Beta\*`, "*Beta*", true},
+		{`\*Beta\*`, "Beta Tool", false},
+		{`[\]x]`, "]", true},
+		{"Tool/*", "Tool/x/y", true},
+	}
+	for _, tt := range tests {
+		re, err := compilePattern(tt.pattern)
+		if err != nil {
+			t.Errorf("compilePattern(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got := re.MatchString(tt.name); got != tt.want {
+			t.Errorf("%q matching %q = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
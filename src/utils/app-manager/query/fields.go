@@ -0,0 +1,120 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Field 是记录中的一个字段
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Record 是只包含所选字段的记录，序列化为JSON时保持字段顺序
+type Record []Field
+
+// MarshalJSON 按字段顺序输出JSON对象。HTML字符不在这里转义，
+// 由调用方的 json.Encoder 决定是否转义
+func (r Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(f.Name); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1) // Encode 会追加换行
+		buf.WriteByte(':')
+		if err := enc.Encode(f.Value); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// FieldNames 返回结构体按JSON标签命名的所有字段
+func FieldNames(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := jsonName(t.Field(i)); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ResolveFields 把逗号分隔的字段列表（不区分大小写）转换为结构体的JSON字段名
+func ResolveFields(v interface{}, list string) ([]string, error) {
+	names := FieldNames(v)
+
+	var fields []string
+	for _, f := range strings.Split(list, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		found := ""
+		for _, name := range names {
+			if strings.EqualFold(name, f) {
+				found = name
+				break
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("未知字段 '%s'，可用字段: %s", f, strings.Join(names, ", "))
+		}
+		fields = append(fields, found)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("未指定字段")
+	}
+	return fields, nil
+}
+
+// Select 从结构体中取出指定字段，字段名须先经过 ResolveFields 校验
+func Select(v interface{}, fields []string) Record {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	t := rv.Type()
+
+	record := make(Record, 0, len(fields))
+	for _, name := range fields {
+		for i := 0; i < t.NumField(); i++ {
+			if n, ok := jsonName(t.Field(i)); ok && n == name {
+				record = append(record, Field{Name: name, Value: rv.Field(i).Interface()})
+				break
+			}
+		}
+	}
+	return record
+}
+
+func jsonName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
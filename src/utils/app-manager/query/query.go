@@ -0,0 +1,251 @@
+// Package query 实现 list/export 的过滤、排序、截取和字段选择。
+//
+// 过滤条件之间为"且"的关系，未设置的条件不参与过滤。调用方把每个应用转换为
+// Item，Apply 返回符合条件的下标，因此本包不依赖具体的应用类型。
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 排序字段
+const (
+	SortName      = "name"
+	SortSize      = "size"
+	SortDate      = "date"
+	SortPublisher = "publisher"
+)
+
+// Item 是参与过滤和排序的应用属性
+type Item struct {
+	Name        string
+	Publisher   string
+	InstallDate time.Time // 未知时为零值
+	Size        int64     // 字节，未知时为0
+	Scope       string    // machine 或 user
+	Arch        string    // x86、x64 或 arm64
+}
+
+// Query 是一组过滤、排序和截取条件
+type Query struct {
+	Name      *Pattern
+	Publisher *Pattern
+	// 安装日期范围，After 包含当天，Before 不包含当天；安装日期未知的应用不满足日期条件
+	InstalledAfter  time.Time
+	InstalledBefore time.Time
+	// 大小范围（字节），0 表示不限制
+	MinSize int64
+	MaxSize int64
+	Scope   string
+	Arch    string
+
+	Sort    string
+	Reverse bool
+	// 最多返回的数量，0 表示不限制
+	Limit int
+}
+
+// Apply 返回满足条件的元素下标，按排序条件排列并截取前 Limit 个
+func (q *Query) Apply(items []Item) []int {
+	indexes := []int{}
+	for i := range items {
+		if q.Match(items[i]) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	if q.Sort != "" {
+		less := lessFunc(q.Sort, items)
+		sort.SliceStable(indexes, func(a, b int) bool {
+			if q.Reverse {
+				return less(indexes[b], indexes[a])
+			}
+			return less(indexes[a], indexes[b])
+		})
+	} else if q.Reverse {
+		for a, b := 0, len(indexes)-1; a < b; a, b = a+1, b-1 {
+			indexes[a], indexes[b] = indexes[b], indexes[a]
+		}
+	}
+
+	if q.Limit > 0 && len(indexes) > q.Limit {
+		indexes = indexes[:q.Limit]
+	}
+	return indexes
+}
+
+// Match 判断一个元素是否满足所有过滤条件
+func (q *Query) Match(item Item) bool {
+	if q.Name != nil && !q.Name.Match(item.Name) {
+		return false
+	}
+	if q.Publisher != nil && !q.Publisher.Match(item.Publisher) {
+		return false
+	}
+	if !q.InstalledAfter.IsZero() && (item.InstallDate.IsZero() || item.InstallDate.Before(q.InstalledAfter)) {
+		return false
+	}
+	if !q.InstalledBefore.IsZero() && (item.InstallDate.IsZero() || !item.InstallDate.Before(q.InstalledBefore)) {
+		return false
+	}
+	if q.MinSize > 0 && item.Size < q.MinSize {
+		return false
+	}
+	if q.MaxSize > 0 && item.Size > q.MaxSize {
+		return false
+	}
+	if q.Scope != "" && !strings.EqualFold(q.Scope, item.Scope) {
+		return false
+	}
+	if q.Arch != "" && !strings.EqualFold(q.Arch, item.Arch) {
+		return false
+	}
+	return true
+}
+
+// 大小和日期相同时按名称排序，保证输出稳定
+func lessFunc(field string, items []Item) func(a, b int) bool {
+	byName := func(a, b int) bool {
+		return strings.ToLower(items[a].Name) < strings.ToLower(items[b].Name)
+	}
+
+	switch field {
+	case SortSize:
+		return func(a, b int) bool {
+			if items[a].Size != items[b].Size {
+				return items[a].Size < items[b].Size
+			}
+			return byName(a, b)
+		}
+	case SortDate:
+		return func(a, b int) bool {
+			if !items[a].InstallDate.Equal(items[b].InstallDate) {
+				return items[a].InstallDate.Before(items[b].InstallDate)
+			}
+			return byName(a, b)
+		}
+	case SortPublisher:
+		return func(a, b int) bool {
+			pa, pb := strings.ToLower(items[a].Publisher), strings.ToLower(items[b].Publisher)
+			if pa != pb {
+				return pa < pb
+			}
+			return byName(a, b)
+		}
+	default:
+		return byName
+	}
+}
+
+// ValidSort 判断排序字段是否受支持
+func ValidSort(field string) bool {
+	switch field {
+	case "", SortName, SortSize, SortDate, SortPublisher:
+		return true
+	}
+	return false
+}
+
+// Pattern 匹配名称或发布者，不区分大小写：
+//   - /.../ 形式为正则表达式
+//   - 包含 * 或 ? 时为通配符，需要匹配整个字符串
+//   - 其他情况为子串匹配
+type Pattern struct {
+	re *regexp.Regexp
+}
+
+// ParsePattern 解析匹配模式，空字符串返回 nil
+func ParsePattern(s string) (*Pattern, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var expr string
+	switch {
+	case len(s) > 1 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/"):
+		expr = s[1 : len(s)-1]
+	case strings.ContainsAny(s, "*?"):
+		var b strings.Builder
+		b.WriteString("^")
+		for _, r := range s {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		b.WriteString("$")
+		expr = b.String()
+	default:
+		expr = regexp.QuoteMeta(s)
+	}
+
+	re, err := regexp.Compile("(?is)" + expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{re: re}, nil
+}
+
+// Match 判断字符串是否匹配
+func (p *Pattern) Match(s string) bool {
+	return p.re.MatchString(s)
+}
+
+var dateLayouts = []string{"2006-01-02", "20060102", "2006/01/02"}
+
+// ParseDate 解析命令行中的日期，支持 2024-03-15、20240315 和 2024/03/15
+func ParseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的日期 '%s'，应为 YYYY-MM-DD", s)
+}
+
+var sizeUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"k":  1 << 10,
+	"kb": 1 << 10,
+	"m":  1 << 20,
+	"mb": 1 << 20,
+	"g":  1 << 30,
+	"gb": 1 << 30,
+	"t":  1 << 40,
+	"tb": 1 << 40,
+}
+
+// ParseSize 解析大小，如 512K、100MB、1.5G，不带单位时为字节
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	i := len(s)
+	for i > 0 && (s[i-1] < '0' || s[i-1] > '9') {
+		i--
+	}
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("无效的大小 '%s'", s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("无效的大小 '%s'", s)
+	}
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := ParseDate(s)
+	return t
+}
+
+var testItems = []Item{
+	{Name: "7-Zip 23.01 (x64)", Publisher: "Igor Pavlov", InstallDate: date("2024-01-10"), Size: 5 << 20, Scope: "machine", Arch: "x64"},
+	{Name: "Google Chrome", Publisher: "Google LLC", InstallDate: date("2024-03-15"), Size: 600 << 20, Scope: "machine", Arch: "x64"},
+	{Name: "微信", Publisher: "腾讯科技(深圳)有限公司", InstallDate: date("2023-11-02"), Size: 500 << 20, Scope: "machine", Arch: "x86"},
+	{Name: "Visual Studio Code", Publisher: "Microsoft Corporation", Size: 350 << 20, Scope: "user", Arch: "x64"},
+	{Name: "Microsoft Edge", Publisher: "Microsoft Corporation", InstallDate: date("2024-03-20"), Scope: "machine", Arch: "x64"},
+}
+
+func names(indexes []int) []string {
+	out := []string{}
+	for _, i := range indexes {
+		out = append(out, testItems[i].Name)
+	}
+	return out
+}
+
+func mustPattern(t *testing.T, s string) *Pattern {
+	t.Helper()
+	p, err := ParsePattern(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"no filter keeps order", Query{}, []string{"7-Zip 23.01 (x64)", "Google Chrome", "微信", "Visual Studio Code", "Microsoft Edge"}},
+		{"publisher substring", Query{Publisher: mustPattern(t, "microsoft")}, []string{"Visual Studio Code", "Microsoft Edge"}},
+		{"name glob", Query{Name: mustPattern(t, "*chrome")}, []string{"Google Chrome"}},
+		{"name glob anchored", Query{Name: mustPattern(t, "chrome*")}, []string{}},
+		{"name regex", Query{Name: mustPattern(t, `/^(微信|7-zip)/`)}, []string{"7-Zip 23.01 (x64)", "微信"}},
+		{"installed range", Query{InstalledAfter: date("2024-01-10"), InstalledBefore: date("2024-03-20")}, []string{"7-Zip 23.01 (x64)", "Google Chrome"}},
+		{"size range", Query{MinSize: 100 << 20, MaxSize: 500 << 20}, []string{"微信", "Visual Studio Code"}},
+		{"scope and arch", Query{Scope: "machine", Arch: "X64"}, []string{"7-Zip 23.01 (x64)", "Google Chrome", "Microsoft Edge"}},
+		{"sort size desc limit", Query{Sort: SortSize, Reverse: true, Limit: 2}, []string{"Google Chrome", "微信"}},
+		{"sort date", Query{Sort: SortDate, MinSize: 1}, []string{"Visual Studio Code", "微信", "7-Zip 23.01 (x64)", "Google Chrome"}},
+		{"sort publisher ties by name", Query{Sort: SortPublisher, Publisher: mustPattern(t, "Microsoft*")}, []string{"Microsoft Edge", "Visual Studio Code"}},
+		{"reverse without sort", Query{Reverse: true, Limit: 1}, []string{"Microsoft Edge"}},
+		{"composed", Query{Scope: "machine", MinSize: 1, Sort: SortName, Limit: 2}, []string{"7-Zip 23.01 (x64)", "Google Chrome"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(tt.query.Apply(testItems)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatternError(t *testing.T) {
+	if _, err := ParsePattern("/[/"); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+	if p, err := ParsePattern(""); p != nil || err != nil {
+		t.Error("Expected nil pattern for empty string")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"1024", 1024, true},
+		{"512K", 512 << 10, true},
+		{"100MB", 100 << 20, true},
+		{"1.5g", 3 << 29, true},
+		{"2 TB", 2 << 40, true},
+		{"", 0, true},
+		{"10XB", 0, false},
+		{"MB", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, s := range []string{"2024-03-15", "20240315", "2024/03/15"} {
+		if got := date(s); !got.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("ParseDate(%q) = %v", s, got)
+		}
+	}
+	if _, err := ParseDate("15.03.2024"); err == nil {
+		t.Error("Expected error for unsupported date")
+	}
+}
+
+type testApp struct {
+	DisplayName    string `json:"DisplayName"`
+	DisplayVersion string `json:"DisplayVersion"`
+	EstimatedSize  uint32 `json:"EstimatedSize"`
+	internal       string
+}
+
+func TestSelect(t *testing.T) {
+	fields, err := ResolveFields(testApp{}, "displayversion, DisplayName")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fields, []string{"DisplayVersion", "DisplayName"}) {
+		t.Errorf("ResolveFields() = %v", fields)
+	}
+
+	record := Select(&testApp{DisplayName: "微信", DisplayVersion: "3.9", internal: "x"}, fields)
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"DisplayVersion":"3.9","DisplayName":"微信"}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	if _, err := ResolveFields(testApp{}, "DisplayName,internal"); err == nil {
+		t.Error("Expected error for unexported field")
+	}
+	if _, err := ResolveFields(testApp{}, " , "); err == nil {
+		t.Error("Expected error for empty field list")
+	}
+}
@@ -0,0 +1,400 @@
+// Package regfile 读写 regedit 格式的 .reg 文件（Windows Registry Editor Version 5.00），
+// 用于在卸载前备份ARP注册表项，卸载中途失败、注册表项已被删除时再恢复。
+//
+//	keys, err := regfile.Export(app.RegistryKey, regfile.ViewOf(app.Architecture))
+//	err = regfile.Write(f, keys)
+//	...
+//	keys, err = regfile.Parse(data)
+//	err = regfile.CheckARP(keys)
+//	err = regfile.Import(keys, regfile.ViewOf(app.Architecture))
+//
+// 值保留注册表中的原始类型和数据，因此导出再导入不会改变任何值。
+package regfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/arp"
+)
+
+const header = "Windows Registry Editor Version 5.00"
+
+// 64位Windows上的注册表视图，即 KEY_WOW64_64KEY 和 KEY_WOW64_32KEY
+const (
+	View64 uint32 = 0x0100
+	View32 uint32 = 0x0200
+)
+
+// ViewOf 返回与 App.Architecture 对应的注册表视图。32位程序读写64位应用的项时
+// 必须指定视图，否则会被重定向到 WOW6432Node；架构未知时返回0，使用进程默认的视图
+func ViewOf(arch string) uint32 {
+	switch strings.ToLower(arch) {
+	case "x86":
+		return View32
+	case "x64", "arm64":
+		return View64
+	}
+	return 0
+}
+
+// Value 是一个注册表值，Name 为空表示默认值，Data 为注册表中的原始数据
+type Value struct {
+	Name string
+	Type uint32
+	Data []byte
+}
+
+// Key 是一个注册表项及其值，Path 以根键开头，如 HKEY_LOCAL_MACHINE\Software\...
+type Key struct {
+	Path   string
+	Values []Value
+}
+
+// String 返回字符串值，值不存在或不是字符串时返回空字符串
+func (k *Key) String(name string) string {
+	for _, v := range k.Values {
+		if strings.EqualFold(v.Name, name) && (v.Type == arp.TypeSZ || v.Type == arp.TypeExpandSZ) {
+			s, _ := arp.Decode(v.Type, v.Data).Data.(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// 根键的缩写，.reg 文件中使用全称
+var rootNames = map[string]string{
+	"HKCR": "HKEY_CLASSES_ROOT",
+	"HKCU": "HKEY_CURRENT_USER",
+	"HKLM": "HKEY_LOCAL_MACHINE",
+	"HKU":  "HKEY_USERS",
+	"HKCC": "HKEY_CURRENT_CONFIG",
+}
+
+// LongName 把路径中缩写的根键换为全称，如 HKLM\Software 换为 HKEY_LOCAL_MACHINE\Software
+func LongName(path string) string {
+	root, rest, found := strings.Cut(path, `\`)
+	if long, ok := rootNames[strings.ToUpper(root)]; ok {
+		root = long
+	}
+	if !found {
+		return root
+	}
+	return root + `\` + rest
+}
+
+// ShortName 把路径中根键的全称换为缩写，与 App.RegistryKey 的形式一致
+func ShortName(path string) string {
+	root, rest, found := strings.Cut(path, `\`)
+	for short, long := range rootNames {
+		if strings.EqualFold(root, long) {
+			root = short
+			break
+		}
+	}
+	if !found {
+		return root
+	}
+	return root + `\` + rest
+}
+
+// ARP注册表项所在的位置（根键之后的部分），与 inventory 扫描的位置一致
+var uninstallPaths = []string{
+	`Software\Microsoft\Windows\CurrentVersion\Uninstall`,
+	`Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall`,
+}
+
+// CheckARP 检查备份中的注册表项是否都属于同一个ARP注册表项：第一项必须是
+// HKLM 或 HKCU 下 ...\CurrentVersion\Uninstall\<子键名>，其余各项必须是它本身或它的子项。
+// 导入之前必须检查，以免篡改过的备份写入注册表的其他位置
+func CheckARP(keys []Key) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("没有注册表项")
+	}
+
+	top := ShortName(keys[0].Path)
+	root, rest, _ := strings.Cut(top, `\`)
+	if !strings.EqualFold(root, "HKLM") && !strings.EqualFold(root, "HKCU") {
+		return fmt.Errorf("%s 不是ARP注册表项", keys[0].Path)
+	}
+	parent, name := "", rest
+	if i := strings.LastIndex(rest, `\`); i >= 0 {
+		parent, name = rest[:i], rest[i+1:]
+	}
+	isARP := false
+	for _, p := range uninstallPaths {
+		if strings.EqualFold(parent, p) {
+			isARP = true
+		}
+	}
+	if !isARP || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%s 不是ARP注册表项", keys[0].Path)
+	}
+
+	for _, k := range keys[1:] {
+		path := ShortName(k.Path)
+		if !strings.EqualFold(path, top) && !hasPrefixFold(path, top+`\`) {
+			return fmt.Errorf("%s 不在 %s 下", k.Path, keys[0].Path)
+		}
+	}
+	return nil
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// Write 按 regedit 的格式（UTF-16LE 带BOM，CRLF换行）写入注册表项
+func Write(w io.Writer, keys []Key) error {
+	var b strings.Builder
+	b.WriteString(header + "\r\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\r\n[%s]\r\n", LongName(k.Path))
+		for _, v := range k.Values {
+			b.WriteString(formatValue(v) + "\r\n")
+		}
+	}
+	b.WriteString("\r\n")
+
+	u := utf16.Encode([]rune(b.String()))
+	data := make([]byte, 2+2*len(u))
+	data[0], data[1] = 0xFF, 0xFE
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(data[2+2*i:], c)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// REG_SZ 只有在能原样还原时才写成字符串，否则（如缺少结尾的NUL）写成 hex(1)
+func stringData(v Value) (string, bool) {
+	if v.Type != arp.TypeSZ {
+		return "", false
+	}
+	s, _ := arp.Decode(v.Type, v.Data).Data.(string)
+	if !bytes.Equal(encodeString(s), v.Data) || strings.ContainsAny(s, "\r\n") {
+		return "", false
+	}
+	return s, true
+}
+
+func encodeString(s string) []byte {
+	u := utf16.Encode([]rune(s + "\x00"))
+	data := make([]byte, 2*len(u))
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(data[2*i:], c)
+	}
+	return data
+}
+
+// regedit 每行最多约80个字符，较长的十六进制数据以 \ 续行
+const maxLineWidth = 80
+
+func formatValue(v Value) string {
+	name := "@"
+	if v.Name != "" {
+		name = quote(v.Name)
+	}
+	if s, ok := stringData(v); ok {
+		return name + "=" + quote(s)
+	}
+	if v.Type == arp.TypeDWord && len(v.Data) == 4 {
+		return fmt.Sprintf("%s=dword:%08x", name, binary.LittleEndian.Uint32(v.Data))
+	}
+
+	line := name + "=hex:"
+	if v.Type != arp.TypeBinary {
+		line = fmt.Sprintf("%s=hex(%x):", name, v.Type)
+	}
+	var b strings.Builder
+	for i, c := range v.Data {
+		s := fmt.Sprintf("%02x", c)
+		if i < len(v.Data)-1 {
+			s += ","
+		}
+		if len(line)+len(s) > maxLineWidth-2 {
+			b.WriteString(line + "\\\r\n")
+			line = "  "
+		}
+		line += s
+	}
+	b.WriteString(line)
+	return b.String()
+}
+
+// 按BOM解码文件内容，没有BOM时视为UTF-8
+func decodeText(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		data = data[2:]
+		u := make([]uint16, len(data)/2)
+		for i := range u {
+			u[i] = binary.LittleEndian.Uint16(data[2*i:])
+		}
+		return string(utf16.Decode(u)), nil
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		data = data[3:]
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("文件编码不是UTF-16或UTF-8")
+	}
+	return string(data), nil
+}
+
+// Parse 解析 .reg 文件。不支持删除项或值的写法（[-...] 和 "name"=-）
+func Parse(data []byte) ([]Key, error) {
+	text, err := decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	seenHeader := false
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		// 以 \ 结尾的行与下一行相连
+		for strings.HasSuffix(line, `\`) && !strings.HasPrefix(line, "[") && i+1 < len(lines) {
+			i++
+			line = strings.TrimSuffix(line, `\`) + strings.TrimSpace(lines[i])
+		}
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if !seenHeader {
+			if line != header {
+				return nil, fmt.Errorf("第 %d 行: 不是 regedit 5.00 格式的文件", lineNo)
+			}
+			seenHeader = true
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("第 %d 行: 注册表项缺少 ]", lineNo)
+			}
+			path := line[1 : len(line)-1]
+			if strings.HasPrefix(path, "-") {
+				return nil, fmt.Errorf("第 %d 行: 不支持删除注册表项", lineNo)
+			}
+			keys = append(keys, Key{Path: path})
+			continue
+		}
+
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("第 %d 行: 值不属于任何注册表项", lineNo)
+		}
+		v, err := parseValue(line)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %v", lineNo, err)
+		}
+		k := &keys[len(keys)-1]
+		k.Values = append(k.Values, v)
+	}
+	if !seenHeader {
+		return nil, fmt.Errorf("文件为空")
+	}
+	return keys, nil
+}
+
+// 解析开头的带引号字符串，返回字符串和其后的部分
+func unquote(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", fmt.Errorf("缺少引号")
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("字符串没有结束")
+			}
+			i++
+			b.WriteByte(s[i])
+		case '"':
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("字符串没有结束")
+}
+
+func parseValue(line string) (Value, error) {
+	var v Value
+	rest := line
+	if strings.HasPrefix(rest, "@") {
+		rest = rest[1:]
+	} else {
+		name, after, err := unquote(rest)
+		if err != nil {
+			return v, fmt.Errorf("值名称%v", err)
+		}
+		v.Name, rest = name, after
+	}
+	rest, ok := strings.CutPrefix(strings.TrimSpace(rest), "=")
+	if !ok {
+		return v, fmt.Errorf("缺少 =")
+	}
+	rest = strings.TrimSpace(rest)
+
+	switch {
+	case rest == "-":
+		return v, fmt.Errorf("不支持删除值")
+	case strings.HasPrefix(rest, `"`):
+		s, after, err := unquote(rest)
+		if err != nil {
+			return v, err
+		}
+		if strings.TrimSpace(after) != "" {
+			return v, fmt.Errorf("字符串后有多余的内容")
+		}
+		v.Type, v.Data = arp.TypeSZ, encodeString(s)
+	case strings.HasPrefix(rest, "dword:"):
+		n, err := strconv.ParseUint(rest[len("dword:"):], 16, 32)
+		if err != nil {
+			return v, fmt.Errorf("无效的 dword 值")
+		}
+		v.Type, v.Data = arp.TypeDWord, binary.LittleEndian.AppendUint32(nil, uint32(n))
+	case strings.HasPrefix(rest, "hex"):
+		typ, data, ok := strings.Cut(rest[len("hex"):], ":")
+		if !ok {
+			return v, fmt.Errorf("缺少 :")
+		}
+		v.Type = arp.TypeBinary
+		if typ != "" {
+			n, err := strconv.ParseUint(strings.Trim(typ, "()"), 16, 32)
+			if err != nil || !strings.HasPrefix(typ, "(") || !strings.HasSuffix(typ, ")") {
+				return v, fmt.Errorf("无效的值类型 hex%s", typ)
+			}
+			v.Type = uint32(n)
+		}
+		v.Data = []byte{}
+		for _, h := range strings.Split(data, ",") {
+			h = strings.TrimSpace(h)
+			if h == "" {
+				continue
+			}
+			c, err := strconv.ParseUint(h, 16, 8)
+			if err != nil {
+				return v, fmt.Errorf("无效的十六进制数据 '%s'", h)
+			}
+			v.Data = append(v.Data, byte(c))
+		}
+	default:
+		return v, fmt.Errorf("无法识别的值")
+	}
+	return v, nil
+}
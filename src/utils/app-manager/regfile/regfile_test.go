@@ -0,0 +1,149 @@
+package regfile
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/arp"
+)
+
+func TestWriteParse(t *testing.T) {
+	keys := []Key{
+		{Path: `HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip`, Values: []Value{
+			{Name: "", Type: arp.TypeSZ, Data: encodeString("默认值")},
+			{Name: "DisplayName", Type: arp.TypeSZ, Data: encodeString(`7-Zip 23.01 "x64"`)},
+			{Name: "UninstallString", Type: arp.TypeSZ, Data: encodeString(`C:\Program Files\7-Zip\Uninstall.exe`)},
+			{Name: "EstimatedSize", Type: arp.TypeDWord, Data: []byte{0x34, 0x12, 0, 0}},
+			{Name: "InstallLocation", Type: arp.TypeExpandSZ, Data: encodeString(`%ProgramFiles%\7-Zip`)},
+			{Name: "Blob", Type: arp.TypeBinary, Data: bytes.Repeat([]byte{0xAB}, 60)},
+			{Name: "Empty", Type: arp.TypeSZ, Data: []byte{}},
+			{Name: "Q", Type: arp.TypeQWord, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+			{Name: "None", Type: arp.TypeNone, Data: []byte{}},
+		}},
+		{Path: `HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip\Sub`},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, keys); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0xFF, 0xFE}) {
+		t.Error("Write should start with a UTF-16LE BOM")
+	}
+	text, _ := decodeText(buf.Bytes())
+	for _, want := range []string{
+		"[HKEY_LOCAL_MACHINE\\Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\7-Zip]\r\n",
+		`"DisplayName"="7-Zip 23.01 \"x64\""`,
+		`"UninstallString"="C:\\Program Files\\7-Zip\\Uninstall.exe"`,
+		`"EstimatedSize"=dword:00001234`,
+		`"InstallLocation"=hex(2):25,00,`,
+		`"Empty"=hex(1):`,
+		`"Q"=hex(b):01,02,03,04,05,06,07,08`,
+		"\\\r\n  ab,",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q:\n%s", want, text)
+		}
+	}
+	for _, line := range strings.Split(text, "\r\n") {
+		if len(line) > maxLineWidth && !strings.HasPrefix(line, "[") {
+			t.Errorf("line too long: %q", line)
+		}
+	}
+
+	parsed, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range keys {
+		keys[i].Path = LongName(keys[i].Path)
+	}
+	if !reflect.DeepEqual(parsed, keys) {
+		t.Errorf("Parse(Write(keys)) = %+v\nwant %+v", parsed, keys)
+	}
+	if got := parsed[0].String("displayname"); got != `7-Zip 23.01 "x64"` {
+		t.Errorf("String(DisplayName) = %q", got)
+	}
+}
+
+func TestParseUTF8(t *testing.T) {
+	data := "\xEF\xBB\xBFWindows Registry Editor Version 5.00\n\n; comment\n[HKEY_CURRENT_USER\\Software\\App]\n@=\"v\"\n\"Bin\"=hex:01,\\\n  02\n"
+	keys, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Key{{Path: `HKEY_CURRENT_USER\Software\App`, Values: []Value{
+		{Name: "", Type: arp.TypeSZ, Data: encodeString("v")},
+		{Name: "Bin", Type: arp.TypeBinary, Data: []byte{1, 2}},
+	}}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Parse = %+v", keys)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"REGEDIT4\n[HKEY_CURRENT_USER\\A]\n",
+		header + "\n\"A\"=\"x\"\n",
+		header + "\n[-HKEY_CURRENT_USER\\A]\n",
+		header + "\n[HKEY_CURRENT_USER\\A]\n\"A\"=-\n",
+		header + "\n[HKEY_CURRENT_USER\\A]\n\"A\"=dword:xyz\n",
+		header + "\n[HKEY_CURRENT_USER\\A]\n\"A\"=hex(zz):00\n",
+		header + "\n[HKEY_CURRENT_USER\\A]\n\"A=\"x\"\n",
+	}
+	for _, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) should fail", data)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	if got := LongName(`hklm\Software\X`); got != `HKEY_LOCAL_MACHINE\Software\X` {
+		t.Errorf("LongName = %q", got)
+	}
+	if got := ShortName(`HKEY_CURRENT_USER\Software\X`); got != `HKCU\Software\X` {
+		t.Errorf("ShortName = %q", got)
+	}
+}
+
+func TestCheckARP(t *testing.T) {
+	const arpKey = `HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip`
+	tests := []struct {
+		paths []string
+		ok    bool
+	}{
+		{[]string{arpKey}, true},
+		{[]string{arpKey, arpKey + `\Sub`, `HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\7-zip\Sub\Deeper`}, true},
+		{[]string{`HKCU\Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall\{A}`}, true},
+		{nil, false},
+		{[]string{`HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`}, false},
+		{[]string{`HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`}, false},
+		{[]string{`HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip\Sub`}, false},
+		{[]string{`HKEY_CLASSES_ROOT\Software\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip`}, false},
+		{[]string{arpKey, `HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`}, false},
+		{[]string{arpKey, arpKey + `-Evil`}, false},
+		{[]string{arpKey, `HKEY_CURRENT_USER\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip`}, false},
+	}
+	for _, tt := range tests {
+		var keys []Key
+		for _, p := range tt.paths {
+			keys = append(keys, Key{Path: p})
+		}
+		if err := CheckARP(keys); (err == nil) != tt.ok {
+			t.Errorf("CheckARP(%v) = %v, want ok=%v", tt.paths, err, tt.ok)
+		}
+	}
+}
+
+func TestViewOf(t *testing.T) {
+	tests := map[string]uint32{"x86": View32, "x64": View64, "ARM64": View64, "": 0}
+	for arch, want := range tests {
+		if got := ViewOf(arch); got != want {
+			t.Errorf("ViewOf(%q) = %#x, want %#x", arch, got, want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package regfile
+
+import "errors"
+
+var errUnsupported = errors.New("当前系统没有注册表")
+
+// Export 读取注册表项及其所有子项，仅支持Windows
+func Export(path string, view uint32) ([]Key, error) {
+	return nil, errUnsupported
+}
+
+// Import 创建注册表项并写入值，仅支持Windows
+func Import(keys []Key, view uint32) error {
+	return errUnsupported
+}
@@ -0,0 +1,128 @@
+package regfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+var procRegSetValueExW = windows.NewLazySystemDLL("advapi32.dll").NewProc("RegSetValueExW")
+
+var rootKeys = map[string]registry.Key{
+	"HKEY_CLASSES_ROOT":   registry.CLASSES_ROOT,
+	"HKEY_CURRENT_USER":   registry.CURRENT_USER,
+	"HKEY_LOCAL_MACHINE":  registry.LOCAL_MACHINE,
+	"HKEY_USERS":          registry.USERS,
+	"HKEY_CURRENT_CONFIG": registry.CURRENT_CONFIG,
+}
+
+func splitPath(path string) (registry.Key, string, error) {
+	root, rest, _ := strings.Cut(LongName(path), `\`)
+	key, ok := rootKeys[strings.ToUpper(root)]
+	if !ok || rest == "" {
+		return 0, "", fmt.Errorf("无效的注册表项 '%s'", path)
+	}
+	return key, rest, nil
+}
+
+// Export 读取注册表项及其所有子项，path 可以使用根键的缩写（如 App.RegistryKey），
+// view 为 ViewOf 返回的注册表视图。值和子项按名称排序，父项在子项之前
+func Export(path string, view uint32) ([]Key, error) {
+	root, subPath, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []Key
+	if err := export(root, subPath, LongName(path), view, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func export(root registry.Key, subPath, path string, view uint32, keys *[]Key) error {
+	k, err := registry.OpenKey(root, subPath, registry.READ|view)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer k.Close()
+
+	names, err := k.ReadValueNames(-1)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	sort.Strings(names)
+
+	key := Key{Path: path}
+	for _, name := range names {
+		n, typ, err := k.GetValue(name, nil)
+		if err != nil {
+			return fmt.Errorf("%s\\%s: %w", path, name, err)
+		}
+		data := make([]byte, n)
+		if n > 0 {
+			if n, _, err = k.GetValue(name, data); err != nil {
+				return fmt.Errorf("%s\\%s: %w", path, name, err)
+			}
+		}
+		key.Values = append(key.Values, Value{Name: name, Type: typ, Data: data[:n]})
+	}
+	*keys = append(*keys, key)
+
+	subKeys, err := k.ReadSubKeyNames(-1)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	sort.Strings(subKeys)
+	for _, name := range subKeys {
+		if err := export(root, subPath+`\`+name, path+`\`+name, view, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import 在 view 指定的注册表视图中创建注册表项并写入值，已有的同名值被覆盖。
+// 写入 HKEY_LOCAL_MACHINE 需要管理员权限，此时返回的错误包含 windows.ERROR_ACCESS_DENIED
+func Import(keys []Key, view uint32) error {
+	for _, key := range keys {
+		root, subPath, err := splitPath(key.Path)
+		if err != nil {
+			return err
+		}
+		k, _, err := registry.CreateKey(root, subPath, registry.SET_VALUE|view)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key.Path, err)
+		}
+		for _, v := range key.Values {
+			if err := setValue(k, v); err != nil {
+				k.Close()
+				return fmt.Errorf("%s\\%s: %w", key.Path, v.Name, err)
+			}
+		}
+		k.Close()
+	}
+	return nil
+}
+
+// registry 包只能写入几种常见类型，这里直接调用 RegSetValueExW 写入原始数据
+func setValue(k registry.Key, v Value) error {
+	name, err := windows.UTF16PtrFromString(v.Name)
+	if err != nil {
+		return err
+	}
+	var data *byte
+	if len(v.Data) > 0 {
+		data = &v.Data[0]
+	}
+	r, _, _ := procRegSetValueExW.Call(uintptr(k), uintptr(unsafe.Pointer(name)), 0,
+		uintptr(v.Type), uintptr(unsafe.Pointer(data)), uintptr(len(v.Data)))
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type htmlCell struct {
+	Text string
+	// 数字列按数值排序
+	Numeric bool
+}
+
+type htmlRow struct {
+	Icon  template.URL
+	Cells []htmlCell
+}
+
+type htmlData struct {
+	Title     string
+	Generated string
+	Columns   []string
+	Rows      []htmlRow
+	HasIcons  bool
+}
+
+// 独立的HTML文件，样式和排序脚本都内嵌在页面中，不引用外部资源
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: "Segoe UI", "Microsoft YaHei", sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+.meta { color: #666; font-size: 13px; margin-bottom: 16px; }
+table { border-collapse: collapse; font-size: 13px; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: middle; }
+th { background: #f4f4f4; cursor: pointer; user-select: none; white-space: nowrap; }
+th.icon { cursor: default; }
+th[data-order="asc"]::after { content: " ▲"; }
+th[data-order="desc"]::after { content: " ▼"; }
+td.num { text-align: right; }
+td img { width: 16px; height: 16px; display: block; }
+tr:nth-child(even) td { background: #fafafa; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">{{if .Generated}}生成时间 {{.Generated}}，{{end}}共 {{len .Rows}} 项</div>
+<table id="report">
+<thead><tr>{{if .HasIcons}}<th class="icon"></th>{{end}}{{range $i, $c := .Columns}}<th data-column="{{$i}}">{{$c}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{if $.HasIcons}}<td>{{if .Icon}}<img src="{{.Icon}}" alt="">{{end}}</td>{{end}}{{range .Cells}}<td{{if .Numeric}} class="num"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+(function () {
+  var table = document.getElementById("report");
+  var offset = {{if .HasIcons}}1{{else}}0{{end}};
+  var collator = new Intl.Collator("zh-CN", { numeric: true, sensitivity: "base" });
+  table.querySelectorAll("th[data-column]").forEach(function (th) {
+    th.addEventListener("click", function () {
+      var column = Number(th.dataset.column) + offset;
+      var order = th.dataset.order === "asc" ? "desc" : "asc";
+      table.querySelectorAll("th").forEach(function (h) { delete h.dataset.order; });
+      th.dataset.order = order;
+
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[column], y = b.cells[column];
+        var result = x.classList.contains("num") && y.classList.contains("num")
+          ? Number(x.textContent) - Number(y.textContent)
+          : collator.compare(x.textContent, y.textContent);
+        return order === "asc" ? result : -result;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, r *Report) error {
+	data := htmlData{
+		Title:   r.Title,
+		Columns: r.Columns,
+		Rows:    make([]htmlRow, len(r.Rows)),
+	}
+	if data.Title == "" {
+		data.Title = "已安装的应用"
+	}
+	if !r.Generated.IsZero() {
+		data.Generated = r.Generated.Format("2006-01-02 15:04:05")
+	}
+
+	for i, record := range r.Rows {
+		row := htmlRow{Cells: make([]htmlCell, len(record))}
+		for j, f := range record {
+			row.Cells[j] = htmlCell{Text: Cell(f.Value), Numeric: isNumber(f.Value)}
+		}
+		// 只接受内嵌的图片，避免报告引用外部资源
+		if i < len(r.Icons) && strings.HasPrefix(r.Icons[i], "data:image/") {
+			row.Icon = template.URL(r.Icons[i])
+			data.HasIcons = true
+		}
+		data.Rows[i] = row
+	}
+
+	return htmlTemplate.Execute(w, data)
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	case string:
+		return false
+	}
+	_, err := strconv.ParseFloat(Cell(v), 64)
+	return err == nil
+}
@@ -0,0 +1,166 @@
+// Package report 把应用列表输出为表格、CSV、TSV、YAML、NDJSON 或独立的HTML报告。
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/query"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+)
+
+// 输出格式
+const (
+	FormatTable  = "table"
+	FormatCSV    = "csv"
+	FormatTSV    = "tsv"
+	FormatYAML   = "yaml"
+	FormatNDJSON = "ndjson"
+	FormatHTML   = "html"
+)
+
+// Formats 是本包支持的所有格式
+var Formats = []string{FormatTable, FormatCSV, FormatTSV, FormatYAML, FormatNDJSON, FormatHTML}
+
+// Report 是要输出的数据，Rows 中每条记录的字段与 Columns 一致
+type Report struct {
+	Title     string
+	Generated time.Time
+	Columns   []string
+	Rows      []query.Record
+	// 与 Rows 对应的图标 data URI，只用于HTML，可以为空
+	Icons []string
+	// 表格的最大宽度，通常为控制台宽度，0 表示不限制
+	Width int
+}
+
+// Supported 判断是否支持该格式
+func Supported(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Write 按指定格式输出
+func Write(w io.Writer, r *Report, format string) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, r)
+	case FormatCSV:
+		// Excel 需要BOM才能正确识别UTF-8编码的中文
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return err
+		}
+		return writeDelimited(w, r, ',')
+	case FormatTSV:
+		return writeDelimited(w, r, '\t')
+	case FormatYAML:
+		return writeYAML(w, r)
+	case FormatNDJSON:
+		return writeNDJSON(w, r)
+	case FormatHTML:
+		return writeHTML(w, r)
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+}
+
+// Cell 返回字段在文本格式中的表示
+func Cell(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func cells(record query.Record) []string {
+	values := make([]string, len(record))
+	for i, f := range record {
+		values[i] = Cell(f.Value)
+	}
+	return values
+}
+
+func writeTable(w io.Writer, r *Report) error {
+	t := &table.Table{
+		Header:   r.Columns,
+		MaxWidth: r.Width,
+	}
+	for i, record := range r.Rows {
+		t.Rows = append(t.Rows, cells(record))
+		if i == 0 {
+			// 数字列右对齐
+			t.Align = make([]table.Align, len(record))
+			for j, f := range record {
+				if isNumber(f.Value) {
+					t.Align[j] = table.AlignRight
+				}
+			}
+		}
+	}
+	return t.Render(w)
+}
+
+func writeDelimited(w io.Writer, r *Report, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	// 使用CRLF，与Excel导出的文件一致
+	cw.UseCRLF = true
+
+	if err := cw.Write(r.Columns); err != nil {
+		return err
+	}
+	for _, record := range r.Rows {
+		if err := cw.Write(cells(record)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeYAML(w io.Writer, r *Report) error {
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, record := range r.Rows {
+		item := &yaml.Node{Kind: yaml.MappingNode}
+		for _, f := range record {
+			value := &yaml.Node{}
+			if err := value.Encode(f.Value); err != nil {
+				return err
+			}
+			item.Content = append(item.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.Name}, value)
+		}
+		list.Content = append(list.Content, item)
+	}
+	if len(list.Content) == 0 {
+		// 空序列输出为 []
+		list.Style = yaml.FlowStyle
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(list); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// 每行一个JSON对象，便于流式处理
+func writeNDJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, record := range r.Rows {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/query"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+)
+
+func testReport() *Report {
+	return &Report{
+		Title:     "Inventory",
+		Generated: time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+		Columns:   []string{"DisplayName", "Publisher", "EstimatedSize"},
+		Rows: []query.Record{
+			{{Name: "DisplayName", Value: "7-Zip 23.01 (x64)"}, {Name: "Publisher", Value: "Igor Pavlov"}, {Name: "EstimatedSize", Value: uint32(5632)}},
+			{{Name: "DisplayName", Value: "微信"}, {Name: "Publisher", Value: "腾讯科技(深圳)有限公司"}, {Name: "EstimatedSize", Value: uint32(0)}},
+			{{Name: "DisplayName", Value: `Tool "Pro", <beta>`}, {Name: "Publisher", Value: "Line1\nLine2"}, {Name: "EstimatedSize", Value: uint32(12)}},
+		},
+	}
+}
+
+func render(t *testing.T, r *Report, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, r, format); err != nil {
+		t.Fatalf("Write(%s) failed: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestCSV(t *testing.T) {
+	got := render(t, testReport(), FormatCSV)
+	want := "\ufeffDisplayName,Publisher,EstimatedSize\r\n" +
+		"7-Zip 23.01 (x64),Igor Pavlov,5632\r\n" +
+		"微信,腾讯科技(深圳)有限公司,0\r\n" +
+		"\"Tool \"\"Pro\"\", <beta>\",\"Line1\r\nLine2\",12\r\n"
+	if got != want {
+		t.Errorf("Unexpected CSV:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestTSV(t *testing.T) {
+	got := render(t, testReport(), FormatTSV)
+	if strings.HasPrefix(got, "\ufeff") {
+		t.Error("TSV should not start with a BOM")
+	}
+	lines := strings.Split(got, "\r\n")
+	if lines[0] != "DisplayName\tPublisher\tEstimatedSize" || lines[1] != "7-Zip 23.01 (x64)\tIgor Pavlov\t5632" {
+		t.Errorf("Unexpected TSV:\n%s", got)
+	}
+}
+
+func TestTable(t *testing.T) {
+	got := render(t, testReport(), FormatTable)
+	lines := strings.Split(got, "\n")
+	if !strings.HasPrefix(lines[0], "DisplayName") || !strings.Contains(lines[1], "Igor Pavlov") {
+		t.Errorf("Unexpected table:\n%s", got)
+	}
+	// 发布者列按显示宽度对齐，包括中文名称所在的行
+	column := table.Width(lines[0][:strings.Index(lines[0], "Publisher")])
+	if table.Width(lines[1][:strings.Index(lines[1], "Igor Pavlov")]) != column ||
+		table.Width(lines[2][:strings.Index(lines[2], "腾讯")]) != column {
+		t.Errorf("Columns are not aligned:\n%s", got)
+	}
+	if !strings.Contains(lines[3], "Line1 Line2") || len(lines) != 5 {
+		t.Errorf("Multi-line cells should be rendered on one line:\n%s", got)
+	}
+	// 数字列右对齐
+	if !strings.HasSuffix(lines[2], " 0") || !strings.HasSuffix(lines[0], "EstimatedSize") {
+		t.Errorf("Numeric column should be right-aligned:\n%s", got)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	got := render(t, testReport(), FormatYAML)
+	want := `- DisplayName: 7-Zip 23.01 (x64)
+  Publisher: Igor Pavlov
+  EstimatedSize: 5632
+- DisplayName: 微信
+  Publisher: 腾讯科技(深圳)有限公司
+  EstimatedSize: 0
+- DisplayName: Tool "Pro", <beta>
+  Publisher: |-
+    Line1
+    Line2
+  EstimatedSize: 12
+`
+	if got != want {
+		t.Errorf("Unexpected YAML:\n%s", got)
+	}
+
+	if got := render(t, &Report{}, FormatYAML); got != "[]\n" {
+		t.Errorf("Expected empty sequence, got %q", got)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	got := render(t, testReport(), FormatNDJSON)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d:\n%s", len(lines), got)
+	}
+	if lines[1] != `{"DisplayName":"微信","Publisher":"腾讯科技(深圳)有限公司","EstimatedSize":0}` {
+		t.Errorf("Unexpected line: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `<beta>`) {
+		t.Errorf("HTML characters should not be escaped: %s", lines[2])
+	}
+}
+
+func TestHTML(t *testing.T) {
+	r := testReport()
+	r.Icons = []string{"data:image/x-icon;base64,AAABAA==", "", "https://example.com/x.ico"}
+	got := render(t, r, FormatHTML)
+
+	for _, want := range []string{
+		"<title>Inventory</title>",
+		"生成时间 2024-03-15 09:30:00",
+		`<th data-column="2">EstimatedSize</th>`,
+		`<td class="num">5632</td>`,
+		`<img src="data:image/x-icon;base64,AAABAA==" alt="">`,
+		"Tool &#34;Pro&#34;, &lt;beta&gt;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML report does not contain %q", want)
+		}
+	}
+	if strings.Contains(got, "example.com") {
+		t.Error("External icon URLs must not be embedded")
+	}
+	if strings.Count(got, "<img") != 1 {
+		t.Errorf("Expected exactly one icon, got %d", strings.Count(got, "<img"))
+	}
+
+	// 没有图标时不输出图标列
+	r.Icons = nil
+	if got := render(t, r, FormatHTML); strings.Contains(got, `class="icon"`) {
+		t.Error("Icon column should be omitted without icons")
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, testReport(), "xml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if Supported("json") || !Supported(FormatNDJSON) {
+		t.Error("Unexpected result from Supported")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/history"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/regfile"
+)
+
+// 重新注册的操作名称，用于错误参数 action 和历史记录
+const actionReregister = "reregister"
+
+type ReregisterResult struct {
+	Success     bool           `json:"success"`
+	Message     string         `json:"message,omitempty"`
+	Backup      string         `json:"backup,omitempty"`
+	RegistryKey string         `json:"registryKey,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	Code        errcode.Code   `json:"code,omitempty"`
+	Params      errcode.Params `json:"params,omitempty"`
+}
+
+func (r *ReregisterResult) fail(err error) *ReregisterResult {
+	r.Success = false
+	r.Error = err.Error()
+	r.Code = errcode.CodeOf(err)
+	r.Params = errcode.ParamsOf(err)
+	return r
+}
+
+// 备份可以是 .reg 文件路径，也可以是卸载时的历史记录ID。同时返回备份时应用的架构，
+// 用于选择注册表视图；历史记录中找不到时为空
+func resolveBackup(arg string) (path, arch string, err error) {
+	entries, _ := history.Read(historyPath())
+	if _, err := os.Stat(arg); err == nil {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Backup != "" && strings.EqualFold(filepath.Clean(entries[i].Backup), filepath.Clean(arg)) {
+				return arg, entries[i].App.Architecture, nil
+			}
+		}
+		return arg, "", nil
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].ID == arg && entries[i].Backup != "" {
+			return entries[i].Backup, entries[i].App.Architecture, nil
+		}
+	}
+	return "", "", errcode.New(errcode.BackupNotFound, errcode.Params{"backup": arg})
+}
+
+// 从卸载前的备份恢复ARP注册表项，使应用重新出现在“程序和功能”中以便再次卸载。
+// 注册表项仍然存在时需要 force 才会覆盖
+func reregister(arg string, force bool) *ReregisterResult {
+	result := &ReregisterResult{}
+
+	path, arch, err := resolveBackup(arg)
+	if err != nil {
+		return result.fail(err)
+	}
+	result.Backup = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result.fail(errcode.New(errcode.BackupNotFound, errcode.Params{"backup": path}))
+	}
+	keys, err := regfile.Parse(data)
+	if err == nil {
+		// 只恢复ARP注册表项，不导入备份中其他位置的项
+		err = regfile.CheckARP(keys)
+	}
+	if err != nil {
+		return result.fail(errcode.Wrap(errcode.InvalidBackup, errcode.Params{"path": path}, err))
+	}
+
+	top := &keys[0]
+	result.RegistryKey = regfile.ShortName(top.Path)
+	if !force && inventory.KeyExists(result.RegistryKey) {
+		return result.fail(errcode.New(errcode.AlreadyRegistered, errcode.Params{"key": result.RegistryKey}))
+	}
+
+	app := App{
+		DisplayName:    top.String("DisplayName"),
+		DisplayVersion: top.String("DisplayVersion"),
+		Publisher:      top.String("Publisher"),
+		RegistryKey:    result.RegistryKey,
+		Architecture:   arch,
+	}
+	start := time.Now()
+	err = regfile.Import(keys, regfile.ViewOf(arch))
+	switch {
+	case err == nil:
+		result.Success = true
+		result.Message = errcode.Text(errcode.Language(), "succeeded", errcode.Params{"app": app.DisplayName, "action": actionReregister})
+		slog.Info("已恢复注册表项", "key", result.RegistryKey, "backup", path, "keys", len(keys))
+	case errors.Is(err, windows.ERROR_ACCESS_DENIED):
+		result.fail(&errcode.Error{Code: errcode.AccessDenied, Params: errcode.Params{"action": actionReregister}, Err: err})
+	default:
+		result.fail(errcode.Wrap(errcode.RestoreFailed, errcode.Params{"key": result.RegistryKey}, err))
+	}
+
+	entry := &history.Entry{
+		Timestamp:  start,
+		Action:     actionReregister,
+		App:        app,
+		Invocation: os.Args[1:],
+		Success:    result.Success,
+		Code:       result.Code,
+		Error:      result.Error,
+		Params:     result.Params,
+		DurationMs: time.Since(start).Milliseconds(),
+		Backup:     path,
+	}
+	if path := historyPath(); path != "" {
+		if err := history.Append(path, entry); err != nil {
+			slog.Warn("无法写入历史记录", "path", path, "error", err)
+		}
+	}
+	return result
+}
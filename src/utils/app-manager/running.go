@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/uninstall"
+)
+
+// 询问的回答对应的关闭方式，空回答使用第一项
+var closeAnswers = map[string]string{
+	"":  uninstall.CloseGraceful,
+	"g": uninstall.CloseGraceful,
+	"f": uninstall.CloseForce,
+	"c": uninstall.CloseContinue,
+	"q": uninstall.CloseCancel,
+}
+
+// --close-running=ask 时在控制台中询问如何处理正在运行的进程。
+// --json 或没有控制台（如被 Electron 调用）时无法询问，不关闭进程
+func askCloseRunning(out *cli) func([]uninstall.RunningProcess) string {
+	return func(procs []uninstall.RunningProcess) string {
+		if out.json || !isConsole(os.Stdin) {
+			slog.Info("无法询问用户，不关闭正在运行的进程", "count", len(procs))
+			return uninstall.CloseContinue
+		}
+
+		t := &table.Table{
+			Header: []string{"PID", "名称", "路径"},
+			Align:  []table.Align{table.AlignRight},
+		}
+		t.MaxWidth = consoleWidth(os.Stderr)
+		for _, p := range procs {
+			t.Rows = append(t.Rows, []string{fmt.Sprint(p.PID), p.Name, p.Path})
+		}
+		fmt.Fprintf(os.Stderr, "\n以下 %d 个进程正在运行，可能导致卸载失败或需要重新启动:\n\n", len(procs))
+		t.Render(os.Stderr)
+
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			fmt.Fprint(os.Stderr, "\n关闭这些进程? [g] 正常关闭(默认)  [f] 强制结束  [c] 不关闭，继续卸载  [q] 取消卸载: ")
+			line, err := reader.ReadString('\n')
+			answer := strings.ToLower(strings.TrimSpace(line))
+			// 输入已结束时不能把空回答当作默认选项
+			if err != nil && answer == "" {
+				return uninstall.CloseCancel
+			}
+			if mode, ok := closeAnswers[answer]; ok {
+				return mode
+			}
+			if err != nil {
+				return uninstall.CloseCancel
+			}
+		}
+	}
+}
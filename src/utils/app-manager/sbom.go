@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/sbom"
+)
+
+// 从DisplayIcon中找出应用的主程序，DisplayIcon通常为 "C:\path\app.exe,0"
+func mainExecutable(app *App) string {
+	path := strings.Trim(strings.TrimSpace(app.DisplayIcon), `"`)
+	if idx := strings.LastIndex(path, ","); idx > 0 {
+		path = strings.Trim(strings.TrimSpace(path[:idx]), `"`)
+	}
+
+	if !strings.HasSuffix(strings.ToLower(path), ".exe") || !isFileExists(path) {
+		return ""
+	}
+	return path
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// 生成随机的UUID v4
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func buildSBOMComponents(apps []App) []sbom.Component {
+	components := make([]sbom.Component, 0, len(apps))
+	for i := range apps {
+		app := &apps[i]
+		component := sbom.Component{
+			Name:        app.DisplayName,
+			Version:     app.DisplayVersion,
+			Supplier:    app.Publisher,
+			InstallPath: app.InstallLocation,
+			Ref:         app.RegistryKey,
+		}
+		// 主程序无法读取时仍然输出组件，只是不带哈希
+		if exe := mainExecutable(app); exe != "" {
+			component.SHA256, _ = sha256File(exe)
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// 以CycloneDX或SPDX格式导出软件物料清单
+func writeSBOM(w io.Writer, apps []App, format string) error {
+	hostname, _ := os.Hostname()
+	meta := sbom.Metadata{
+		Timestamp:    time.Now(),
+		SerialNumber: newUUID(),
+		Hostname:     hostname,
+		ToolName:     "appman",
+	}
+
+	switch format {
+	case "cyclonedx":
+		return sbom.WriteCycloneDX(w, meta, buildSBOMComponents(apps))
+	case "spdx":
+		return sbom.WriteSPDX(w, meta, buildSBOMComponents(apps))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+}
@@ -0,0 +1,102 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type cdxBOM struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp"`
+	Tools     cdxTools      `json:"tools"`
+	Component *cdxComponent `json:"component,omitempty"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string           `json:"type"`
+	BOMRef     string           `json:"bom-ref,omitempty"`
+	Supplier   *cdxOrganization `json:"supplier,omitempty"`
+	Publisher  string           `json:"publisher,omitempty"`
+	Name       string           `json:"name"`
+	Version    string           `json:"version,omitempty"`
+	Hashes     []cdxHash        `json:"hashes,omitempty"`
+	CPE        string           `json:"cpe,omitempty"`
+	PURL       string           `json:"purl,omitempty"`
+	Properties []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxOrganization struct {
+	Name string `json:"name"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// WriteCycloneDX 以 CycloneDX 1.5 JSON 格式写出清单
+func WriteCycloneDX(w io.Writer, meta Metadata, components []Component) error {
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + meta.SerialNumber,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: meta.Timestamp.UTC().Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{{
+				Type:    "application",
+				Name:    meta.ToolName,
+				Version: meta.ToolVersion,
+			}}},
+		},
+		Components: []cdxComponent{},
+	}
+	if meta.Hostname != "" {
+		bom.Metadata.Component = &cdxComponent{Type: "device", Name: meta.Hostname}
+	}
+
+	for _, c := range components {
+		component := cdxComponent{
+			Type:      "application",
+			BOMRef:    c.Ref,
+			Publisher: c.Supplier,
+			Name:      c.Name,
+			Version:   c.Version,
+			CPE:       c.CPE(),
+			PURL:      c.PURL(),
+		}
+		if c.Supplier != "" {
+			component.Supplier = &cdxOrganization{Name: c.Supplier}
+		}
+		if c.SHA256 != "" {
+			component.Hashes = []cdxHash{{Alg: "SHA-256", Content: c.SHA256}}
+		}
+		if c.InstallPath != "" {
+			component.Properties = []cdxProperty{{Name: "appman:installLocation", Value: c.InstallPath}}
+		}
+		bom.Components = append(bom.Components, component)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(bom)
+}
@@ -0,0 +1,72 @@
+// Package sbom 将已安装的应用导出为 CycloneDX 1.5 或 SPDX 2.3 格式的软件物料清单。
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/cpe"
+)
+
+// Component 是清单中的一个应用
+type Component struct {
+	Name        string
+	Version     string
+	Supplier    string
+	InstallPath string
+	// 唯一标识，通常为ARP注册表键
+	Ref string
+	// 主程序文件的SHA-256，无法确定主程序时为空
+	SHA256 string
+}
+
+// Metadata 描述生成清单的机器和时间
+type Metadata struct {
+	Timestamp time.Time
+	// 不带 urn:uuid: 前缀的UUID
+	SerialNumber string
+	Hostname     string
+	ToolName     string
+	ToolVersion  string
+}
+
+// CPE 返回由发布者、名称和版本推导出的CPE，无法推导时为空
+func (c Component) CPE() string {
+	return cpe.Derive(c.Supplier, c.Name, c.Version)
+}
+
+// PURL 返回 pkg:generic 类型的package URL，缺少名称或版本时为空
+func (c Component) PURL() string {
+	if c.Name == "" || c.Version == "" {
+		return ""
+	}
+
+	purl := "pkg:generic/"
+	if c.Supplier != "" {
+		purl += purlEscape(c.Supplier) + "/"
+	}
+	return purl + purlEscape(c.Name) + "@" + purlEscape(c.Version)
+}
+
+// 除字母、数字和 "."、"-"、"_"、"~" 外的字符都进行百分号编码
+func purlEscape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '.', c == '-', c == '_', c == '~':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func toolString(meta Metadata) string {
+	if meta.ToolVersion == "" {
+		return meta.ToolName
+	}
+	return fmt.Sprintf("%s-%s", meta.ToolName, meta.ToolVersion)
+}
@@ -0,0 +1,161 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var update = flag.Bool("update", false, "更新golden文件")
+
+var testMetadata = Metadata{
+	Timestamp:    time.Date(2024, 3, 15, 8, 30, 0, 0, time.UTC),
+	SerialNumber: "3e671687-395b-41f5-a30f-a58921a69b79",
+	Hostname:     "DESKTOP-TEST",
+	ToolName:     "appman",
+}
+
+var testComponents = []Component{
+	{
+		Name:        "Mozilla Firefox (x64 en-US)",
+		Version:     "125.0",
+		Supplier:    "Mozilla",
+		InstallPath: `C:\Program Files\Mozilla Firefox`,
+		Ref:         `HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\Mozilla Firefox 125.0 (x64 en-US)`,
+		SHA256:      "5d41402abc4b2a76b9719d911017c592b3a5e1f3a7f0c2b9d0e6f2c1b4a8d7e6",
+	},
+	{
+		Name:     "微信",
+		Version:  "3.9.10",
+		Supplier: "腾讯科技(深圳)有限公司",
+		Ref:      `HKLM\Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall\WeChat`,
+	},
+	{
+		Name: "Legacy Tool",
+		Ref:  `HKCU\Software\Microsoft\Windows\CurrentVersion\Uninstall\LegacyTool`,
+	},
+}
+
+func compareGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("Failed to read golden file:", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Output differs from %s, run go test -update to regenerate\ngot:\n%s", path, got)
+	}
+}
+
+func compileSchema(t *testing.T, main string, extra map[string]string) *jsonschema.Schema {
+	t.Helper()
+	compiler := jsonschema.NewCompiler()
+
+	for url, file := range extra {
+		f, err := os.Open(filepath.Join("testdata", "schema", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := compiler.AddResource(url, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := compiler.Compile(filepath.Join("testdata", "schema", main))
+	if err != nil {
+		t.Fatal("Failed to compile schema:", err)
+	}
+	return schema
+}
+
+func validate(t *testing.T, schema *jsonschema.Schema, data []byte) {
+	t.Helper()
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal("Output is not valid JSON:", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		t.Errorf("Output does not validate against schema: %#v", err)
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, testMetadata, testComponents); err != nil {
+		t.Fatal(err)
+	}
+
+	compareGolden(t, "cyclonedx.golden.json", buf.Bytes())
+
+	schema := compileSchema(t, "bom-1.5.schema.json", map[string]string{
+		"http://cyclonedx.org/schema/spdx.schema.json":     "spdx.schema.json",
+		"http://cyclonedx.org/schema/jsf-0.82.schema.json": "jsf-0.82.schema.json",
+	})
+	validate(t, schema, buf.Bytes())
+}
+
+func TestSPDX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSPDX(&buf, testMetadata, testComponents); err != nil {
+		t.Fatal(err)
+	}
+
+	compareGolden(t, "spdx.golden.json", buf.Bytes())
+
+	schema := compileSchema(t, "spdx-schema-2.3.json", nil)
+	validate(t, schema, buf.Bytes())
+}
+
+func TestEmptyInventory(t *testing.T) {
+	cdxSchema := compileSchema(t, "bom-1.5.schema.json", map[string]string{
+		"http://cyclonedx.org/schema/spdx.schema.json":     "spdx.schema.json",
+		"http://cyclonedx.org/schema/jsf-0.82.schema.json": "jsf-0.82.schema.json",
+	})
+	spdxSchema := compileSchema(t, "spdx-schema-2.3.json", nil)
+
+	for _, tt := range []struct {
+		write  func(io.Writer, Metadata, []Component) error
+		schema *jsonschema.Schema
+	}{
+		{WriteCycloneDX, cdxSchema},
+		{WriteSPDX, spdxSchema},
+	} {
+		var buf bytes.Buffer
+		if err := tt.write(&buf, Metadata{SerialNumber: testMetadata.SerialNumber, ToolName: "appman"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		validate(t, tt.schema, buf.Bytes())
+	}
+}
+
+func TestPURL(t *testing.T) {
+	tests := []struct {
+		c    Component
+		want string
+	}{
+		{Component{Name: "Mozilla Firefox", Version: "125.0", Supplier: "Mozilla"}, "pkg:generic/Mozilla/Mozilla%20Firefox@125.0"},
+		{Component{Name: "Notepad++", Version: "8.6"}, "pkg:generic/Notepad%2B%2B@8.6"},
+		{Component{Name: "NoVersion"}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.c.PURL(); got != tt.want {
+			t.Errorf("PURL() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	Comment               string            `json:"comment,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// 未知的信息在SPDX中用NOASSERTION表示
+const noAssertion = "NOASSERTION"
+
+// WriteSPDX 以 SPDX 2.3 JSON 格式写出清单
+func WriteSPDX(w io.Writer, meta Metadata, components []Component) error {
+	name := meta.Hostname
+	if name == "" {
+		name = "installed-apps"
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: "urn:uuid:" + meta.SerialNumber,
+		CreationInfo: spdxCreationInfo{
+			Created:  meta.Timestamp.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolString(meta)},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	for i, c := range components {
+		pkg := spdxPackage{
+			Name:                  c.Name,
+			SPDXID:                fmt.Sprintf("SPDXRef-App-%d", i+1),
+			VersionInfo:           c.Version,
+			Supplier:              noAssertion,
+			DownloadLocation:      noAssertion,
+			FilesAnalyzed:         false,
+			LicenseConcluded:      noAssertion,
+			LicenseDeclared:       noAssertion,
+			CopyrightText:         noAssertion,
+			PrimaryPackagePurpose: "APPLICATION",
+		}
+		if c.Supplier != "" {
+			pkg.Supplier = "Organization: " + c.Supplier
+		}
+		if c.SHA256 != "" {
+			pkg.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: c.SHA256}}
+		}
+		if c.InstallPath != "" {
+			pkg.Comment = "InstallLocation: " + c.InstallPath
+		}
+		if cpe := c.CPE(); cpe != "" {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "SECURITY",
+				ReferenceType:     "cpe23Type",
+				ReferenceLocator:  cpe,
+			})
+		}
+		if purl := c.PURL(); purl != "" {
+			pkg.ExternalRefs = append(pkg.ExternalRefs, spdxExternalRef{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  purl,
+			})
+		}
+
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(doc)
+}
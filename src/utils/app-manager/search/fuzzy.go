@@ -0,0 +1,78 @@
+package search
+
+import "unicode/utf8"
+
+// 查询越长允许的拼写错误越多，太短的查询不做模糊匹配
+func maxTypos(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	case n < 10:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// 与整段文本、其中的单词或同样长度的开头部分比较，返回最高的相似度（0~1），
+// 编辑距离超过允许的拼写错误数时返回0
+func (t *text) fuzzySimilarity(q string) float64 {
+	qr := []rune(q)
+	limit := maxTypos(len(qr))
+	if limit == 0 {
+		return 0
+	}
+
+	candidates := append([]string{t.lower}, t.words...)
+	if lower := []rune(t.lower); len(lower) > len(qr) {
+		candidates = append(candidates, string(lower[:len(qr)]))
+	}
+
+	best := limit + 1
+	for _, c := range candidates {
+		// 长度相差过大时编辑距离必然超过限制
+		if diff := utf8.RuneCountInString(c) - len(qr); diff > limit || -diff > limit {
+			continue
+		}
+		if d := Distance(q, c); d < best {
+			best = d
+		}
+	}
+	if best > limit {
+		return 0
+	}
+	return 1 - float64(best)/float64(len(qr))
+}
+
+// Distance 返回两个字符串的 Damerau-Levenshtein 距离（限制转置版本），
+// 插入、删除、替换和相邻字符交换各计为一次编辑
+func Distance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	n, m := len(ar), len(br)
+
+	// 只保留最近三行
+	prev2 := make([]int, m+1)
+	prev := make([]int, m+1)
+	cur := make([]int, m+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= n; i++ {
+		cur[0] = i
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[m]
+}
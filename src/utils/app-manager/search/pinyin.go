@@ -0,0 +1,120 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+var pinyinArgs = pinyin.Args{Style: pinyin.Normal, Heteronym: true}
+
+// 返回汉字的所有读音（不带声调），非汉字返回 nil
+func pinyinOf(r rune) []string {
+	if !unicode.Is(unicode.Han, r) {
+		return nil
+	}
+	return pinyin.SinglePinyin(r, pinyinArgs)
+}
+
+// 拼音的匹配方式
+type pinyinMode int
+
+const (
+	// 每个汉字使用完整拼音，只有最后一个字可以只输入开头几个字母
+	modeFull pinyinMode = iota
+	// 每个汉字只使用首字母
+	modeInitials
+	// 每个汉字可以使用拼音的任意前缀，如 "weix"、"wxin"
+	modeMixed
+)
+
+// 使用多音字的其他读音才能匹配时扣分，避免罕见读音带来的误匹配排在前面
+const penaltyHeteronym = 0.15
+
+// 在文本的任意位置查找与查询连续匹配的一段汉字，优先使用每个字的常用读音
+func (t *text) pinyinScore(q string) (float64, string) {
+	modes := []struct {
+		mode  pinyinMode
+		score float64
+		kind  string
+	}{
+		{modeFull, scorePinyin, KindPinyin},
+		{modeInitials, scoreInitials, KindInitials},
+		{modeMixed, scoreMixed, KindMixed},
+	}
+
+	for _, heteronym := range []bool{false, true} {
+		for _, m := range modes {
+			for start := range t.syllables {
+				if !t.matchPinyin(start, q, m.mode, heteronym, make(map[[2]int]bool)) {
+					continue
+				}
+				score := m.score
+				if start == 0 {
+					score += bonusLeading
+				}
+				if heteronym {
+					score -= penaltyHeteronym
+				}
+				return score, m.kind
+			}
+		}
+	}
+	return 0, ""
+}
+
+// 判断从第 ci 个字符开始能否匹配完剩余的查询 rest，heteronym 为 false 时只使用常用读音
+func (t *text) matchPinyin(ci int, rest string, mode pinyinMode, heteronym bool, failed map[[2]int]bool) bool {
+	if rest == "" {
+		return true
+	}
+	if ci >= len(t.syllables) {
+		return false
+	}
+	key := [2]int{ci, len(rest)}
+	if failed[key] {
+		return false
+	}
+
+	readings := t.syllables[ci]
+	if !heteronym {
+		readings = readings[:1]
+	}
+	for _, syl := range readings {
+		if !t.han[ci] {
+			// 非汉字必须逐字匹配
+			if strings.HasPrefix(rest, syl) && t.matchPinyin(ci+1, rest[len(syl):], mode, heteronym, failed) {
+				return true
+			}
+			continue
+		}
+
+		switch mode {
+		case modeFull:
+			if strings.HasPrefix(rest, syl) && t.matchPinyin(ci+1, rest[len(syl):], mode, heteronym, failed) {
+				return true
+			}
+			// 查询在这个字的拼音中间结束
+			if len(rest) < len(syl) && strings.HasPrefix(syl, rest) {
+				return true
+			}
+		case modeInitials:
+			if rest[0] == syl[0] && t.matchPinyin(ci+1, rest[1:], mode, heteronym, failed) {
+				return true
+			}
+		case modeMixed:
+			for n := 1; n <= len(syl) && n <= len(rest); n++ {
+				if rest[:n] != syl[:n] {
+					break
+				}
+				if t.matchPinyin(ci+1, rest[n:], mode, heteronym, failed) {
+					return true
+				}
+			}
+		}
+	}
+
+	failed[key] = true
+	return false
+}
@@ -0,0 +1,203 @@
+// Package search 按名称和发布者搜索应用，支持中文的全拼和首字母（如 "weixin"、
+// "wx" 都能找到"微信"）以及允许少量拼写错误的模糊匹配。
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 匹配方式
+const (
+	KindExact     = "exact"
+	KindPrefix    = "prefix"
+	KindSubstring = "substring"
+	KindPinyin    = "pinyin"
+	KindInitials  = "initials"
+	KindMixed     = "mixed" // 拼音和首字母混合，如 "wxin"
+	KindFuzzy     = "fuzzy"
+)
+
+// 各匹配方式的基础分数，匹配位置在开头时拼音类匹配额外加分
+const (
+	scoreExact     = 1.0
+	scorePrefix    = 0.9
+	scoreSubstring = 0.8
+	scorePinyin    = 0.7
+	scoreInitials  = 0.65
+	scoreMixed     = 0.6
+	scoreFuzzy     = 0.5
+	bonusLeading   = 0.1
+)
+
+// 发布者匹配的分数低于名称匹配
+const publisherWeight = 0.8
+
+// Document 是可搜索的条目
+type Document struct {
+	Name      string
+	Publisher string
+}
+
+// Match 是一个搜索结果
+type Match struct {
+	Index int     `json:"-"`
+	Score float64 `json:"score"`
+	Field string  `json:"field"` // DisplayName 或 Publisher
+	Kind  string  `json:"kind"`
+}
+
+// Index 预先计算好拼音的文档集合
+type Index struct {
+	docs      []Document
+	names     []*text
+	publisher []*text
+}
+
+// NewIndex 为文档建立索引
+func NewIndex(docs []Document) *Index {
+	idx := &Index{
+		docs:      docs,
+		names:     make([]*text, len(docs)),
+		publisher: make([]*text, len(docs)),
+	}
+	for i, d := range docs {
+		idx.names[i] = newText(d.Name)
+		idx.publisher[i] = newText(d.Publisher)
+	}
+	return idx
+}
+
+// Search 返回分数不低于 minScore 的结果，按分数从高到低排列，limit 为0时不限制数量
+func (idx *Index) Search(query string, minScore float64, limit int) []Match {
+	q := newQuery(query)
+	if q.empty() {
+		return []Match{}
+	}
+
+	matches := []Match{}
+	for i := range idx.docs {
+		best := Match{Index: i}
+		if score, kind := idx.names[i].score(q); score > 0 {
+			best.Score, best.Field, best.Kind = score, "DisplayName", kind
+		}
+		if score, kind := idx.publisher[i].score(q); score*publisherWeight > best.Score {
+			best.Score, best.Field, best.Kind = score*publisherWeight, "Publisher", kind
+		}
+		if best.Score > 0 && best.Score >= minScore {
+			matches = append(matches, best)
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		// 分数相同时名称较短的更可能是用户要找的
+		na, nb := idx.docs[matches[a].Index].Name, idx.docs[matches[b].Index].Name
+		if len(na) != len(nb) {
+			return len(na) < len(nb)
+		}
+		return na < nb
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Score 计算查询与一段文本的匹配分数（0~1），不匹配时返回0
+func Score(query, s string) (float64, string) {
+	q := newQuery(query)
+	if q.empty() {
+		return 0, ""
+	}
+	return newText(s).score(q)
+}
+
+type query struct {
+	lower   string // 小写，去掉首尾空白
+	compact string // 去掉空白，用于拼音匹配
+}
+
+func newQuery(s string) *query {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	return &query{
+		lower:   lower,
+		compact: strings.Join(strings.Fields(lower), ""),
+	}
+}
+
+func (q *query) empty() bool {
+	return q.lower == ""
+}
+
+// text 是预处理过的待匹配文本
+type text struct {
+	lower string
+	words []string
+	// 每个字符的读音，汉字为拼音（多音字有多个），其他字符为其小写形式，空白被忽略
+	syllables [][]string
+	// 对应位置的字符是否为有拼音的汉字
+	han    []bool
+	hasHan bool
+}
+
+func newText(s string) *text {
+	t := &text{lower: strings.ToLower(strings.TrimSpace(s))}
+	t.words = strings.FieldsFunc(t.lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, r := range t.lower {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		py := pinyinOf(r)
+		han := len(py) > 0
+		if !han {
+			py = []string{string(r)}
+		}
+		t.syllables = append(t.syllables, py)
+		t.han = append(t.han, han)
+		t.hasHan = t.hasHan || han
+	}
+	return t
+}
+
+func (t *text) score(q *query) (float64, string) {
+	if t.lower == "" {
+		return 0, ""
+	}
+
+	switch {
+	case t.lower == q.lower:
+		return scoreExact, KindExact
+	case strings.HasPrefix(t.lower, q.lower):
+		return scorePrefix, KindPrefix
+	case strings.Contains(t.lower, q.lower):
+		return scoreSubstring, KindSubstring
+	}
+
+	if t.hasHan && isLatin(q.compact) {
+		if score, kind := t.pinyinScore(q.compact); score > 0 {
+			return score, kind
+		}
+	}
+
+	if similarity := t.fuzzySimilarity(q.lower); similarity > 0 {
+		return scoreFuzzy * similarity, KindFuzzy
+	}
+	return 0, ""
+}
+
+// 查询只包含ASCII字母和数字时才尝试拼音匹配
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
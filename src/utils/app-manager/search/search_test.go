@@ -0,0 +1,120 @@
+package search
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"chrome", "chrome", 0},
+		{"chorme", "chrome", 1}, // 相邻字符交换
+		{"chrme", "chrome", 1},
+		{"chromee", "chrome", 1},
+		{"crome", "chrome", 1},
+		{"firefax", "firefox", 1},
+		{"ca", "abc", 3},
+		{"微心", "微信", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		query, text string
+		kind        string
+	}{
+		{"微信", "微信", KindExact},
+		{"WeChat", "wechat", KindExact},
+		{"google", "Google Chrome", KindPrefix},
+		{"chrome", "Google Chrome", KindSubstring},
+		{"weixin", "微信", KindPinyin},
+		{"wei xin", "微信", KindPinyin},
+		{"weix", "微信", KindPinyin},
+		{"wx", "微信", KindInitials},
+		{"wxin", "微信", KindMixed},
+		{"txhy", "腾讯会议", KindInitials},
+		{"huiyi", "腾讯会议", KindPinyin},
+		{"qqyy", "QQ音乐", KindInitials},
+		{"qqyinyue", "QQ音乐", KindPinyin},
+		{"yinhang", "招商银行", KindPinyin}, // 多音字 行
+		{"chorme", "Google Chrome", KindFuzzy},
+		{"visaul studio", "Visual Studio Code", KindFuzzy},
+		{"notepad+", "Notepad++", KindPrefix},
+	}
+	for _, tt := range tests {
+		score, kind := Score(tt.query, tt.text)
+		if kind != tt.kind || score <= 0 || score > 1 {
+			t.Errorf("Score(%q, %q) = %.2f %s, want %s", tt.query, tt.text, score, kind, tt.kind)
+		}
+	}
+
+	for _, tt := range []struct{ query, text string }{
+		{"xw", "微信"},
+		{"wxx", "微信"},
+		{"zoom", "Google Chrome"},
+		{"ab", "ac"}, // 太短，不做模糊匹配
+		{"", "微信"},
+	} {
+		if score, kind := Score(tt.query, tt.text); score != 0 {
+			t.Errorf("Score(%q, %q) = %.2f %s, want no match", tt.query, tt.text, score, kind)
+		}
+	}
+}
+
+func TestScoreOrdering(t *testing.T) {
+	exact, _ := Score("weixin", "weixin")
+	leading, _ := Score("weixin", "微信")
+	inner, _ := Score("weixin", "企业微信")
+	fuzzy, _ := Score("weixim", "weixin tools")
+	if !(exact > leading && leading > inner && inner > fuzzy) {
+		t.Errorf("Unexpected ordering: exact %.2f, leading %.2f, inner %.2f, fuzzy %.2f", exact, leading, inner, fuzzy)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := NewIndex([]Document{
+		{Name: "企业微信", Publisher: "腾讯科技(深圳)有限公司"},
+		{Name: "微信", Publisher: "腾讯科技(深圳)有限公司"},
+		{Name: "Google Chrome", Publisher: "Google LLC"},
+		{Name: "腾讯会议", Publisher: "腾讯科技(深圳)有限公司"},
+		{Name: "Wireshark 4.2.3 x64", Publisher: "The Wireshark developer community"},
+	})
+
+	// 有限公司 中 有(wei) 限(xian) 为罕见读音，分数低于 minScore
+	matches := idx.Search("wx", 0.5, 0)
+	if len(matches) != 2 || matches[0].Index != 1 || matches[1].Index != 0 {
+		t.Errorf("Search(wx) = %+v, want 微信 before 企业微信", matches)
+	}
+
+	// 只匹配发布者时分数降低
+	matches = idx.Search("tengxun", 0, 0)
+	if len(matches) != 3 {
+		t.Fatalf("Search(tengxun) = %+v", matches)
+	}
+	if matches[0].Index != 3 || matches[0].Field != "DisplayName" {
+		t.Errorf("Expected 腾讯会议 by name first, got %+v", matches[0])
+	}
+	if matches[1].Field != "Publisher" || matches[1].Score >= matches[0].Score {
+		t.Errorf("Expected publisher matches to score lower, got %+v", matches)
+	}
+
+	if matches := idx.Search("tengxun", 0, 2); len(matches) != 2 {
+		t.Errorf("Expected limit to apply, got %d matches", len(matches))
+	}
+	if matches := idx.Search("chorme", 0.4, 0); len(matches) != 1 || matches[0].Kind != KindFuzzy {
+		t.Errorf("Search(chorme) = %+v", matches)
+	}
+	if matches := idx.Search("chorme", 0.45, 0); len(matches) != 0 {
+		t.Errorf("Expected min score to filter fuzzy match, got %+v", matches)
+	}
+	if matches := idx.Search("  ", 0, 0); matches == nil || len(matches) != 0 {
+		t.Errorf("Expected empty result for blank query, got %+v", matches)
+	}
+}
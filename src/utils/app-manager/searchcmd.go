@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/search"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+)
+
+type SearchMatch struct {
+	App App `json:"app"`
+	search.Match
+}
+
+type SearchResult struct {
+	Success bool          `json:"success"`
+	Query   string        `json:"query"`
+	Matches []SearchMatch `json:"matches"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// 按名称和发布者搜索应用，支持拼音和模糊匹配
+func searchApps(apps []App, q string, minScore float64, limit int) *SearchResult {
+	docs := make([]search.Document, len(apps))
+	for i, app := range apps {
+		docs[i] = search.Document{Name: app.DisplayName, Publisher: app.Publisher}
+	}
+
+	result := &SearchResult{
+		Success: true,
+		Query:   q,
+		Matches: []SearchMatch{},
+	}
+	for _, m := range search.NewIndex(docs).Search(q, minScore, limit) {
+		result.Matches = append(result.Matches, SearchMatch{App: apps[m.Index], Match: m})
+	}
+	return result
+}
+
+func writeSearchResult(w io.Writer, result *SearchResult, format string) error {
+	switch format {
+	case "text":
+		t := &table.Table{
+			Header: []string{"名称", "版本", "发布者", "分数", "匹配"},
+			Align:  []table.Align{3: table.AlignRight},
+		}
+		if f, ok := w.(*os.File); ok {
+			t.MaxWidth = consoleWidth(f)
+		}
+		for _, m := range result.Matches {
+			t.Rows = append(t.Rows, []string{m.App.DisplayName, m.App.DisplayVersion, m.App.Publisher,
+				fmt.Sprintf("%.2f", m.Score), m.Kind})
+		}
+		return t.Render(w)
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/diskusage"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+// 缓存的最长有效期，目录修改时间只反映直接子项的变化
+const sizeCacheMaxAge = 7 * 24 * time.Hour
+
+func sizeCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "appman", "sizes.json")
+}
+
+// 统计各应用安装目录的实际占用空间，结果缓存在用户缓存目录中
+func measureAppSizes(apps []inventory.App, workers int) {
+	opts := []inventory.MeasureOption{inventory.WithWorkers(workers)}
+	var cache *diskusage.Cache
+	if path := sizeCachePath(); path != "" {
+		cache = diskusage.OpenCache(path, sizeCacheMaxAge)
+		opts = append(opts, inventory.WithSizeCache(cache))
+	}
+	inventory.MeasureSizes(apps, opts...)
+	if cache != nil {
+		// 缓存写入失败不影响结果
+		cache.Save()
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// 快照文件格式版本，结构发生不兼容变化时递增
+const snapshotVersion = 1
+
+type MachineInfo struct {
+	Hostname  string `json:"hostname"`
+	User      string `json:"user,omitempty"`
+	OSVersion string `json:"osVersion"`
+	Arch      string `json:"arch"`
+}
+
+// Snapshot 在 export 输出的基础上附加时间和机器信息，因此 export 的结果也可以直接用于 diff
+type Snapshot struct {
+	Version   int         `json:"snapshotVersion"`
+	Timestamp time.Time   `json:"timestamp"`
+	Machine   MachineInfo `json:"machine"`
+	Result
+}
+
+// snapshot save 的输出
+type SnapshotSaved struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+type AppChange struct {
+	DisplayName string `json:"DisplayName"`
+	OldVersion  string `json:"oldVersion"`
+	NewVersion  string `json:"newVersion"`
+	OldSize     uint32 `json:"oldSize"`
+	NewSize     uint32 `json:"newSize"`
+}
+
+// SizeDelta 返回大小变化（KB）
+func (c AppChange) SizeDelta() int64 {
+	return int64(c.NewSize) - int64(c.OldSize)
+}
+
+// SnapshotHeader 是快照的摘要信息，用于在diff结果中标识两侧快照
+type SnapshotHeader struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Machine   MachineInfo `json:"machine"`
+	AppCount  int         `json:"appCount"`
+}
+
+type SnapshotDiff struct {
+	From       SnapshotHeader `json:"from"`
+	To         SnapshotHeader `json:"to"`
+	Added      []App          `json:"added"`
+	Removed    []App          `json:"removed"`
+	Upgraded   []AppChange    `json:"upgraded"`
+	Downgraded []AppChange    `json:"downgraded"`
+	Resized    []AppChange    `json:"resized"`
+	SizeDelta  int64          `json:"sizeDelta"`
+}
+
+// 获取当前机器的基本信息
+func getMachineInfo() MachineInfo {
+	hostname, _ := os.Hostname()
+	v := windows.RtlGetVersion()
+
+	return MachineInfo{
+		Hostname:  hostname,
+		User:      os.Getenv("USERNAME"),
+		OSVersion: fmt.Sprintf("%d.%d.%d", v.MajorVersion, v.MinorVersion, v.BuildNumber),
+		Arch:      runtime.GOARCH,
+	}
+}
+
+// 生成当前已安装应用的快照
+func takeSnapshot() (*Snapshot, error) {
+	result, err := getAllApps()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Version:   snapshotVersion,
+		Timestamp: time.Now(),
+		Machine:   getMachineInfo(),
+		Result:    *result,
+	}, nil
+}
+
+func saveSnapshot(snapshot *Snapshot, path string) error {
+	jsonData, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, jsonData, 0644)
+}
+
+func loadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("无法解析快照文件 %s: %v", path, err)
+	}
+	return &snapshot, nil
+}
+
+// 比较两个快照，应用按DisplayName对应，与getAllApps的去重规则一致
+func diffSnapshots(from, to *Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		From:       from.header(),
+		To:         to.header(),
+		Added:      []App{},
+		Removed:    []App{},
+		Upgraded:   []AppChange{},
+		Downgraded: []AppChange{},
+		Resized:    []AppChange{},
+	}
+
+	oldApps := make(map[string]App, len(from.Apps))
+	for _, app := range from.Apps {
+		oldApps[app.DisplayName] = app
+	}
+	newApps := make(map[string]App, len(to.Apps))
+	for _, app := range to.Apps {
+		newApps[app.DisplayName] = app
+	}
+
+	for _, app := range to.Apps {
+		old, exists := oldApps[app.DisplayName]
+		if !exists {
+			diff.Added = append(diff.Added, app)
+			diff.SizeDelta += int64(app.EstimatedSize)
+			continue
+		}
+
+		change := AppChange{
+			DisplayName: app.DisplayName,
+			OldVersion:  old.DisplayVersion,
+			NewVersion:  app.DisplayVersion,
+			OldSize:     old.EstimatedSize,
+			NewSize:     app.EstimatedSize,
+		}
+		diff.SizeDelta += change.SizeDelta()
+
+		switch cmp := compareVersions(app.DisplayVersion, old.DisplayVersion); {
+		case cmp > 0:
+			diff.Upgraded = append(diff.Upgraded, change)
+		case cmp < 0:
+			diff.Downgraded = append(diff.Downgraded, change)
+		case change.SizeDelta() != 0:
+			diff.Resized = append(diff.Resized, change)
+		}
+	}
+
+	for _, app := range from.Apps {
+		if _, exists := newApps[app.DisplayName]; !exists {
+			diff.Removed = append(diff.Removed, app)
+			diff.SizeDelta -= int64(app.EstimatedSize)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].DisplayName < diff.Added[j].DisplayName })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].DisplayName < diff.Removed[j].DisplayName })
+
+	return diff
+}
+
+// 格式化大小变化，EstimatedSize的单位为KB
+func formatSizeDelta(kb int64) string {
+	sign := "+"
+	if kb < 0 {
+		sign = "-"
+		kb = -kb
+	}
+	switch {
+	case kb >= 1024*1024:
+		return fmt.Sprintf("%s%.1f GB", sign, float64(kb)/1024/1024)
+	case kb >= 1024:
+		return fmt.Sprintf("%s%.1f MB", sign, float64(kb)/1024)
+	default:
+		return fmt.Sprintf("%s%d KB", sign, kb)
+	}
+}
+
+func (s *Snapshot) header() SnapshotHeader {
+	return SnapshotHeader{
+		Timestamp: s.Timestamp,
+		Machine:   s.Machine,
+		AppCount:  len(s.Apps),
+	}
+}
+
+func describeSnapshot(h SnapshotHeader) string {
+	// 普通export输出没有时间和机器信息
+	if h.Timestamp.IsZero() {
+		return fmt.Sprintf("%d 个应用", h.AppCount)
+	}
+	return fmt.Sprintf("%s @ %s, %d 个应用", h.Machine.Hostname, h.Timestamp.Format("2006-01-02 15:04:05"), h.AppCount)
+}
+
+func writeDiffText(w io.Writer, diff *SnapshotDiff) {
+	fmt.Fprintf(w, "旧: %s\n", describeSnapshot(diff.From))
+	fmt.Fprintf(w, "新: %s\n\n", describeSnapshot(diff.To))
+
+	for _, app := range diff.Added {
+		fmt.Fprintf(w, "+ %s %s\n", app.DisplayName, app.DisplayVersion)
+	}
+	for _, app := range diff.Removed {
+		fmt.Fprintf(w, "- %s %s\n", app.DisplayName, app.DisplayVersion)
+	}
+	for _, c := range diff.Upgraded {
+		fmt.Fprintf(w, "↑ %s %s -> %s (%s)\n", c.DisplayName, c.OldVersion, c.NewVersion, formatSizeDelta(c.SizeDelta()))
+	}
+	for _, c := range diff.Downgraded {
+		fmt.Fprintf(w, "↓ %s %s -> %s (%s)\n", c.DisplayName, c.OldVersion, c.NewVersion, formatSizeDelta(c.SizeDelta()))
+	}
+	for _, c := range diff.Resized {
+		fmt.Fprintf(w, "~ %s %s (%s)\n", c.DisplayName, c.NewVersion, formatSizeDelta(c.SizeDelta()))
+	}
+
+	fmt.Fprintf(w, "\n新增 %d, 删除 %d, 升级 %d, 降级 %d, 大小变化 %d, 总大小变化 %s\n",
+		len(diff.Added), len(diff.Removed), len(diff.Upgraded), len(diff.Downgraded), len(diff.Resized),
+		formatSizeDelta(diff.SizeDelta))
+}
+
+// 转义Markdown表格单元格中的特殊字符
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func writeDiffMarkdown(w io.Writer, diff *SnapshotDiff) {
+	fmt.Fprintf(w, "# 应用变更\n\n")
+	fmt.Fprintf(w, "- 旧: %s\n", escapeMarkdownCell(describeSnapshot(diff.From)))
+	fmt.Fprintf(w, "- 新: %s\n", escapeMarkdownCell(describeSnapshot(diff.To)))
+	fmt.Fprintf(w, "- 总大小变化: %s\n", formatSizeDelta(diff.SizeDelta))
+
+	writeAppSection := func(title string, apps []App) {
+		if len(apps) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n## %s (%d)\n\n", title, len(apps))
+		fmt.Fprintf(w, "| 名称 | 版本 | 发布者 |\n|---|---|---|\n")
+		for _, app := range apps {
+			fmt.Fprintf(w, "| %s | %s | %s |\n",
+				escapeMarkdownCell(app.DisplayName), escapeMarkdownCell(app.DisplayVersion), escapeMarkdownCell(app.Publisher))
+		}
+	}
+	writeChangeSection := func(title string, changes []AppChange) {
+		if len(changes) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n## %s (%d)\n\n", title, len(changes))
+		fmt.Fprintf(w, "| 名称 | 旧版本 | 新版本 | 大小变化 |\n|---|---|---|---|\n")
+		for _, c := range changes {
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n",
+				escapeMarkdownCell(c.DisplayName), escapeMarkdownCell(c.OldVersion), escapeMarkdownCell(c.NewVersion),
+				formatSizeDelta(c.SizeDelta()))
+		}
+	}
+
+	writeAppSection("新增", diff.Added)
+	writeAppSection("删除", diff.Removed)
+	writeChangeSection("升级", diff.Upgraded)
+	writeChangeSection("降级", diff.Downgraded)
+	writeChangeSection("大小变化", diff.Resized)
+}
+
+func writeDiff(w io.Writer, diff *SnapshotDiff, format string) error {
+	switch format {
+	case "text":
+		writeDiffText(w, diff)
+	case "markdown", "md":
+		writeDiffMarkdown(w, diff)
+	case "json":
+		jsonData, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &Snapshot{Result: Result{Apps: []App{
+		{DisplayName: "7-Zip", DisplayVersion: "22.01", EstimatedSize: 5000},
+		{DisplayName: "Notepad++", DisplayVersion: "8.6", EstimatedSize: 4000},
+		{DisplayName: "OldTool", DisplayVersion: "1.0", EstimatedSize: 100},
+		{DisplayName: "VLC", DisplayVersion: "3.0.20", EstimatedSize: 150000},
+		{DisplayName: "Same", DisplayVersion: "1.0", EstimatedSize: 10},
+	}}}
+	to := &Snapshot{Result: Result{Apps: []App{
+		{DisplayName: "7-Zip", DisplayVersion: "23.01", EstimatedSize: 5500},
+		{DisplayName: "Notepad++", DisplayVersion: "8.5", EstimatedSize: 4000},
+		{DisplayName: "NewTool", DisplayVersion: "2.0", EstimatedSize: 300},
+		{DisplayName: "VLC", DisplayVersion: "3.0.20", EstimatedSize: 160000},
+		{DisplayName: "Same", DisplayVersion: "1.0", EstimatedSize: 10},
+	}}}
+
+	diff := diffSnapshots(from, to)
+
+	if len(diff.Added) != 1 || diff.Added[0].DisplayName != "NewTool" {
+		t.Errorf("Added = %+v, want NewTool", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].DisplayName != "OldTool" {
+		t.Errorf("Removed = %+v, want OldTool", diff.Removed)
+	}
+	if len(diff.Upgraded) != 1 || diff.Upgraded[0].DisplayName != "7-Zip" {
+		t.Errorf("Upgraded = %+v, want 7-Zip", diff.Upgraded)
+	}
+	if len(diff.Downgraded) != 1 || diff.Downgraded[0].DisplayName != "Notepad++" {
+		t.Errorf("Downgraded = %+v, want Notepad++", diff.Downgraded)
+	}
+	if len(diff.Resized) != 1 || diff.Resized[0].DisplayName != "VLC" {
+		t.Errorf("Resized = %+v, want VLC", diff.Resized)
+	}
+
+	// +500 (7-Zip) +300 (NewTool) -100 (OldTool) +10000 (VLC)
+	if diff.SizeDelta != 10700 {
+		t.Errorf("SizeDelta = %d, want 10700", diff.SizeDelta)
+	}
+}
+
+func TestWriteDiffFormats(t *testing.T) {
+	from := &Snapshot{Result: Result{Apps: []App{{DisplayName: "A|B", DisplayVersion: "1.0"}}}}
+	to := &Snapshot{Result: Result{Apps: []App{{DisplayName: "A|B", DisplayVersion: "2.0"}}}}
+	diff := diffSnapshots(from, to)
+
+	for _, format := range []string{"text", "json", "markdown"} {
+		var buf bytes.Buffer
+		if err := writeDiff(&buf, diff, format); err != nil {
+			t.Fatalf("writeDiff(%s) failed: %v", format, err)
+		}
+		if buf.Len() == 0 {
+			t.Errorf("writeDiff(%s) produced no output", format)
+		}
+	}
+
+	var buf bytes.Buffer
+	writeDiff(&buf, diff, "markdown")
+	if !strings.Contains(buf.String(), `A\|B`) {
+		t.Errorf("markdown output does not escape '|': %s", buf.String())
+	}
+
+	if err := writeDiff(&buf, diff, "xml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
@@ -0,0 +1,169 @@
+package table
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Align 是列的对齐方式
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// 列之间的空格数
+const gap = 2
+
+// 收缩列宽时每列至少保留的宽度（表头更窄时以表头为准）
+const minColumnWidth = 8
+
+// Table 是一个文本表格
+type Table struct {
+	Header []string
+	Rows   [][]string
+	// 每列的对齐方式，未指定的列左对齐
+	Align []Align
+	// 表格的最大总宽度，通常为控制台宽度；0 表示不限制。
+	// 超出时从最宽的列开始收缩，过长的单元格被截断
+	MaxWidth int
+}
+
+// Render 输出表格，每行末尾不留空格。单元格中的换行和制表符被替换为空格
+func (t *Table) Render(w io.Writer) error {
+	clean := &Table{Header: singleLine(t.Header), Align: t.Align, MaxWidth: t.MaxWidth}
+	for _, row := range t.Rows {
+		clean.Rows = append(clean.Rows, singleLine(row))
+	}
+	widths := clean.columnWidths()
+
+	if len(clean.Header) > 0 {
+		if err := clean.writeRow(w, clean.Header, widths); err != nil {
+			return err
+		}
+	}
+	for _, row := range clean.Rows {
+		if err := clean.writeRow(w, row, widths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var controlReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+func singleLine(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		out[i] = controlReplacer.Replace(c)
+	}
+	return out
+}
+
+func (t *Table) columns() int {
+	n := len(t.Header)
+	for _, row := range t.Rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	return n
+}
+
+func (t *Table) columnWidths() []int {
+	n := t.columns()
+	widths := make([]int, n)
+	minimums := make([]int, n)
+	for i, h := range t.Header {
+		widths[i] = Width(h)
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if w := Width(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	for i := range minimums {
+		minimums[i] = minColumnWidth
+		if i < len(t.Header) && Width(t.Header[i]) > minimums[i] {
+			minimums[i] = Width(t.Header[i])
+		}
+		if widths[i] < minimums[i] {
+			minimums[i] = widths[i]
+		}
+	}
+
+	if t.MaxWidth <= 0 || n == 0 {
+		return widths
+	}
+
+	total := gap * (n - 1)
+	for _, w := range widths {
+		total += w
+	}
+	// 每次收缩当前最宽且仍可收缩的列，直到放得下或无法再收缩
+	for total > t.MaxWidth {
+		widest := -1
+		for i, w := range widths {
+			if w > minimums[i] && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+func (t *Table) writeRow(w io.Writer, row []string, widths []int) error {
+	// 末尾的空单元格不需要补齐
+	last := len(row) - 1
+	for last >= 0 && row[last] == "" {
+		last--
+	}
+
+	var b strings.Builder
+	for i := 0; i <= last; i++ {
+		if i > 0 {
+			b.WriteString(strings.Repeat(" ", gap))
+		}
+		cell := Truncate(row[i], widths[i])
+		switch {
+		case i < len(t.Align) && t.Align[i] == AlignRight:
+			b.WriteString(PadLeft(cell, widths[i]))
+		case i == last:
+			b.WriteString(cell)
+		default:
+			b.WriteString(PadRight(cell, widths[i]))
+		}
+	}
+	_, err := fmt.Fprintln(w, b.String())
+	return err
+}
+
+// Box 输出带边框和标题的列表，用于让用户从多个条目中选择。
+// width 为包括边框在内的总宽度，过长的行被截断
+func Box(w io.Writer, title string, lines []string, width int) error {
+	inner := width - 4 // "| " 和 " |"
+	if inner < 1 {
+		inner = 1
+	}
+
+	var b strings.Builder
+	// 上边框为 "+--- " + 标题 + " " + 填充 + "+"，标题最多占 width-7 列
+	head := "--- " + Truncate(title, inner-3) + " "
+	b.WriteString("+" + head + strings.Repeat("-", max(0, width-2-Width(head))) + "+\n")
+	for _, line := range lines {
+		b.WriteString("| " + PadRight(Truncate(line, inner), inner) + " |\n")
+	}
+	b.WriteString("+" + strings.Repeat("-", width-2) + "+\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,182 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"7-Zip", 5},
+		{"微信", 4},
+		{"ＷｅＣｈａｔ", 12},                    // 全角字母
+		{"é", 1},                         // 组合字符
+		{"한국어", 6},                        // 谚文
+		{"👨‍👩‍👧", 2},                      // ZWJ表情序列
+		{"Microsoft Visual C++ 2015", 25}, // 纯ASCII
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := Width(tt.s); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestAmbiguousWidth(t *testing.T) {
+	defer SetAmbiguousWide(false)
+
+	if got := Width("①±"); got != 2 {
+		t.Errorf("Width of ambiguous characters = %d, want 2", got)
+	}
+	SetAmbiguousWide(true)
+	if got := Width("①±"); got != 4 {
+		t.Errorf("Width of ambiguous characters in wide mode = %d, want 4", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"7-Zip 23.01", 20, "7-Zip 23.01"},
+		{"7-Zip 23.01 (x64)", 10, "7-Zip 2..."},
+		{"腾讯科技(深圳)有限公司", 10, "腾讯科..."},  // 第四个汉字放不下
+		{"腾讯科技(深圳)有限公司", 11, "腾讯科技..."}, // 4个汉字占8列
+		{"Café au lait", 7, "Café..."},
+		{"👨‍👩‍👧👨‍👩‍👧👨‍👩‍👧", 5, "👨‍👩‍👧..."},
+		{"微信", 2, ".."},
+		{"微信", 0, ""},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) produced invalid UTF-8", tt.s, tt.width)
+		}
+		if Width(got) > tt.width {
+			t.Errorf("Truncate(%q, %d) is %d columns wide", tt.s, tt.width, Width(got))
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tbl := &Table{
+		Header: []string{"名称", "版本", "大小"},
+		Rows: [][]string{
+			{"微信", "3.9.10", "512"},
+			{"7-Zip 23.01 (x64)", "23.01", "5"},
+		},
+		Align: []Align{AlignLeft, AlignLeft, AlignRight},
+	}
+
+	var buf bytes.Buffer
+	if err := tbl.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"名称               版本    大小\n" +
+		"微信               3.9.10   512\n" +
+		"7-Zip 23.01 (x64)  23.01      5\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected table:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestRenderMaxWidth(t *testing.T) {
+	tbl := &Table{
+		Header: []string{"DisplayName", "Publisher"},
+		Rows: [][]string{
+			{"Microsoft Visual C++ 2015-2022 Redistributable (x64)", "Microsoft Corporation"},
+			{"腾讯会议", "腾讯科技(深圳)有限公司"},
+			{"Git", ""},
+		},
+		MaxWidth: 40,
+	}
+
+	var buf bytes.Buffer
+	if err := tbl.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got:\n%s", buf.String())
+	}
+
+	column := Width("DisplayName") + strings.Index(lines[0][len("DisplayName"):], "Publisher")
+	for _, line := range lines {
+		if Width(line) > 40 {
+			t.Errorf("Line exceeds max width: %q (%d)", line, Width(line))
+		}
+		if strings.HasSuffix(line, " ") {
+			t.Errorf("Line has trailing spaces: %q", line)
+		}
+	}
+	// 第二列在所有行中起始于同一显示列
+	for _, line := range lines[1:3] {
+		runes := []rune(line)
+		prefix := ""
+		for i := range runes {
+			if Width(string(runes[:i])) == column {
+				prefix = string(runes[:i])
+				break
+			}
+		}
+		if prefix == "" || !strings.HasSuffix(prefix, "  ") {
+			t.Errorf("Second column is misaligned in %q", line)
+		}
+	}
+	if !strings.Contains(lines[1], Ellipsis) {
+		t.Errorf("Expected long name to be truncated: %q", lines[1])
+	}
+}
+
+func TestBox(t *testing.T) {
+	var buf bytes.Buffer
+	err := Box(&buf, "搜索到 2 个匹配程序", []string{
+		" 1) 微信",
+		" 2) 腾讯会议 Tencent Meeting 国际版 长名称会被截断",
+	}, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for _, line := range lines {
+		if Width(line) != 30 {
+			t.Errorf("Box line is %d columns wide, want 30: %q", Width(line), line)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "+--- 搜索到 2 个匹配程序 ") || !strings.Contains(lines[2], Ellipsis) {
+		t.Errorf("Unexpected box:\n%s", buf.String())
+	}
+}
+
+func TestBoxLongTitle(t *testing.T) {
+	for _, title := range []string{
+		"Found 12 matching applications for the given name",
+		"搜索到 12 个匹配程序，请复制完整名称后重新运行",
+	} {
+		var buf bytes.Buffer
+		if err := Box(&buf, title, []string{"1) 7-Zip"}, 30); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			if Width(line) != 30 {
+				t.Errorf("Box line is %d columns wide, want 30: %q", Width(line), line)
+			}
+		}
+		if !strings.Contains(buf.String(), Ellipsis) {
+			t.Errorf("Expected truncated title:\n%s", buf.String())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package table 在终端中输出对齐的表格。
+//
+// 宽度按显示列数而不是字节数计算：中日韩文字和全角符号占两列，组合字符
+// 不占列，截断只发生在字素簇（grapheme cluster）边界上，不会切开一个字符。
+package table
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Ellipsis 是截断时追加的省略号，使用ASCII字符以免在不同终端中宽度不一致
+const Ellipsis = "..."
+
+// SetAmbiguousWide 设置东亚宽度为"模糊"的字符（如 ①、±、希腊字母）是否按两列计算。
+// 使用中文传统控制台字体时这些字符通常显示为两列，默认按一列计算
+func SetAmbiguousWide(wide bool) {
+	if wide {
+		uniseg.EastAsianAmbiguousWidth = 2
+	} else {
+		uniseg.EastAsianAmbiguousWidth = 1
+	}
+}
+
+// Width 返回字符串的显示宽度
+func Width(s string) int {
+	return uniseg.StringWidth(s)
+}
+
+// Truncate 把字符串截断到不超过 width 列，被截断时以省略号结尾
+func Truncate(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	ellipsis := Ellipsis
+	if width < len(Ellipsis) {
+		ellipsis = Ellipsis[:width]
+	}
+	limit := width - len(ellipsis)
+
+	var b strings.Builder
+	used := 0
+	state := -1
+	rest := s
+	for len(rest) > 0 {
+		var cluster string
+		var w int
+		cluster, rest, w, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if used+w > limit {
+			break
+		}
+		b.WriteString(cluster)
+		used += w
+	}
+	b.WriteString(ellipsis)
+	return b.String()
+}
+
+// PadRight 在右侧补空格到 width 列，超出时不截断
+func PadRight(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// PadLeft 在左侧补空格到 width 列，超出时不截断
+func PadLeft(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
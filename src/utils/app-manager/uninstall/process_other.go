@@ -0,0 +1,32 @@
+//go:build !windows
+
+package uninstall
+
+import (
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+func systemDir() string {
+	return ""
+}
+
+func windowsDir() string {
+	return ""
+}
+
+// 其他系统上以 SIGTERM 请求进程退出
+func closeGracefully(pid int32) error {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Terminate()
+}
+
+func run(app *inventory.App, action, cmd string, args []string, o *options) *Result {
+	result := &Result{Command: commandLine(cmd, args)}
+	return result.fail(errcode.New(errcode.UnsupportedPlatform, errcode.Params{"action": action}))
+}
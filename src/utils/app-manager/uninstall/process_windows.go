@@ -0,0 +1,246 @@
+package uninstall
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"golang.org/x/sys/windows"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+// STILL_ACTIVE is the exit code that indicates a process is still running
+const STILL_ACTIVE uint32 = 259
+
+func systemDir() string {
+	dir, err := windows.GetSystemDirectory()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+func windowsDir() string {
+	dir, err := windows.GetWindowsDirectory()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+var procPostMessageW = windows.NewLazySystemDLL("user32.dll").NewProc("PostMessageW")
+
+const wmClose = 0x0010
+
+// EnumWindows 的回调。Windows 限制回调的数量，因此只创建一次，用 closeMu 保护当前要关闭的进程
+var (
+	closeMu     sync.Mutex
+	closePid    uint32
+	closeFound  bool
+	closeWindow = sync.OnceValue(func() uintptr {
+		return windows.NewCallback(func(hwnd windows.HWND, _ uintptr) uintptr {
+			var pid uint32
+			if _, err := windows.GetWindowThreadProcessId(hwnd, &pid); err == nil && pid == closePid {
+				procPostMessageW.Call(uintptr(hwnd), wmClose, 0, 0)
+				closeFound = true
+			}
+			return 1 // 继续枚举
+		})
+	})
+)
+
+// 向进程的所有顶层窗口发送 WM_CLOSE，与用户点击关闭按钮相同。没有窗口的进程无法正常关闭
+func closeGracefully(pid int32) error {
+	closeMu.Lock()
+	defer closeMu.Unlock()
+
+	closePid, closeFound = uint32(pid), false
+	if err := windows.EnumWindows(closeWindow(), nil); err != nil {
+		return err
+	}
+	if !closeFound {
+		return fmt.Errorf("进程没有窗口")
+	}
+	return nil
+}
+
+// 启动进程并等待其结束，返回PowerShell的PID和安装程序的退出码
+func startProcess(cmd string, args []string) (int, int, error) {
+	// 处理命令路径，使用单引号包裹
+	cmdPath := fmt.Sprintf("'%s'", strings.Trim(cmd, `"`))
+
+	// 构建PowerShell命令
+	var psCmd string
+	if len(args) > 0 {
+		// 如果有参数，添加-ArgumentList
+		argsStr := fmt.Sprintf("'%s'", strings.Join(args, " "))
+		psCmd = fmt.Sprintf(`Start-Process -FilePath %s -ArgumentList %s -Verb RunAs -Wait -PassThru`, cmdPath, argsStr)
+	} else {
+		// 如果没有参数，不添加-ArgumentList
+		psCmd = fmt.Sprintf(`Start-Process -FilePath %s -Verb RunAs -Wait -PassThru`, cmdPath)
+	}
+	// 安装程序的退出码写到标准输出；启动失败时以Win32错误码作为退出码，用于区分取消UAC提示和权限不足
+	psCmd = fmt.Sprintf(`try { $p = %s -ErrorAction Stop; Write-Output $p.ExitCode } catch { $e = $_.Exception; while ($e -and -not ($e -is [System.ComponentModel.Win32Exception])) { $e = $e.InnerException }; if ($e) { exit $e.NativeErrorCode }; exit 1 }`, psCmd)
+
+	// 执行PowerShell命令
+	slog.Debug("启动PowerShell", "script", psCmd)
+	var stdout bytes.Buffer
+	command := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", psCmd)
+	command.Stdout = &stdout
+	command.Stderr = os.Stderr
+
+	err := command.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 1 {
+			err = windows.Errno(exitErr.ExitCode())
+		}
+		return 0, 0, fmt.Errorf("执行命令失败: %w", err)
+	}
+
+	// 读取不到退出码时视为成功
+	exitCode, _ := strconv.Atoi(strings.TrimSpace(stdout.String()))
+	return command.Process.Pid, exitCode, nil
+}
+
+// 获取进程的所有子进程PID
+func getChildProcesses(pid int) ([]int, error) {
+	var childPids []int
+	processes, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, proc := range processes {
+		ppid, err := proc.Ppid()
+		if err == nil && int(ppid) == pid {
+			childPids = append(childPids, int(proc.Pid))
+		}
+	}
+
+	return childPids, nil
+}
+
+// 递归获取所有后代进程
+func getAllDescendants(pid int, visited map[int]bool) ([]int, error) {
+	if visited[pid] {
+		return nil, nil
+	}
+	visited[pid] = true
+
+	var descendants []int
+	children, err := getChildProcesses(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, childPid := range children {
+		descendants = append(descendants, childPid)
+		childDescendants, err := getAllDescendants(childPid, visited)
+		if err != nil {
+			return nil, err
+		}
+		descendants = append(descendants, childDescendants...)
+	}
+
+	return descendants, nil
+}
+
+// 检查进程是否仍在运行
+func isProcessRunning(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	defer windows.CloseHandle(handle)
+
+	var exitCode uint32
+	err = windows.GetExitCodeProcess(handle, &exitCode)
+	return err == nil && exitCode == STILL_ACTIVE
+}
+
+// 区分用户取消UAC提示、权限不足和其他启动失败
+func launchError(action string, err error) error {
+	params := errcode.Params{"action": action}
+	switch {
+	case errors.Is(err, windows.ERROR_CANCELLED):
+		return &errcode.Error{Code: errcode.Cancelled, Params: params, Err: err}
+	case errors.Is(err, windows.ERROR_ACCESS_DENIED), errors.Is(err, windows.ERROR_ELEVATION_REQUIRED):
+		return &errcode.Error{Code: errcode.AccessDenied, Params: params, Err: err}
+	default:
+		return errcode.Wrap(errcode.LaunchFailed, params, err)
+	}
+}
+
+// 启动卸载、修复或修改命令，等待进程树结束后报告结果，action 用于提示信息
+func run(app *inventory.App, action, cmd string, args []string, o *options) *Result {
+	result := &Result{
+		Success: false,
+		Command: commandLine(cmd, args),
+	}
+
+	// 启动进程
+	slog.Info("启动安装程序", "action", action, "app", app.DisplayName, "registryKey", app.RegistryKey, "path", cmd, "args", args, "timeout", o.timeout)
+	start := time.Now()
+	pid, exitCode, err := startProcess(cmd, args)
+	if err != nil {
+		err = launchError(action, err)
+		slog.Warn("无法启动安装程序", "action", action, "path", cmd, "code", errcode.CodeOf(err), "error", err)
+		return result.fail(err)
+	}
+	slog.Debug("安装程序进程已退出，等待子进程", "pid", pid, "exitCode", exitCode, "duration", time.Since(start))
+
+	// 监控进程树
+	timeout := time.After(o.timeout)
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+
+	// 只在进程树变化时记录，避免每次轮询都写日志
+	var lastRunning []int
+
+	for {
+		select {
+		case <-ticker.C:
+			// 获取所有相关进程
+			visited := make(map[int]bool)
+			descendants, err := getAllDescendants(pid, visited)
+			if err != nil {
+				slog.Debug("无法枚举子进程", "pid", pid, "error", err)
+				continue
+			}
+
+			// 检查主进程是否仍在运行
+			mainProcessRunning := isProcessRunning(pid)
+
+			// 检查是否还有子进程在运行
+			var running []int
+			for _, descendantPid := range descendants {
+				if isProcessRunning(descendantPid) {
+					running = append(running, descendantPid)
+				}
+			}
+			if !slices.Equal(running, lastRunning) {
+				slog.Debug("进程树", "pid", pid, "mainRunning", mainProcessRunning, "descendants", descendants, "running", running)
+				lastRunning = running
+			}
+
+			// 如果主进程和所有子进程都已结束，退出循环
+			if !mainProcessRunning && len(running) == 0 {
+				slog.Info("进程树已结束", "action", action, "duration", time.Since(start))
+				return finish(result, app, action, exitCode)
+			}
+
+		case <-timeout:
+			slog.Warn("等待进程树超时", "action", action, "pid", pid, "running", lastRunning, "duration", time.Since(start))
+			return result.fail(errcode.New(errcode.Timeout, errcode.Params{"action": action}))
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package uninstall
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	cmd, args, err := parseCommand(`MsiExec.exe /I{23170F69-40C1-2702-2301-000001000000}`)
+	if err != nil || !strings.HasSuffix(strings.ToLower(cmd), `\msiexec.exe`) || len(args) != 1 || args[0] != "/I{23170F69-40C1-2702-2301-000001000000}" {
+		t.Errorf("parseCommand = %q, %q, %v", cmd, args, err)
+	}
+}
@@ -0,0 +1,199 @@
+package uninstall
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+// 卸载前关闭安装目录中正在运行的进程的方式
+const (
+	CloseNone     = ""         // 只列出，不关闭
+	CloseAsk      = "ask"      // 由 WithAsk 的函数决定
+	CloseGraceful = "graceful" // 请求进程退出（Windows上向窗口发送 WM_CLOSE）并等待
+	CloseForce    = "force"    // 结束进程
+	// 以下只作为 WithAsk 的函数的返回值
+	CloseContinue = "continue" // 不关闭，继续卸载
+	CloseCancel   = "cancel"   // 取消卸载
+)
+
+// ValidCloseMode 判断是否为 --close-running 可用的值
+func ValidCloseMode(mode string) bool {
+	return mode == CloseNone || mode == CloseAsk || mode == CloseGraceful || mode == CloseForce
+}
+
+// RunningProcess 是可执行文件位于应用安装目录或卸载程序目录中的进程
+type RunningProcess struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// WithCloseRunning 设置卸载前如何关闭正在运行的进程，默认只在结果中列出
+func WithCloseRunning(mode string) Option {
+	return func(o *options) {
+		o.closeRunning = mode
+	}
+}
+
+// WithAsk 设置 CloseAsk 时询问用户的函数，返回 CloseGraceful、CloseForce、CloseContinue 或 CloseCancel。
+// 没有设置时不关闭进程
+func WithAsk(ask func(procs []RunningProcess) string) Option {
+	return func(o *options) {
+		o.ask = ask
+	}
+}
+
+// WithCloseTimeout 设置关闭进程后等待其退出的最长时间，默认10秒
+func WithCloseTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.closeTimeout = d
+		}
+	}
+}
+
+// 统一分隔符和大小写，便于比较路径
+func normalizePath(path string) string {
+	path = strings.ReplaceAll(strings.Trim(strings.TrimSpace(path), `"`), "/", `\`)
+	return strings.ToLower(strings.TrimRight(path, `\`))
+}
+
+// 判断 path 是否位于 dir 中
+func underDir(path, dir string) bool {
+	path, dir = normalizePath(path), normalizePath(dir)
+	return dir != "" && strings.HasPrefix(path, dir+`\`)
+}
+
+// 需要检查的目录：安装目录和卸载程序所在的目录。
+// 卸载程序在 excluded 中（如 msiexec.exe 所在的系统目录）时不检查其目录
+func watchedDirs(app *inventory.App, cmd string, excluded ...string) []string {
+	var dirs []string
+	if dir := app.InstallDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	cmd = strings.Trim(cmd, `"`)
+	i := strings.LastIndexAny(cmd, `\/`)
+	// 盘符根目录不检查
+	if i <= 0 || strings.HasSuffix(cmd[:i], ":") {
+		return dirs
+	}
+	cmdDir := cmd[:i]
+	for _, dir := range append(excluded, dirs...) {
+		if dir != "" && (normalizePath(cmdDir) == normalizePath(dir) || underDir(cmdDir, dir)) {
+			return dirs
+		}
+	}
+	return append(dirs, cmdDir)
+}
+
+// 返回可执行文件位于 dirs 中的进程
+func matchRunning(procs []RunningProcess, dirs []string) []RunningProcess {
+	var matched []RunningProcess
+	for _, p := range procs {
+		for _, dir := range dirs {
+			if underDir(p.Path, dir) {
+				matched = append(matched, p)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// 列出可以读取可执行文件路径的进程，不包括自身
+func listProcesses() ([]RunningProcess, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	self := int32(os.Getpid())
+	var result []RunningProcess
+	for _, p := range procs {
+		if p.Pid == self {
+			continue
+		}
+		// 无权访问的系统进程没有路径
+		exe, err := p.Exe()
+		if err != nil || exe == "" {
+			continue
+		}
+		name, _ := p.Name()
+		result = append(result, RunningProcess{PID: p.Pid, Name: name, Path: exe})
+	}
+	return result, nil
+}
+
+// 返回仍在运行的进程
+func stillRunning(procs []RunningProcess) []RunningProcess {
+	var running []RunningProcess
+	for _, p := range procs {
+		if exists, err := process.PidExists(p.PID); err == nil && exists {
+			running = append(running, p)
+		}
+	}
+	return running
+}
+
+// 按 mode 关闭进程，等待最多 timeout，返回仍在运行的进程
+func closeProcesses(procs []RunningProcess, mode string, timeout, poll time.Duration) []RunningProcess {
+	for _, p := range procs {
+		var err error
+		if mode == CloseForce {
+			var proc *process.Process
+			if proc, err = process.NewProcess(p.PID); err == nil {
+				err = proc.Kill()
+			}
+		} else {
+			err = closeGracefully(p.PID)
+		}
+		slog.Info("关闭进程", "mode", mode, "pid", p.PID, "path", p.Path, "error", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		running := stillRunning(procs)
+		if len(running) == 0 || time.Now().After(deadline) {
+			return running
+		}
+		time.Sleep(poll)
+	}
+}
+
+// 卸载前检查正在运行的进程，按选项关闭，返回仍在运行的进程。用户选择取消时 cancelled 为 true
+func checkRunning(app *inventory.App, cmd string, o *options) (running []RunningProcess, cancelled bool) {
+	procs, err := listProcesses()
+	if err != nil {
+		slog.Warn("无法枚举进程", "error", err)
+		return nil, false
+	}
+	dirs := watchedDirs(app, cmd, systemDir(), windowsDir())
+	running = matchRunning(procs, dirs)
+	slog.Info("检查正在运行的进程", "dirs", dirs, "running", len(running), "mode", o.closeRunning)
+	if len(running) == 0 {
+		return nil, false
+	}
+
+	mode := o.closeRunning
+	if mode == CloseAsk {
+		mode = CloseContinue
+		if o.ask != nil {
+			mode = o.ask(running)
+		}
+	}
+	switch mode {
+	case CloseCancel:
+		return running, true
+	case CloseGraceful, CloseForce:
+		running = closeProcesses(running, mode, o.closeTimeout, o.pollInterval)
+	}
+	if len(running) > 0 {
+		slog.Warn("仍有进程在运行", "count", len(running))
+	}
+	return running, false
+}
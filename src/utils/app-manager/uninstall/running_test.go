@@ -0,0 +1,70 @@
+package uninstall
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+func TestWatchedDirs(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		cmd      string
+		want     []string
+	}{
+		{"install dir and uninstaller dir", `C:\Program Files\App\`, `C:\ProgramData\App\uninst.exe`, []string{`C:\Program Files\App`, `C:\ProgramData\App`}},
+		{"uninstaller inside install dir", `"C:\Program Files\App"`, `C:\Program Files\App\bin\uninst.exe`, []string{`C:\Program Files\App`}},
+		{"msiexec in system dir", ``, `C:\Windows\System32\msiexec.exe`, nil},
+		{"system dir case", ``, `c:\windows\system32\MsiExec.exe`, nil},
+		{"drive root", ``, `C:\uninst.exe`, nil},
+		{"no path", ``, `uninst.exe`, nil},
+	}
+	for _, tt := range tests {
+		app := &inventory.App{InstallLocation: tt.location}
+		var got []string
+		for _, dir := range watchedDirs(app, tt.cmd, `C:\Windows\System32`, `C:\Windows`) {
+			got = append(got, normalizePath(dir))
+		}
+		var want []string
+		for _, dir := range tt.want {
+			want = append(want, normalizePath(dir))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: watchedDirs = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchRunning(t *testing.T) {
+	procs := []RunningProcess{
+		{PID: 1, Name: "app.exe", Path: `C:\Program Files\App\app.exe`},
+		{PID: 2, Name: "helper.exe", Path: `c:\program files\app\bin\helper.exe`},
+		{PID: 3, Name: "other.exe", Path: `C:\Program Files\AppOther\other.exe`},
+		{PID: 4, Name: "explorer.exe", Path: `C:\Windows\explorer.exe`},
+	}
+	var pids []int32
+	for _, p := range matchRunning(procs, []string{`C:\Program Files\App`}) {
+		pids = append(pids, p.PID)
+	}
+	if !reflect.DeepEqual(pids, []int32{1, 2}) {
+		t.Errorf("matchRunning = %v, want [1 2]", pids)
+	}
+	if got := matchRunning(procs, nil); got != nil {
+		t.Errorf("matchRunning with no dirs = %v", got)
+	}
+}
+
+func TestValidCloseMode(t *testing.T) {
+	for _, mode := range []string{"", CloseAsk, CloseGraceful, CloseForce} {
+		if !ValidCloseMode(mode) {
+			t.Errorf("ValidCloseMode(%q) = false", mode)
+		}
+	}
+	for _, mode := range []string{CloseContinue, CloseCancel, "kill"} {
+		if ValidCloseMode(mode) {
+			t.Errorf("ValidCloseMode(%q) = true", mode)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package uninstall 运行应用的卸载、修复和修改命令。
+//
+//	result := uninstall.Uninstall(&app, uninstall.WithTimeout(30*time.Minute))
+//
+// 命令通过 PowerShell 以管理员身份启动，并等待安装程序的整个进程树结束，
+// 因为很多卸载程序会复制自身到临时目录后再启动真正的卸载进程。
+package uninstall
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/cmdline"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/regfile"
+)
+
+// 操作名称，用作错误参数 action
+const (
+	ActionUninstall = "uninstall"
+	ActionRepair    = "repair"
+	ActionModify    = "modify"
+)
+
+// Result 是一次操作的结果，可以直接序列化为JSON。
+// 失败时 Code 和 Params 描述错误，Error 为按 errcode.Language() 生成的消息
+type Result struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Code    errcode.Code   `json:"code,omitempty"`
+	Params  errcode.Params `json:"params,omitempty"`
+	// 启动的命令行
+	Command string `json:"command,omitempty"`
+	// 卸载前备份的ARP注册表项，可以用 appman reregister 恢复
+	Backup string `json:"backup,omitempty"`
+	// 启动卸载程序时安装目录中仍在运行的进程
+	RunningProcesses []RunningProcess `json:"runningProcesses,omitempty"`
+	// 安装程序的退出码
+	ExitCode int `json:"exitCode,omitempty"`
+	// 成功但需要重新启动才能完成
+	RebootRequired bool `json:"rebootRequired,omitempty"`
+}
+
+// Windows Installer 和多数安装程序使用的退出码
+const (
+	exitRebootInitiated = 1641 // ERROR_SUCCESS_REBOOT_INITIATED
+	exitRebootRequired  = 3010 // ERROR_SUCCESS_REBOOT_REQUIRED
+	exitUserExit        = 1602 // ERROR_INSTALL_USEREXIT
+	exitCancelled       = 1223 // ERROR_CANCELLED
+)
+
+// 根据安装程序的退出码判断结果
+func finish(result *Result, app *inventory.App, action string, exitCode int) *Result {
+	slog.Info("安装程序已结束", "action", action, "app", app.DisplayName, "exitCode", exitCode)
+	result.ExitCode = exitCode
+	switch exitCode {
+	case 0, exitRebootRequired, exitRebootInitiated:
+		result.Success = true
+		result.RebootRequired = exitCode != 0
+		result.Message = errcode.Text(errcode.Language(), "succeeded", errcode.Params{"app": app.DisplayName, "action": action})
+		return result
+	case exitUserExit, exitCancelled:
+		return result.fail(errcode.New(errcode.Cancelled, errcode.Params{"action": action}))
+	default:
+		return result.fail(errcode.New(errcode.InstallerFailed, errcode.Params{"action": action, "exitCode": strconv.Itoa(exitCode)}))
+	}
+}
+
+// Failed 返回表示失败的结果，错误代码和参数取自 err
+func Failed(err error) *Result {
+	result := &Result{}
+	return result.fail(err)
+}
+
+// 记录失败的原因
+func (r *Result) fail(err error) *Result {
+	r.Success = false
+	r.Error = err.Error()
+	r.Code = errcode.CodeOf(err)
+	r.Params = errcode.ParamsOf(err)
+	return r
+}
+
+type options struct {
+	timeout      time.Duration
+	pollInterval time.Duration
+	backup       string
+	closeRunning string
+	ask          func(procs []RunningProcess) string
+	closeTimeout time.Duration
+}
+
+// Option 是 Uninstall、Repair 和 Modify 的选项
+type Option func(*options)
+
+// WithTimeout 设置等待进程树结束的最长时间，默认10分钟
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithPollInterval 设置检查进程是否结束的间隔，默认1秒
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithBackup 在卸载前把应用的ARP注册表项及其子项导出到 .reg 文件，只用于 Uninstall。
+// 备份失败时不会卸载；path 为空时不备份
+func WithBackup(path string) Option {
+	return func(o *options) {
+		o.backup = path
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		timeout:      10 * time.Minute,
+		pollInterval: 1 * time.Second,
+		closeTimeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func requiresParentError(app *inventory.App, action string) error {
+	return errcode.New(errcode.RequiresParent, errcode.Params{"app": app.DisplayName, "parent": app.ParentName(), "action": action})
+}
+
+// 解析命令行，MsiExec.exe 等不带路径的程序使用系统目录中的
+func parseCommand(cmdStr string) (string, []string, error) {
+	var opts []cmdline.Option
+	if dir := systemDir(); dir != "" {
+		opts = append(opts, cmdline.WithSearchDirs(dir))
+	}
+	cmd, args, err := cmdline.Parse(cmdStr, opts...)
+	if err != nil {
+		slog.Warn("无法解析命令行", "command", cmdStr, "code", errcode.CodeOf(err), "error", err)
+	}
+	return cmd, args, err
+}
+
+// 记录用的命令行，路径加引号
+func commandLine(cmd string, args []string) string {
+	return strings.TrimSpace(`"` + strings.Trim(cmd, `"`) + `" ` + strings.Join(args, " "))
+}
+
+// 系统目录中的msiexec.exe
+func msiexecPath() string {
+	if dir := systemDir(); dir != "" {
+		return filepath.Join(dir, "msiexec.exe")
+	}
+	return "msiexec.exe"
+}
+
+// Uninstall 运行应用的卸载命令并等待所有子进程结束。
+// 子组件和补丁需要随父产品卸载，此时返回错误
+func Uninstall(app *inventory.App, opts ...Option) *Result {
+	result := &Result{
+		Success: false,
+	}
+
+	// 子组件和补丁需要随父产品卸载
+	if app.RequiresParent() {
+		return result.fail(requiresParentError(app, ActionUninstall))
+	}
+
+	// 解析卸载命令
+	cmd, args, err := parseCommand(app.UninstallString)
+	if err != nil {
+		return result.fail(err)
+	}
+
+	o := newOptions(opts)
+	// 先备份再关闭进程，备份失败时不会白白结束用户的进程。
+	// 要求备份时，备份失败就不卸载，否则卸载中途失败后无法恢复
+	if o.backup != "" {
+		if err := backupKey(app, o.backup); err != nil {
+			slog.Warn("无法备份注册表项", "key", app.RegistryKey, "path", o.backup, "error", err)
+			return result.fail(errcode.Wrap(errcode.BackupFailed, errcode.Params{"key": app.RegistryKey}, err))
+		}
+	}
+
+	running, cancelled := checkRunning(app, cmd, o)
+	if cancelled {
+		if o.backup != "" {
+			os.Remove(o.backup)
+		}
+		result.RunningProcesses = running
+		return result.fail(errcode.New(errcode.Cancelled, errcode.Params{"action": ActionUninstall}))
+	}
+
+	result = run(app, ActionUninstall, cmd, args, o)
+	result.Backup = o.backup
+	result.RunningProcesses = running
+	return result
+}
+
+// 把应用的注册表项导出到 .reg 文件
+func backupKey(app *inventory.App, path string) error {
+	keys, err := regfile.Export(app.RegistryKey, regfile.ViewOf(app.Architecture))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := regfile.Write(f, keys); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	slog.Info("已备份注册表项", "key", app.RegistryKey, "path", path, "keys", len(keys))
+	return f.Close()
+}
+
+// Repair 修复应用。MSI产品使用 msiexec /f，其他应用运行ModifyPath打开安装程序的维护界面
+func Repair(app *inventory.App, opts ...Option) *Result {
+	result := &Result{
+		Success: false,
+	}
+
+	if app.RequiresParent() {
+		return result.fail(requiresParentError(app, ActionRepair))
+	}
+	if app.NoRepair {
+		return result.fail(errcode.New(errcode.NotSupported, errcode.Params{"app": app.DisplayName, "action": ActionRepair}))
+	}
+
+	if code := app.MSIProductCode(); code != "" {
+		return run(app, ActionRepair, msiexecPath(), []string{"/f", code}, newOptions(opts))
+	}
+	if app.ModifyPath == "" {
+		return result.fail(errcode.New(errcode.NoCommand, errcode.Params{"app": app.DisplayName, "action": ActionRepair}))
+	}
+	cmd, args, err := parseCommand(app.ModifyPath)
+	if err != nil {
+		return result.fail(err)
+	}
+	return run(app, ActionRepair, cmd, args, newOptions(opts))
+}
+
+// Modify 修改应用的安装功能。优先使用ModifyPath，没有时MSI产品使用 msiexec /i 进入维护模式
+func Modify(app *inventory.App, opts ...Option) *Result {
+	result := &Result{
+		Success: false,
+	}
+
+	if app.RequiresParent() {
+		return result.fail(requiresParentError(app, ActionModify))
+	}
+	if app.NoModify {
+		return result.fail(errcode.New(errcode.NotSupported, errcode.Params{"app": app.DisplayName, "action": ActionModify}))
+	}
+
+	if app.ModifyPath != "" {
+		cmd, args, err := parseCommand(app.ModifyPath)
+		if err != nil {
+			return result.fail(err)
+		}
+		return run(app, ActionModify, cmd, args, newOptions(opts))
+	}
+	if code := app.MSIProductCode(); code != "" {
+		return run(app, ActionModify, msiexecPath(), []string{"/i", code}, newOptions(opts))
+	}
+	return result.fail(errcode.New(errcode.NoCommand, errcode.Params{"app": app.DisplayName, "action": ActionModify}))
+}
@@ -0,0 +1,122 @@
+package uninstall
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/errcode"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/inventory"
+)
+
+func TestRefused(t *testing.T) {
+	tests := []struct {
+		name string
+		app  inventory.App
+		run  func(*inventory.App, ...Option) *Result
+		want string
+		code errcode.Code
+	}{
+		{"no repair", inventory.App{DisplayName: "A", NoRepair: true, WindowsInstaller: true, RegistryKey: `HKLM\X\{23170F69-40C1-2702-2301-000001000000}`}, Repair, "不支持修复", errcode.NotSupported},
+		{"no modify", inventory.App{DisplayName: "A", NoModify: true, ModifyPath: "setup.exe"}, Modify, "不支持修改", errcode.NotSupported},
+		{"no repair command", inventory.App{DisplayName: "A", RegistryKey: `HKLM\X\A`}, Repair, "没有可用的修复命令", errcode.NoCommand},
+		{"not msi", inventory.App{DisplayName: "A", RegistryKey: `HKLM\X\{23170F69-40C1-2702-2301-000001000000}`}, Modify, "没有可用的修改命令", errcode.NoCommand},
+		{"child", inventory.App{DisplayName: "KB1", ParentDisplayName: "Office", ModifyPath: "setup.exe"}, Repair, "Office", errcode.RequiresParent},
+		{"child uninstall", inventory.App{DisplayName: "Orphan Update", UninstallString: "remove.exe", ParentDisplayName: "Contoso"}, Uninstall, "Contoso", errcode.RequiresParent},
+	}
+	for _, tt := range tests {
+		result := tt.run(&tt.app)
+		if result.Success || !strings.Contains(result.Error, tt.want) || result.Code != tt.code {
+			t.Errorf("%s: result = %+v, want %s containing %q", tt.name, result, tt.code, tt.want)
+		}
+	}
+}
+
+func TestFinish(t *testing.T) {
+	app := &inventory.App{DisplayName: "7-Zip"}
+	tests := []struct {
+		exitCode int
+		success  bool
+		reboot   bool
+		code     errcode.Code
+	}{
+		{0, true, false, ""},
+		{3010, true, true, ""},
+		{1641, true, true, ""},
+		{1602, false, false, errcode.Cancelled},
+		{2, false, false, errcode.InstallerFailed},
+	}
+	for _, tt := range tests {
+		result := finish(&Result{}, app, ActionUninstall, tt.exitCode)
+		if result.Success != tt.success || result.RebootRequired != tt.reboot || result.Code != tt.code || result.ExitCode != tt.exitCode {
+			t.Errorf("finish(%d) = %+v", tt.exitCode, result)
+		}
+	}
+}
+
+func TestBackupFailed(t *testing.T) {
+	dir := t.TempDir()
+	uninstaller := filepath.Join(dir, "Uninstall.exe")
+	if err := os.WriteFile(uninstaller, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	app := &inventory.App{DisplayName: "7-Zip", UninstallString: `"` + uninstaller + `"`, RegistryKey: `HKLM\X\7-Zip`}
+	backup := filepath.Join(dir, "backup.reg")
+
+	// 备份失败时不运行卸载程序
+	result := Uninstall(app, WithBackup(backup))
+	if result.Success || result.Code != errcode.BackupFailed || result.Params["key"] != app.RegistryKey || result.Backup != "" {
+		t.Errorf("Uninstall with failed backup = %+v", result)
+	}
+
+	// 不要求备份时直接运行卸载程序
+	result = Uninstall(app, WithBackup(backup), WithBackup(""))
+	if result.Code == errcode.BackupFailed {
+		t.Errorf("Uninstall without backup = %+v", result)
+	}
+}
+
+// 供 TestBackupBeforeClosing 启动的子进程，模拟安装目录中正在运行的程序
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("APPMAN_TEST_HELPER") != "1" {
+		return
+	}
+	time.Sleep(time.Minute)
+}
+
+func TestBackupBeforeClosing(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper := exec.Command(exe, "-test.run=^TestHelperProcess$")
+	helper.Env = append(os.Environ(), "APPMAN_TEST_HELPER=1")
+	if err := helper.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		helper.Process.Kill()
+		helper.Wait()
+	}()
+
+	app := &inventory.App{DisplayName: "App", UninstallString: `"` + exe + `"`, InstallLocation: filepath.Dir(exe), RegistryKey: `HKLM\X\App`}
+	asked := 0
+	ask := WithAsk(func([]RunningProcess) string {
+		asked++
+		return CloseCancel
+	})
+
+	// 备份失败时不检查也不关闭正在运行的进程
+	result := Uninstall(app, WithCloseRunning(CloseAsk), ask, WithBackup(filepath.Join(t.TempDir(), "backup.reg")))
+	if result.Code != errcode.BackupFailed || asked != 0 {
+		t.Errorf("Uninstall with failed backup = %+v, asked %d times", result, asked)
+	}
+
+	result = Uninstall(app, WithCloseRunning(CloseAsk), ask)
+	if result.Code != errcode.Cancelled || asked != 1 {
+		t.Errorf("Uninstall without backup = %+v, asked %d times", result, asked)
+	}
+}
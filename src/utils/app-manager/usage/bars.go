@@ -0,0 +1,80 @@
+package usage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/normalize"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+)
+
+const (
+	// 名称列的最大宽度
+	maxLabelWidth = 30
+	// 未指定宽度时条形的长度
+	defaultBarWidth = 30
+	minBarWidth     = 10
+)
+
+// Bars 输出文本条形图，条形长度相对于最大的一项，width 为总宽度，0 表示使用默认宽度
+func Bars(w io.Writer, title string, entries []Entry, total int64, width int) error {
+	if _, err := fmt.Fprintf(w, "%s\n", title); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "  (无)")
+		return err
+	}
+
+	labelWidth := 0
+	sizes := make([]string, len(entries))
+	sizeWidth := 0
+	for i, e := range entries {
+		labelWidth = max(labelWidth, min(table.Width(e.Name), maxLabelWidth))
+		sizes[i] = normalize.HumanSize(e.Size)
+		sizeWidth = max(sizeWidth, len(sizes[i]))
+	}
+
+	// 缩进 2，名称，条形，大小和百分比之间各留 2 个空格，百分比宽 6
+	barWidth := defaultBarWidth
+	if width > 0 {
+		barWidth = width - 2 - labelWidth - 2 - 2 - sizeWidth - 2 - 6
+		if barWidth < minBarWidth {
+			labelWidth = max(labelWidth-(minBarWidth-barWidth), 8)
+			barWidth = minBarWidth
+		}
+		barWidth = min(barWidth, 60)
+	}
+
+	largest := entries[0].Size
+	for _, e := range entries {
+		largest = max(largest, e.Size)
+	}
+
+	// 控制台把东亚模糊宽度字符显示为双宽时改用 #
+	block := "█"
+	if table.Width(block) != 1 {
+		block = "#"
+	}
+
+	for i, e := range entries {
+		n := 0
+		if largest > 0 {
+			n = int((e.Size*int64(barWidth) + largest/2) / largest)
+		}
+		if n == 0 && e.Size > 0 {
+			n = 1
+		}
+		percent := 0.0
+		if total > 0 {
+			percent = float64(e.Size) * 100 / float64(total)
+		}
+		label := table.PadRight(table.Truncate(e.Name, labelWidth), labelWidth)
+		bar := strings.Repeat(block, n) + strings.Repeat(" ", barWidth-n)
+		if _, err := fmt.Fprintf(w, "  %s  %s  %s  %5.1f%%\n", label, bar, table.PadLeft(sizes[i], sizeWidth), percent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package usage 按应用、发布者、安装程序类型和磁盘汇总已安装软件的占用空间，
+// 并生成适合树图（treemap）展示的层级数据和终端中的文本条形图。
+package usage
+
+import (
+	"sort"
+	"strings"
+)
+
+// Unknown 是分组值为空时使用的名称
+const Unknown = "未知"
+
+// Item 是一个参与统计的应用
+type Item struct {
+	Name          string
+	Publisher     string
+	InstallerType string
+	Drive         string // 如 C:，未知时为空
+	Size          int64  // 字节，未知时为 0
+}
+
+// Entry 是一个应用或分组的占用空间
+type Entry struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	Count int    `json:"count,omitempty"` // 分组中的应用数量
+	// 与 Items 对应的索引，只用于 Top
+	Index int `json:"-"`
+}
+
+// Node 是树图中的节点，叶子节点为应用
+type Node struct {
+	Name     string  `json:"name"`
+	Value    int64   `json:"value"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// Summary 是统计结果，各列表按占用空间从大到小排列
+type Summary struct {
+	Total int64 `json:"total"`
+	Count int   `json:"count"`
+	// 大小未知的应用数量，不计入任何分组
+	UnknownSize     int     `json:"unknownSize"`
+	Top             []Entry `json:"top"`
+	ByPublisher     []Entry `json:"byPublisher"`
+	ByInstallerType []Entry `json:"byInstallerType"`
+	ByDrive         []Entry `json:"byDrive"`
+	// 根节点为全部应用，第二层为发布者，第三层为应用
+	Tree *Node `json:"tree"`
+}
+
+// Summarize 统计占用空间，top 为列出的最大应用数量，0 表示全部
+func Summarize(items []Item, top int) *Summary {
+	s := &Summary{Count: len(items)}
+
+	sized := []Entry{}
+	publishers := newGroups()
+	types := newGroups()
+	drives := newGroups()
+	for i, item := range items {
+		if item.Size <= 0 {
+			s.UnknownSize++
+			continue
+		}
+		s.Total += item.Size
+		sized = append(sized, Entry{Name: item.Name, Size: item.Size, Index: i})
+		publishers.add(item.Publisher, item.Size)
+		types.add(item.InstallerType, item.Size)
+		drives.add(strings.ToUpper(item.Drive), item.Size)
+	}
+
+	sortEntries(sized)
+	s.Top = sized
+	if top > 0 && len(s.Top) > top {
+		s.Top = s.Top[:top]
+	}
+	s.ByPublisher = publishers.entries()
+	s.ByInstallerType = types.entries()
+	s.ByDrive = drives.entries()
+	s.Tree = buildTree(items, sized, s.ByPublisher, s.Total)
+	return s
+}
+
+func buildTree(items []Item, sized []Entry, publishers []Entry, total int64) *Node {
+	root := &Node{Name: "全部应用", Value: total, Children: []*Node{}}
+	nodes := make(map[string]*Node)
+	for _, p := range publishers {
+		n := &Node{Name: p.Name, Value: p.Size}
+		nodes[p.Name] = n
+		root.Children = append(root.Children, n)
+	}
+	// sized 已按大小排序，子节点也按大小排列
+	for _, e := range sized {
+		n := nodes[groupName(items[e.Index].Publisher)]
+		n.Children = append(n.Children, &Node{Name: e.Name, Value: e.Size})
+	}
+	return root
+}
+
+func groupName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Unknown
+	}
+	return name
+}
+
+type groups struct {
+	index map[string]int
+	list  []Entry
+}
+
+func newGroups() *groups {
+	return &groups{index: make(map[string]int)}
+}
+
+func (g *groups) add(name string, size int64) {
+	name = groupName(name)
+	i, ok := g.index[name]
+	if !ok {
+		i = len(g.list)
+		g.index[name] = i
+		g.list = append(g.list, Entry{Name: name})
+	}
+	g.list[i].Size += size
+	g.list[i].Count++
+}
+
+func (g *groups) entries() []Entry {
+	result := append([]Entry{}, g.list...)
+	sortEntries(result)
+	return result
+}
+
+// 按大小从大到小，大小相同时按名称
+func sortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Size != entries[j].Size {
+			return entries[i].Size > entries[j].Size
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+}
@@ -0,0 +1,131 @@
+package usage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/table"
+)
+
+func testItems() []Item {
+	return []Item{
+		{Name: "Visual Studio", Publisher: "Microsoft Corporation", InstallerType: "msi", Drive: "c:", Size: 8 << 30},
+		{Name: "Office", Publisher: "Microsoft Corporation", InstallerType: "msi", Drive: "C:", Size: 3 << 30},
+		{Name: "Steam", Publisher: "Valve", InstallerType: "nsis", Drive: "D:", Size: 5 << 30},
+		{Name: "7-Zip", Publisher: "Igor Pavlov", InstallerType: "exe", Drive: "C:", Size: 5 << 20},
+		{Name: "Tool", Publisher: "", InstallerType: "", Drive: "", Size: 1 << 20},
+		{Name: "NoSize", Publisher: "Valve", InstallerType: "nsis", Drive: "D:"},
+	}
+}
+
+func names(entries []Entry) []string {
+	var result []string
+	for _, e := range entries {
+		result = append(result, e.Name)
+	}
+	return result
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize(testItems(), 3)
+
+	if s.Count != 6 || s.UnknownSize != 1 {
+		t.Errorf("Count = %d, UnknownSize = %d, want 6, 1", s.Count, s.UnknownSize)
+	}
+	wantTotal := int64(16<<30 + 6<<20)
+	if s.Total != wantTotal {
+		t.Errorf("Total = %d, want %d", s.Total, wantTotal)
+	}
+	if got := strings.Join(names(s.Top), ","); got != "Visual Studio,Steam,Office" {
+		t.Errorf("Top = %s", got)
+	}
+	if s.Top[1].Index != 2 {
+		t.Errorf("Top[1].Index = %d, want 2", s.Top[1].Index)
+	}
+
+	if got := strings.Join(names(s.ByPublisher), ","); got != "Microsoft Corporation,Valve,Igor Pavlov,未知" {
+		t.Errorf("ByPublisher = %s", got)
+	}
+	if s.ByPublisher[0].Size != 11<<30 || s.ByPublisher[0].Count != 2 {
+		t.Errorf("ByPublisher[0] = %+v", s.ByPublisher[0])
+	}
+	if got := strings.Join(names(s.ByInstallerType), ","); got != "msi,nsis,exe,未知" {
+		t.Errorf("ByInstallerType = %s", got)
+	}
+	if got := strings.Join(names(s.ByDrive), ","); got != "C:,D:,未知" {
+		t.Errorf("ByDrive = %s", got)
+	}
+	if s.ByDrive[0].Count != 3 {
+		t.Errorf("ByDrive[0].Count = %d, want 3", s.ByDrive[0].Count)
+	}
+
+	if len(Summarize(testItems(), 0).Top) != 5 {
+		t.Error("top 0 should list all sized apps")
+	}
+}
+
+func TestSummarizeTree(t *testing.T) {
+	s := Summarize(testItems(), 1)
+	root := s.Tree
+	if root.Value != s.Total || len(root.Children) != 4 {
+		t.Fatalf("root = %d with %d children", root.Value, len(root.Children))
+	}
+	for _, p := range root.Children {
+		var sum int64
+		for _, c := range p.Children {
+			sum += c.Value
+			if len(c.Children) != 0 {
+				t.Errorf("leaf %s has children", c.Name)
+			}
+		}
+		if sum != p.Value {
+			t.Errorf("%s: children sum %d, value %d", p.Name, sum, p.Value)
+		}
+	}
+	ms := root.Children[0]
+	if ms.Name != "Microsoft Corporation" || ms.Children[0].Name != "Visual Studio" || ms.Children[1].Name != "Office" {
+		t.Errorf("unexpected first publisher node: %+v", ms)
+	}
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	s := Summarize(nil, 10)
+	if s.Total != 0 || s.Top == nil || len(s.Tree.Children) != 0 {
+		t.Errorf("unexpected empty summary: %+v", s)
+	}
+}
+
+func TestBars(t *testing.T) {
+	s := Summarize(testItems(), 0)
+	var buf bytes.Buffer
+	if err := Bars(&buf, "按发布者", s.ByPublisher, s.Total, 80); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if lines[0] != "按发布者" || len(lines) != 5 {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	width := table.Width(lines[1])
+	for _, line := range lines[1:] {
+		if table.Width(line) != width {
+			t.Errorf("misaligned line %q", line)
+		}
+		if table.Width(line) > 80 {
+			t.Errorf("line wider than 80: %q", line)
+		}
+	}
+	if !strings.Contains(lines[1], "11.0 GB") || !strings.Contains(lines[1], "68.7%") {
+		t.Errorf("first line = %q", lines[1])
+	}
+	// 非零的项至少有一格
+	if !strings.Contains(lines[4], "█") {
+		t.Errorf("small entry has no bar: %q", lines[4])
+	}
+
+	buf.Reset()
+	Bars(&buf, "空", nil, 0, 0)
+	if !strings.Contains(buf.String(), "(无)") {
+		t.Errorf("empty output = %q", buf.String())
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/cmdline"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/normalize"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/usage"
+)
+
+type UsageResult struct {
+	Success bool           `json:"success"`
+	Summary *usage.Summary `json:"summary,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// 安装目录所在的盘符，没有安装目录时使用卸载程序所在的盘符
+func appDrive(app *App) string {
+	loc := strings.Trim(strings.TrimSpace(app.InstallLocation), `"`)
+	if loc == "" {
+		if cmd, _, err := cmdline.Parse(app.UninstallString); err == nil && filepath.IsAbs(cmd) {
+			loc = cmd
+		}
+	}
+	return strings.ToUpper(filepath.VolumeName(loc))
+}
+
+// 汇总应用占用的空间，top 为列出的最大应用数量
+func usageSummary(apps []App, top int) *UsageResult {
+	items := make([]usage.Item, len(apps))
+	for i := range apps {
+		app := &apps[i]
+		items[i] = usage.Item{
+			Name:          app.DisplayName,
+			Publisher:     app.Publisher,
+			InstallerType: detectInstallerType(app),
+			Drive:         appDrive(app),
+			Size:          app.SizeBytes,
+		}
+	}
+	return &UsageResult{
+		Success: true,
+		Summary: usage.Summarize(items, top),
+	}
+}
+
+// 分组较多时文本输出只列出前几项
+const usageTextGroups = 10
+
+func writeUsageResult(w io.Writer, result *UsageResult, format string) error {
+	switch format {
+	case "text":
+		s := result.Summary
+		width := 0
+		if f, ok := w.(*os.File); ok {
+			width = consoleWidth(f)
+		}
+		fmt.Fprintf(w, "共 %d 个应用，合计 %s", s.Count, normalize.HumanSize(s.Total))
+		if s.UnknownSize > 0 {
+			fmt.Fprintf(w, "，其中 %d 个应用大小未知", s.UnknownSize)
+		}
+		fmt.Fprintln(w)
+
+		sections := []struct {
+			title   string
+			entries []usage.Entry
+		}{
+			{fmt.Sprintf("\n占用空间最大的 %d 个应用", len(s.Top)), s.Top},
+			{"\n按发布者", s.ByPublisher},
+			{"\n按安装程序类型", s.ByInstallerType},
+			{"\n按磁盘", s.ByDrive},
+		}
+		for i, sec := range sections {
+			entries := sec.entries
+			if i > 0 && len(entries) > usageTextGroups {
+				entries = entries[:usageTextGroups]
+			}
+			if err := usage.Bars(w, sec.title, entries, s.Total, width); err != nil {
+				return err
+			}
+		}
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	case "treemap":
+		// 只输出树图数据，供渲染端直接使用
+		jsonData, err := json.MarshalIndent(result.Summary.Tree, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
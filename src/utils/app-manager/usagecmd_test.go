@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestAppDrive(t *testing.T) {
+	tests := []struct {
+		app  App
+		want string
+	}{
+		{App{InstallLocation: `d:\Games\Steam`}, "D:"},
+		{App{InstallLocation: `"C:\Program Files\Git\"`}, "C:"},
+		{App{UninstallString: `"E:\Tools\unins000.exe" /SILENT`}, "E:"},
+		{App{UninstallString: `MsiExec.exe /X{23170F69-40C1-2702-2301-000001000000}`}, ""},
+		{App{}, ""},
+	}
+	for _, tt := range tests {
+		if got := appDrive(&tt.app); got != tt.want {
+			t.Errorf("appDrive(%+v) = %q, want %q", tt.app, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Package versions 比较注册表中常见的各种版本号字符串。
+//
+// 版本号被拆分为数字段和字母段，支持 "."、"-"、"_"、空格等混合分隔符，
+// 前导的 "v"、"build"、"x64" 之类的修饰词会被忽略。alpha、beta、rc 等
+// 预发布标记低于正式版本，其他字母后缀（如 "1.1.1w"）高于正式版本。
+// "+" 之后的构建元数据只在其余部分完全相同时才参与比较。
+package versions
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	// 缺失的段，比较时按0处理
+	kindMissing tokenKind = iota
+	kindPreRelease
+	kindNumber
+	kindWord
+)
+
+type token struct {
+	kind tokenKind
+	// 数字段为去掉前导0后的数字串，字母段为小写单词
+	text string
+	// 预发布标记的先后顺序
+	rank int
+}
+
+// Version 是解析后的版本号
+type Version struct {
+	raw   string
+	main  []token
+	build []token
+}
+
+// 预发布标记及其先后顺序
+var preReleaseRanks = map[string]int{
+	"dev":      0,
+	"snapshot": 0,
+	"nightly":  0,
+	"canary":   0,
+	"insider":  0,
+	"alpha":    1,
+	"beta":     2,
+	"pre":      3,
+	"preview":  3,
+	"rc":       4,
+	"cr":       4,
+}
+
+// 不影响版本先后的修饰词
+var noiseWords = map[string]bool{
+	"v":       true,
+	"ver":     true,
+	"version": true,
+	"build":   true,
+	"release": true,
+	"final":   true,
+	"ga":      true,
+	"stable":  true,
+	"x86":     true,
+	"x64":     true,
+	"amd64":   true,
+	"arm64":   true,
+	"win32":   true,
+	"win64":   true,
+}
+
+// Parse 解析版本号字符串，任何输入都能得到一个可比较的结果
+func Parse(s string) Version {
+	raw := s
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = stripParentheses(s)
+
+	var buildPart string
+	if idx := strings.Index(s, "+"); idx >= 0 {
+		s, buildPart = s[:idx], s[idx+1:]
+	}
+
+	return Version{
+		raw:   raw,
+		main:  trimZeros(tokenize(s)),
+		build: trimZeros(tokenize(buildPart)),
+	}
+}
+
+// String 返回原始版本号字符串
+func (v Version) String() string {
+	return v.raw
+}
+
+// Compare 比较两个版本号，v小于、等于、大于other时分别返回-1、0、1
+func (v Version) Compare(other Version) int {
+	if c := compareTokens(v.main, other.main); c != 0 {
+		return c
+	}
+	return compareTokens(v.build, other.build)
+}
+
+// Compare 比较两个版本号字符串，a小于、等于、大于b时分别返回-1、0、1
+func Compare(a, b string) int {
+	if a == b {
+		return 0
+	}
+	return Parse(a).Compare(Parse(b))
+}
+
+// 去掉括号中的说明文字，例如 "1.2 (x64)"
+func stripParentheses(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+		case (r == ')' || r == ']') && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// 将版本号拆分为数字段和字母段
+func tokenize(s string) []token {
+	var tokens []token
+
+	for _, chunk := range strings.FieldsFunc(s, isSeparator) {
+		if noiseWords[chunk] {
+			continue
+		}
+		// "v3" 这样的前缀在第一段中出现时去掉 v
+		if len(tokens) == 0 && len(chunk) > 1 && chunk[0] == 'v' && unicode.IsDigit(rune(chunk[1])) {
+			chunk = chunk[1:]
+		}
+
+		start := 0
+		runes := []rune(chunk)
+		for i := 1; i <= len(runes); i++ {
+			if i < len(runes) && unicode.IsDigit(runes[i]) == unicode.IsDigit(runes[i-1]) {
+				continue
+			}
+			tokens = append(tokens, newToken(string(runes[start:i])))
+			start = i
+		}
+	}
+
+	return tokens
+}
+
+func newToken(text string) token {
+	if unicode.IsDigit([]rune(text)[0]) {
+		text = strings.TrimLeft(text, "0")
+		if text == "" {
+			text = "0"
+		}
+		return token{kind: kindNumber, text: text}
+	}
+
+	if rank, ok := preReleaseRanks[text]; ok {
+		return token{kind: kindPreRelease, text: text, rank: rank}
+	}
+	return token{kind: kindWord, text: text}
+}
+
+// 去掉字母段之前和末尾的0，使 "1.0.0-beta" 与 "1.0-beta"、"1.0.0" 与 "1" 相等
+func trimZeros(tokens []token) []token {
+	var result []token
+	for i, t := range tokens {
+		if t.kind == kindNumber && t.text == "0" && onlyZerosUntilWord(tokens[i:]) {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+func onlyZerosUntilWord(tokens []token) bool {
+	for _, t := range tokens {
+		if t.kind != kindNumber {
+			return true
+		}
+		if t.text != "0" {
+			return false
+		}
+	}
+	return true
+}
+
+func compareTokens(a, b []token) int {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+
+	for i := 0; i < n; i++ {
+		var ta, tb token
+		if i < len(a) {
+			ta = a[i]
+		}
+		if i < len(b) {
+			tb = b[i]
+		}
+		if c := compareToken(ta, tb); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// 段之间的顺序: 预发布标记 < 缺失 < 字母后缀 < 数字
+func order(t token) int {
+	switch t.kind {
+	case kindPreRelease:
+		return 0
+	case kindMissing:
+		return 1
+	case kindWord:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func compareToken(a, b token) int {
+	// 缺失的段与数字比较时视为0
+	if a.kind == kindMissing && b.kind == kindNumber {
+		a = token{kind: kindNumber, text: "0"}
+	}
+	if b.kind == kindMissing && a.kind == kindNumber {
+		b = token{kind: kindNumber, text: "0"}
+	}
+
+	if oa, ob := order(a), order(b); oa != ob {
+		return sign(oa - ob)
+	}
+
+	switch a.kind {
+	case kindNumber:
+		if len(a.text) != len(b.text) {
+			return sign(len(a.text) - len(b.text))
+		}
+		return strings.Compare(a.text, b.text)
+	case kindPreRelease:
+		if a.rank != b.rank {
+			return sign(a.rank - b.rank)
+		}
+		return strings.Compare(a.text, b.text)
+	case kindWord:
+		return strings.Compare(a.text, b.text)
+	}
+	return 0
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package versions
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		// 纯数字
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1", "1.0.0.0", 0},
+		{"1.2", "1.10", -1},
+		{"1.10", "1.9", 1},
+		{"2.0", "10.0", -1},
+		{"10.0.19041.1", "10.0.19041.1234", -1},
+		{"1.01", "1.1", 0},
+		{"1.0.1", "1.0", 1},
+		{"0.9", "0.10", -1},
+		{"20240315", "20231201", 1},
+		{"123456789012345678901234", "123456789012345678901235", -1},
+		{"", "", 0},
+		{"", "1.0", -1},
+		{"0.1", "", 1},
+
+		// 字母后缀
+		{"1.10b", "1.10a", 1},
+		{"1.10a", "1.10b", -1},
+		{"1.1.1w", "1.1.1", 1},
+		{"1.1.1w", "1.1.2", -1},
+		{"1.0a", "1.0.1", -1},
+		{"abc", "abd", -1},
+
+		// 预发布标记
+		{"2023.10-beta", "2023.10", -1},
+		{"2023.10", "2023.10-beta", 1},
+		{"1.0-alpha", "1.0-beta", -1},
+		{"1.0-beta", "1.0-rc", -1},
+		{"1.0-rc1", "1.0-rc2", -1},
+		{"1.0-rc.2", "1.0-rc.10", -1},
+		{"1.0rc1", "1.0-RC.1", 0},
+		{"1.0.0-beta", "1.0-beta", 0},
+		{"1.0-beta", "0.9", 1},
+		{"1.0-beta", "1.0.1", -1},
+		{"1.0-preview", "1.0-rc", -1},
+		{"1.0-dev", "1.0-alpha", -1},
+		{"1.0-nightly", "1.0", -1},
+		{"2.0.0-beta.2", "2.0.0-beta.11", -1},
+		{"4.0-Beta", "4.0-beta", 0},
+
+		// 构建元数据
+		{"1.0+build.5", "1.0+build.6", -1},
+		{"1.0+abc", "1.0", 1},
+		{"1.0+5", "1.1", -1},
+		{"1.0-beta+exp.sha", "1.0", -1},
+
+		// 前导 v 和修饰词
+		{"v3.2", "3.2", 0},
+		{"V3.2", "3.2.1", -1},
+		{"v 3.2", "3.2", 0},
+		{"version 2.1", "2.1", 0},
+		{"5.0 build 1234", "5.0 build 1300", -1},
+		{"5.0 build 1234", "5.0", 1},
+		{"5.0 build 1234", "5.1", -1},
+		{"5.0 (build 1234)", "5.0", 0},
+		{"1.2 (x64)", "1.2", 0},
+		{"1.2 x64", "1.2 x86", 0},
+		{"3.0 Final", "3.0", 0},
+
+		// 混合分隔符
+		{"1_2_3", "1.2.3", 0},
+		{"1-2-3", "1.2.3", 0},
+		{"1.2 3", "1.2.3", 0},
+		{"1.2,3", "1.2.4", -1},
+		{" 1.2 ", "1.2", 0},
+		{"2.3-1", "2.3", 1},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		// 比较结果必须是对称的
+		if got := Compare(tt.b, tt.a); got != -tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestCompareIsTransitive(t *testing.T) {
+	ordered := []string{
+		"0.9",
+		"1.0-dev",
+		"1.0-alpha",
+		"1.0-alpha.2",
+		"1.0-beta",
+		"1.0-rc1",
+		"1.0",
+		"1.0+build.7",
+		"1.0a",
+		"1.0b",
+		"1.0.1",
+		"1.2",
+		"1.10",
+		"v2",
+		"2.0 build 15",
+		"10.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := Compare(ordered[i], ordered[j]); got != want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestParseKeepsRaw(t *testing.T) {
+	v := Parse(" v1.2-beta ")
+	if v.String() != " v1.2-beta " {
+		t.Errorf("String() = %q, want original input", v.String())
+	}
+}
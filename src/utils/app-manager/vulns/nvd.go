@@ -0,0 +1,94 @@
+package vulns
+
+// NVD JSON 1.1 数据源（nvdcve-1.1-*.json）
+type nvdFeed11 struct {
+	Items []struct {
+		CVE struct {
+			Meta struct {
+				ID string `json:"ID"`
+			} `json:"CVE_data_meta"`
+			Description struct {
+				Data []nvdDescription `json:"description_data"`
+			} `json:"description"`
+		} `json:"cve"`
+		Configurations struct {
+			Nodes []nvdNode11 `json:"nodes"`
+		} `json:"configurations"`
+		Impact struct {
+			V3 struct {
+				CVSS struct {
+					BaseScore    float64 `json:"baseScore"`
+					BaseSeverity string  `json:"baseSeverity"`
+				} `json:"cvssV3"`
+			} `json:"baseMetricV3"`
+			V2 struct {
+				CVSS struct {
+					BaseScore float64 `json:"baseScore"`
+				} `json:"cvssV2"`
+				Severity string `json:"severity"`
+			} `json:"baseMetricV2"`
+		} `json:"impact"`
+	} `json:"CVE_Items"`
+}
+
+type nvdNode11 struct {
+	Children []nvdNode11   `json:"children"`
+	CPEMatch []nvdCPEMatch `json:"cpe_match"`
+}
+
+// NVD API 2.0 的返回结果，按年份同步的数据源也使用该格式
+type nvdFeed20 struct {
+	Vulnerabilities []struct {
+		CVE struct {
+			ID           string           `json:"id"`
+			Descriptions []nvdDescription `json:"descriptions"`
+			Metrics      struct {
+				V31 []nvdMetric20 `json:"cvssMetricV31"`
+				V30 []nvdMetric20 `json:"cvssMetricV30"`
+				V2  []nvdMetric20 `json:"cvssMetricV2"`
+			} `json:"metrics"`
+			Configurations []struct {
+				Nodes []struct {
+					CPEMatch []nvdCPEMatch `json:"cpeMatch"`
+				} `json:"nodes"`
+			} `json:"configurations"`
+		} `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+type nvdMetric20 struct {
+	CVSSData struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	// CVSS v2 的严重程度在cvssData之外
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+type nvdDescription struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+// 1.1 和 2.0 中的 cpe_match/cpeMatch 字段，CPE 分别为 cpe23Uri 和 criteria
+type nvdCPEMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	CPE23URI              string `json:"cpe23Uri"`
+	Criteria              string `json:"criteria"`
+	VersionStartIncluding string `json:"versionStartIncluding"`
+	VersionStartExcluding string `json:"versionStartExcluding"`
+	VersionEndIncluding   string `json:"versionEndIncluding"`
+	VersionEndExcluding   string `json:"versionEndExcluding"`
+}
+
+func englishDescription(descriptions []nvdDescription) string {
+	for _, d := range descriptions {
+		if d.Lang == "en" {
+			return d.Value
+		}
+	}
+	if len(descriptions) > 0 {
+		return descriptions[0].Value
+	}
+	return ""
+}
@@ -0,0 +1,295 @@
+// Package vulns 将已安装的应用与本地同步的 NVD JSON 数据源比对，找出受影响的 CVE。
+// 支持 NVD JSON 1.1 数据源和 NVD API 2.0 格式，文件可以是 .json 或 .json.gz，
+// 整个过程不访问网络。
+package vulns
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/cpe"
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/versions"
+)
+
+// Vulnerability 是一个CVE条目
+type Vulnerability struct {
+	ID          string  `json:"cve"`
+	Severity    string  `json:"severity"`
+	Score       float64 `json:"score"`
+	Description string  `json:"description,omitempty"`
+}
+
+// Finding 是应用命中的一个CVE，Confidence 取值范围为 0~1
+type Finding struct {
+	Vulnerability
+	CPE        string  `json:"cpe"`
+	Confidence float64 `json:"confidence"`
+}
+
+// 数据源中一条受影响的CPE及其版本范围
+type rule struct {
+	vuln     *Vulnerability
+	criteria string
+	version  string
+
+	startIncluding string
+	startExcluding string
+	endIncluding   string
+	endExcluding   string
+}
+
+// Database 是按 vendor:product 建立索引的漏洞数据
+type Database struct {
+	rules map[string][]rule
+	count int
+}
+
+// Load 读取目录下所有 .json 和 .json.gz 数据源文件
+func Load(dir string) (*Database, error) {
+	db := &Database{rules: make(map[string][]rule)}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := strings.ToLower(d.Name())
+		if d.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) {
+			return nil
+		}
+		if err := db.loadFile(path); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Count 返回已加载的CVE数量
+func (db *Database) Count() int {
+	return db.count
+}
+
+func (db *Database) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return db.add(data)
+}
+
+// 根据顶层字段判断数据源格式
+func (db *Database) add(data []byte) error {
+	var probe struct {
+		Items           json.RawMessage `json:"CVE_Items"`
+		Vulnerabilities json.RawMessage `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+
+	switch {
+	case probe.Items != nil:
+		var feed nvdFeed11
+		if err := json.Unmarshal(data, &feed); err != nil {
+			return err
+		}
+		db.addFeed11(&feed)
+	case probe.Vulnerabilities != nil:
+		var feed nvdFeed20
+		if err := json.Unmarshal(data, &feed); err != nil {
+			return err
+		}
+		db.addFeed20(&feed)
+	default:
+		return fmt.Errorf("无法识别的NVD数据格式")
+	}
+	return nil
+}
+
+func (db *Database) addFeed11(feed *nvdFeed11) {
+	for _, item := range feed.Items {
+		vuln := &Vulnerability{
+			ID:          item.CVE.Meta.ID,
+			Severity:    item.Impact.V3.CVSS.BaseSeverity,
+			Score:       item.Impact.V3.CVSS.BaseScore,
+			Description: englishDescription(item.CVE.Description.Data),
+		}
+		if vuln.Severity == "" {
+			vuln.Severity = item.Impact.V2.Severity
+			vuln.Score = item.Impact.V2.CVSS.BaseScore
+		}
+
+		var walk func(nodes []nvdNode11)
+		walk = func(nodes []nvdNode11) {
+			for _, node := range nodes {
+				for _, m := range node.CPEMatch {
+					db.addMatch(vuln, m, m.CPE23URI)
+				}
+				walk(node.Children)
+			}
+		}
+		walk(item.Configurations.Nodes)
+		db.count++
+	}
+}
+
+func (db *Database) addFeed20(feed *nvdFeed20) {
+	for _, v := range feed.Vulnerabilities {
+		vuln := &Vulnerability{
+			ID:          v.CVE.ID,
+			Description: englishDescription(v.CVE.Descriptions),
+		}
+		for _, metrics := range [][]nvdMetric20{v.CVE.Metrics.V31, v.CVE.Metrics.V30, v.CVE.Metrics.V2} {
+			if len(metrics) == 0 {
+				continue
+			}
+			vuln.Score = metrics[0].CVSSData.BaseScore
+			vuln.Severity = metrics[0].CVSSData.BaseSeverity
+			if vuln.Severity == "" {
+				vuln.Severity = metrics[0].BaseSeverity
+			}
+			break
+		}
+
+		for _, config := range v.CVE.Configurations {
+			for _, node := range config.Nodes {
+				for _, m := range node.CPEMatch {
+					db.addMatch(vuln, m, m.Criteria)
+				}
+			}
+		}
+		db.count++
+	}
+}
+
+// 只收录受影响的应用类CPE；AND组合（例如"运行在某操作系统上"）按其中的应用单独处理
+func (db *Database) addMatch(vuln *Vulnerability, m nvdCPEMatch, criteria string) {
+	if !m.Vulnerable {
+		return
+	}
+	wfn, err := cpe.Parse(criteria)
+	if err != nil || wfn.Part != "a" {
+		return
+	}
+
+	key := wfn.Vendor + ":" + wfn.Product
+	db.rules[key] = append(db.rules[key], rule{
+		vuln:           vuln,
+		criteria:       criteria,
+		version:        wfn.Version,
+		startIncluding: m.VersionStartIncluding,
+		startExcluding: m.VersionStartExcluding,
+		endIncluding:   m.VersionEndIncluding,
+		endExcluding:   m.VersionEndExcluding,
+	})
+}
+
+// 判断版本是否受影响，返回版本匹配的可信度
+func (r *rule) matches(version string) (bool, float64) {
+	switch r.version {
+	case "-":
+		return false, 0
+	case "*", "":
+	default:
+		return versions.Compare(version, r.version) == 0, 1.0
+	}
+
+	hasRange := false
+	if r.startIncluding != "" {
+		hasRange = true
+		if versions.Compare(version, r.startIncluding) < 0 {
+			return false, 0
+		}
+	}
+	if r.startExcluding != "" {
+		hasRange = true
+		if versions.Compare(version, r.startExcluding) <= 0 {
+			return false, 0
+		}
+	}
+	if r.endIncluding != "" {
+		hasRange = true
+		if versions.Compare(version, r.endIncluding) > 0 {
+			return false, 0
+		}
+	}
+	if r.endExcluding != "" {
+		hasRange = true
+		if versions.Compare(version, r.endExcluding) >= 0 {
+			return false, 0
+		}
+	}
+
+	// 没有版本范围表示所有版本都受影响，这类条目往往过于宽泛
+	if !hasRange {
+		return true, 0.5
+	}
+	return true, 1.0
+}
+
+// Match 查找应用命中的CVE，结果按CVSS分数从高到低排列
+func (db *Database) Match(publisher, name, version string) []Finding {
+	if version == "" {
+		return nil
+	}
+
+	best := make(map[string]int)
+	var findings []Finding
+	for _, c := range cpe.Candidates(publisher, name) {
+		rules := db.rules[c.Vendor+":"+c.Product]
+		for i := range rules {
+			ok, versionConfidence := rules[i].matches(version)
+			if !ok {
+				continue
+			}
+
+			finding := Finding{
+				Vulnerability: *rules[i].vuln,
+				CPE:           rules[i].criteria,
+				Confidence:    c.Confidence * versionConfidence,
+			}
+			if idx, exists := best[finding.ID]; exists {
+				if finding.Confidence > findings[idx].Confidence {
+					findings[idx] = finding
+				}
+				continue
+			}
+			best[finding.ID] = len(findings)
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Score != findings[j].Score {
+			return findings[i].Score > findings[j].Score
+		}
+		return findings[i].ID < findings[j].ID
+	})
+	return findings
+}
@@ -0,0 +1,80 @@
+package vulns
+
+import "testing"
+
+func loadTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	db, err := Load("testdata")
+	if err != nil {
+		t.Fatal("Failed to load feeds:", err)
+	}
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	db := loadTestDatabase(t)
+	if db.Count() != 4 {
+		t.Errorf("Expected 4 CVEs from 1.1 and gzipped 2.0 feeds, got %d", db.Count())
+	}
+}
+
+func TestMatch(t *testing.T) {
+	db := loadTestDatabase(t)
+
+	tests := []struct {
+		publisher, name, version string
+		want                     []string
+	}{
+		{"Igor Pavlov", "7-Zip 21.07 (x64)", "21.07", []string{"CVE-2022-29072"}},
+		{"Igor Pavlov", "7-Zip 23.01 (x64)", "23.01", nil},
+		{"Notepad++ Team", "Notepad++ (64-bit x64)", "7.6", []string{"CVE-2019-0001"}},
+		{"Notepad++ Team", "Notepad++ (64-bit x64)", "7.6.1", nil},
+		{"Mozilla", "Mozilla Firefox (x64 en-US)", "123.0", []string{"CVE-2024-29944"}},
+		{"Mozilla", "Mozilla Firefox (x64 en-US)", "124.0.1", nil},
+		{"Mozilla", "Mozilla Firefox ESR (x64 en-US)", "115.8.0", []string{"CVE-2024-29944"}},
+		{"Mozilla", "Mozilla Firefox ESR (x64 en-US)", "102.0", nil},
+		{"Contoso", "Contoso Agent", "1.0", []string{"CVE-2020-0002"}},
+		{"Mozilla", "Mozilla Firefox", "", nil},
+	}
+
+	for _, tt := range tests {
+		findings := db.Match(tt.publisher, tt.name, tt.version)
+		if len(findings) != len(tt.want) {
+			t.Errorf("Match(%q, %q) = %+v, want %v", tt.name, tt.version, findings, tt.want)
+			continue
+		}
+		for i, id := range tt.want {
+			if findings[i].ID != id {
+				t.Errorf("Match(%q, %q)[%d] = %s, want %s", tt.name, tt.version, i, findings[i].ID, id)
+			}
+		}
+	}
+}
+
+func TestFindingDetails(t *testing.T) {
+	db := loadTestDatabase(t)
+
+	findings := db.Match("Mozilla", "Mozilla Firefox (x64 en-US)", "123.0")
+	if len(findings) != 1 {
+		t.Fatalf("Expected one finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Severity != "CRITICAL" || f.Score != 9.8 || f.Confidence != 1.0 {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if f.Description == "" || f.Description[:2] != "An" {
+		t.Errorf("Expected English description, got %q", f.Description)
+	}
+
+	// CVSS v3 缺失时使用 v2 的严重程度
+	findings = db.Match("Notepad++ Team", "Notepad++", "7.6")
+	if len(findings) != 1 || findings[0].Severity != "MEDIUM" || findings[0].Score != 5.0 {
+		t.Errorf("Expected v2 severity fallback, got %+v", findings)
+	}
+
+	// 不带版本范围的条目可信度较低
+	findings = db.Match("Contoso", "Contoso Agent", "1.0")
+	if len(findings) != 1 || findings[0].Confidence >= 1.0 {
+		t.Errorf("Expected reduced confidence for unbounded match, got %+v", findings)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jasoft/YourUninstaler/src/utils/app-manager/vulns"
+)
+
+type AppVulnerabilities struct {
+	DisplayName     string          `json:"DisplayName"`
+	DisplayVersion  string          `json:"DisplayVersion"`
+	Publisher       string          `json:"Publisher"`
+	RegistryKey     string          `json:"RegistryKey"`
+	Vulnerabilities []vulns.Finding `json:"vulnerabilities"`
+}
+
+type VulnResult struct {
+	Success bool                 `json:"success"`
+	Apps    []AppVulnerabilities `json:"apps"`
+	// 数据源中的CVE总数，用于确认数据源是否正确加载
+	FeedCVEs int    `json:"feedCves"`
+	Error    string `json:"error,omitempty"`
+}
+
+// 在本地NVD数据中查找已安装应用的漏洞，低于minConfidence的结果被忽略
+func findVulnerabilities(apps []App, db *vulns.Database, minConfidence float64) *VulnResult {
+	result := &VulnResult{
+		Success:  true,
+		Apps:     []AppVulnerabilities{},
+		FeedCVEs: db.Count(),
+	}
+
+	for _, app := range apps {
+		var findings []vulns.Finding
+		for _, f := range db.Match(app.Publisher, app.DisplayName, app.DisplayVersion) {
+			if f.Confidence >= minConfidence {
+				findings = append(findings, f)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		result.Apps = append(result.Apps, AppVulnerabilities{
+			DisplayName:     app.DisplayName,
+			DisplayVersion:  app.DisplayVersion,
+			Publisher:       app.Publisher,
+			RegistryKey:     app.RegistryKey,
+			Vulnerabilities: findings,
+		})
+	}
+
+	return result
+}
+
+func writeVulnResult(w io.Writer, result *VulnResult, format string) error {
+	switch format {
+	case "text":
+		total := 0
+		for _, app := range result.Apps {
+			fmt.Fprintf(w, "%s %s\n", app.DisplayName, app.DisplayVersion)
+			for _, v := range app.Vulnerabilities {
+				fmt.Fprintf(w, "  %-16s %-8s %4.1f  可信度 %.0f%%\n", v.ID, v.Severity, v.Score, v.Confidence*100)
+			}
+			total += len(app.Vulnerabilities)
+		}
+		fmt.Fprintf(w, "\n%d 个应用存在 %d 个已知漏洞（数据源共 %d 个CVE）\n", len(result.Apps), total, result.FeedCVEs)
+	case "json":
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(jsonData))
+	default:
+		return fmt.Errorf("不支持的输出格式 '%s'", format)
+	}
+	return nil
+}
@@ -1,3 +1,5 @@
 module github.com/yourusername/extract-icon
 
-go 1.21
+go 1.24.0
+
+require golang.org/x/sys v0.30.0 // indirect